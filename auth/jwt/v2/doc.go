@@ -0,0 +1,17 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package jwt authenticates the requests received by the HTTP protocol with the
+JWT bearer tokens they carry, validated with github.com/go-jose/go-jose
+against the keys of a JSON Web Key Set:
+
+	v, err := jwt.NewValidatorFromFile("jwks.json")
+	if err != nil { ... }
+	v.Issuer = "https://issuer.example.com"
+	v.Audience = "events"
+	p, err := cehttp.New(cehttp.WithRequestAuthenticator(v))
+*/
+package jwt
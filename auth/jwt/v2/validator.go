@@ -0,0 +1,176 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package jwt
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	jose "github.com/go-jose/go-jose/v4"
+	josejwt "github.com/go-jose/go-jose/v4/jwt"
+
+	"github.com/cloudevents/sdk-go/v2/extensions"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+// SignatureAlgorithms are the algorithms of the tokens accepted by Validator.
+var SignatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+var _ cehttp.RequestAuthenticator = (*Validator)(nil)
+
+// Validator is a cehttp.RequestAuthenticator validating the JWT bearer tokens
+// of incoming requests against the keys of a JSON Web Key Set (RFC 7517),
+// with one of the SignatureAlgorithms.
+type Validator struct {
+	// Issuer, if set, is the required "iss" claim.
+	Issuer string
+	// Audience, if set, must be one of the "aud" claim.
+	Audience string
+	// Leeway is the clock skew tolerated when checking "exp", "nbf" and "iat".
+	Leeway time.Duration
+	// AuthType is the type of the principals, one of the authtype values of
+	// the authcontext extension. If empty, extensions.AuthTypeUnknown is used.
+	AuthType string
+
+	mu   sync.RWMutex
+	keys []jose.JSONWebKey
+	now  func() time.Time
+}
+
+// NewValidator returns a validator for the tokens signed by the keys of the
+// JSON Web Key Set jwks.
+func NewValidator(jwks []byte) (*Validator, error) {
+	v := &Validator{now: time.Now}
+	if err := v.LoadJWKS(jwks); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// NewValidatorFromFile returns a validator for the tokens signed by the keys
+// of the JSON Web Key Set file at path.
+func NewValidatorFromFile(path string) (*Validator, error) {
+	jwks, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewValidator(jwks)
+}
+
+// LoadJWKS replaces the keys of v with the public signing keys of the JSON Web
+// Key Set jwks, for instance after a key rotation.
+func (v *Validator) LoadJWKS(jwks []byte) error {
+	var set jose.JSONWebKeySet
+	if err := json.Unmarshal(jwks, &set); err != nil {
+		return fmt.Errorf("invalid JWKS: %w", err)
+	}
+	keys := make([]jose.JSONWebKey, 0, len(set.Keys))
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if !k.Valid() {
+			return fmt.Errorf("invalid JWKS: key %d is invalid", i)
+		}
+		public := k.Public()
+		if !public.Valid() || !validPublicKey(public.Key) {
+			return fmt.Errorf("invalid JWKS: key %d is not a valid asymmetric key", i)
+		}
+		keys = append(keys, public)
+	}
+	if len(keys) == 0 {
+		return errors.New("invalid JWKS: no signing key")
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.mu.Unlock()
+	return nil
+}
+
+// validPublicKey completes the checks of jose.JSONWebKey.Valid, which accepts
+// empty RSA moduli.
+func validPublicKey(key interface{}) bool {
+	if k, ok := key.(*rsa.PublicKey); ok {
+		return k.N.Sign() > 0 && k.E > 1
+	}
+	return true
+}
+
+// AuthenticateRequest implements cehttp.RequestAuthenticator, returning the
+// principal identified by the "sub" claim of the bearer token of req.
+func (v *Validator) AuthenticateRequest(req *http.Request) (*cehttp.Principal, error) {
+	scheme, token, _ := strings.Cut(req.Header.Get("Authorization"), " ")
+	token = strings.TrimSpace(token)
+	if !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, fmt.Errorf("%w: missing bearer token", cehttp.ErrUnauthenticated)
+	}
+	claims, err := v.Validate(token)
+	if err != nil {
+		return nil, err
+	}
+	sub, _ := claims["sub"].(string)
+	authType := v.AuthType
+	if authType == "" {
+		authType = extensions.AuthTypeUnknown
+	}
+	return &cehttp.Principal{Type: authType, ID: sub, Claims: claims}, nil
+}
+
+// Validate checks the signature and claims of a compact serialized JWT, and
+// returns its claims. Its errors wrap cehttp.ErrUnauthenticated.
+func (v *Validator) Validate(token string) (map[string]interface{}, error) {
+	tok, err := josejwt.ParseSigned(token, SignatureAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed token: %v", cehttp.ErrUnauthenticated, err)
+	}
+	var claims map[string]interface{}
+	var registered josejwt.Claims
+	if !v.verify(tok, &claims, &registered) {
+		return nil, fmt.Errorf("%w: invalid token signature", cehttp.ErrUnauthenticated)
+	}
+
+	expected := josejwt.Expected{Issuer: v.Issuer, Time: v.now()}
+	if v.Audience != "" {
+		expected.AnyAudience = josejwt.Audience{v.Audience}
+	}
+	if err := registered.ValidateWithLeeway(expected, v.Leeway); err != nil {
+		return nil, fmt.Errorf("%w: %v", cehttp.ErrUnauthenticated, err)
+	}
+	return claims, nil
+}
+
+// verify decodes the claims of tok into dest if it is signed by one of the
+// keys of v matching its key ID and algorithm.
+func (v *Validator) verify(tok *josejwt.JSONWebToken, dest ...interface{}) bool {
+	if len(tok.Headers) != 1 {
+		return false
+	}
+	header := tok.Headers[0]
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	for _, k := range v.keys {
+		if (header.KeyID != "" && k.KeyID != header.KeyID) || (k.Algorithm != "" && k.Algorithm != header.Algorithm) {
+			continue
+		}
+		if tok.Claims(k.Key, dest...) == nil {
+			return true
+		}
+	}
+	return false
+}
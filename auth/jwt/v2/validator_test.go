@@ -0,0 +1,240 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/extensions"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+type jwtTestKeys struct {
+	rsa     *rsa.PrivateKey
+	ecdsa   *ecdsa.PrivateKey
+	ed25519 ed25519.PrivateKey
+}
+
+func newJWTTestKeys(t *testing.T) *jwtTestKeys {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return &jwtTestKeys{rsa: rsaKey, ecdsa: ecKey, ed25519: edKey}
+}
+
+func (k *jwtTestKeys) jwks(t *testing.T) []byte {
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwks, err := json.Marshal(map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA", "kid": "rsa", "use": "sig",
+		"n": b64(k.rsa.N.Bytes()), "e": b64(big.NewInt(int64(k.rsa.E)).Bytes()),
+	}, {
+		"kty": "EC", "kid": "ec", "alg": "ES256", "crv": "P-256",
+		"x": b64(k.ecdsa.X.FillBytes(make([]byte, 32))), "y": b64(k.ecdsa.Y.FillBytes(make([]byte, 32))),
+	}, {
+		"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": b64(k.ed25519.Public().(ed25519.PublicKey)),
+	}, {
+		"kty": "RSA", "kid": "enc", "use": "enc",
+		"n": b64(k.rsa.N.Bytes()), "e": b64(big.NewInt(int64(k.rsa.E)).Bytes()),
+	}}})
+	require.NoError(t, err)
+	return jwks
+}
+
+func (k *jwtTestKeys) sign(t *testing.T, alg, kid string, claims map[string]interface{}) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	hash := map[string]crypto.Hash{"256": crypto.SHA256, "384": crypto.SHA384, "512": crypto.SHA512}[alg[len(alg)-3:]]
+	var digest []byte
+	if hash != 0 {
+		h := hash.New()
+		h.Write([]byte(signed))
+		digest = h.Sum(nil)
+	}
+	var sig []byte
+	switch alg[:2] {
+	case "RS":
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k.rsa, hash, digest)
+	case "PS":
+		sig, err = rsa.SignPSS(rand.Reader, k.rsa, hash, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	case "ES":
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k.ecdsa, digest)
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case "Ed":
+		sig = ed25519.Sign(k.ed25519, []byte(signed))
+	}
+	require.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestValidator(t *testing.T) {
+	keys := newJWTTestKeys(t)
+	clock := time.Unix(1700000000, 0)
+	now := clock.Unix()
+	claims := func(extra map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{"sub": "svc-1", "iss": "https://issuer", "aud": []string{"other", "events"}, "exp": now + 60}
+		for k, v := range extra {
+			if v == nil {
+				delete(c, k)
+			} else {
+				c[k] = v
+			}
+		}
+		return c
+	}
+
+	testCases := map[string]struct {
+		token   string
+		wantErr bool
+	}{
+		"RS256":                 {token: keys.sign(t, "RS256", "rsa", claims(nil))},
+		"RS512":                 {token: keys.sign(t, "RS512", "rsa", claims(nil))},
+		"PS384":                 {token: keys.sign(t, "PS384", "rsa", claims(nil))},
+		"ES256":                 {token: keys.sign(t, "ES256", "ec", claims(nil))},
+		"EdDSA":                 {token: keys.sign(t, "EdDSA", "ed", claims(nil))},
+		"no kid":                {token: keys.sign(t, "EdDSA", "", claims(nil))},
+		"string audience":       {token: keys.sign(t, "RS256", "rsa", claims(map[string]interface{}{"aud": "events"}))},
+		"expired within leeway": {token: keys.sign(t, "RS256", "rsa", claims(map[string]interface{}{"exp": now - 5}))},
+		"expired": {
+			token:   keys.sign(t, "RS256", "rsa", claims(map[string]interface{}{"exp": now - 60})),
+			wantErr: true,
+		},
+		"not valid yet": {
+			token:   keys.sign(t, "RS256", "rsa", claims(map[string]interface{}{"nbf": now + 60})),
+			wantErr: true,
+		},
+		"wrong issuer": {
+			token:   keys.sign(t, "RS256", "rsa", claims(map[string]interface{}{"iss": "https://evil"})),
+			wantErr: true,
+		},
+		"wrong audience": {
+			token:   keys.sign(t, "RS256", "rsa", claims(map[string]interface{}{"aud": "other"})),
+			wantErr: true,
+		},
+		"missing audience": {
+			token:   keys.sign(t, "RS256", "rsa", claims(map[string]interface{}{"aud": nil})),
+			wantErr: true,
+		},
+		"wrong kid": {
+			token:   keys.sign(t, "RS256", "ec", claims(nil)),
+			wantErr: true,
+		},
+		"algorithm not allowed by key": {
+			token:   keys.sign(t, "ES384", "ec", claims(nil)),
+			wantErr: true,
+		},
+		"encryption key": {
+			token:   keys.sign(t, "RS256", "enc", claims(nil)),
+			wantErr: true,
+		},
+		"none algorithm": {
+			token:   keys.sign(t, "none", "", claims(nil)),
+			wantErr: true,
+		},
+		"tampered claims": {
+			token: func() string {
+				token := keys.sign(t, "RS256", "rsa", claims(nil))
+				forged := keys.sign(t, "RS256", "rsa", claims(map[string]interface{}{"sub": "admin"}))
+				return forged[:strings.LastIndex(forged, ".")] + token[strings.LastIndex(token, "."):]
+			}(),
+			wantErr: true,
+		},
+		"malformed": {
+			token:   "not-a-jwt",
+			wantErr: true,
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, keys.jwks(t), 0o600))
+	v, err := NewValidatorFromFile(path)
+	require.NoError(t, err)
+	v.now = func() time.Time { return clock }
+	v.Issuer = "https://issuer"
+	v.Audience = "events"
+	v.Leeway = 10 * time.Second
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			c, err := v.Validate(tc.token)
+			if tc.wantErr {
+				require.True(t, errors.Is(err, cehttp.ErrUnauthenticated), "got %v", err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "svc-1", c["sub"])
+		})
+	}
+}
+
+func TestValidator_AuthenticateRequest(t *testing.T) {
+	keys := newJWTTestKeys(t)
+	v, err := NewValidator(keys.jwks(t))
+	require.NoError(t, err)
+	v.AuthType = extensions.AuthTypeServiceAccount
+
+	req := httptest.NewRequest(http.MethodPost, "http://unittest", nil)
+	_, err = v.AuthenticateRequest(req)
+	require.True(t, errors.Is(err, cehttp.ErrUnauthenticated))
+
+	req.Header.Set("Authorization", "Bearer "+keys.sign(t, "ES256", "ec", map[string]interface{}{"sub": "svc-1", "scope": "events"}))
+	principal, err := v.AuthenticateRequest(req)
+	require.NoError(t, err)
+	require.Equal(t, &cehttp.Principal{
+		Type:   extensions.AuthTypeServiceAccount,
+		ID:     "svc-1",
+		Claims: map[string]interface{}{"sub": "svc-1", "scope": "events"},
+	}, principal)
+
+	// Tokens are rejected once their key is rotated out.
+	require.NoError(t, v.LoadJWKS(newJWTTestKeys(t).jwks(t)))
+	_, err = v.AuthenticateRequest(req)
+	require.True(t, errors.Is(err, cehttp.ErrUnauthenticated))
+}
+
+func TestNewValidator_invalid(t *testing.T) {
+	testCases := map[string]string{
+		"not json":          `keys`,
+		"no keys":           `{"keys":[]}`,
+		"unsupported kty":   `{"keys":[{"kty":"oct","k":"c2VjcmV0"}]}`,
+		"unsupported curve": `{"keys":[{"kty":"EC","crv":"P-192","x":"AQ","y":"AQ"}]}`,
+		"point not on curve": `{"keys":[{"kty":"EC","crv":"P-256",` +
+			`"x":"AQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA","y":"AQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"}]}`,
+		"unsupported okp": `{"keys":[{"kty":"OKP","crv":"X25519","x":"AQ"}]}`,
+		"invalid rsa":     `{"keys":[{"kty":"RSA","n":"","e":"AQAB"}]}`,
+	}
+	for n, jwks := range testCases {
+		t.Run(n, func(t *testing.T) {
+			_, err := NewValidator([]byte(jwks))
+			require.Error(t, err)
+		})
+	}
+}
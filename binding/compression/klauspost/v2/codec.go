@@ -0,0 +1,55 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package klauspost
+
+import (
+	"io"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/cloudevents/sdk-go/v2/binding/compression"
+)
+
+var (
+	// Zstd is the "zstd" content coding.
+	Zstd compression.Codec = zstdCodec{}
+	// Snappy is the "snappy" content coding, with the snappy framing format.
+	Snappy compression.Codec = snappyCodec{}
+)
+
+func init() {
+	compression.Add(Zstd)
+	compression.Add(Snappy)
+}
+
+type zstdCodec struct{}
+
+func (zstdCodec) Encoding() string { return "zstd" }
+
+func (zstdCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+}
+
+func (zstdCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return d.IOReadCloser(), nil
+}
+
+type snappyCodec struct{}
+
+func (snappyCodec) Encoding() string { return "snappy" }
+
+func (snappyCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return snappy.NewBufferedWriter(w), nil
+}
+
+func (snappyCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(snappy.NewReader(r)), nil
+}
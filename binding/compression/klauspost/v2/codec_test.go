@@ -0,0 +1,58 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package klauspost
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding/compression"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+func TestCodecs(t *testing.T) {
+	data := []byte(strings.Repeat(`{"name":"Jane","email":"jane@example.com"}`, 100))
+	for _, c := range []compression.Codec{Zstd, Snappy} {
+		c := c
+		t.Run(c.Encoding(), func(t *testing.T) {
+			require.Equal(t, c, compression.Lookup(strings.ToUpper(c.Encoding())))
+
+			compressed, err := compression.Compress(c, data)
+			require.NoError(t, err)
+			require.Less(t, len(compressed), len(data))
+
+			got, err := compression.Decompress(c, compressed, 0)
+			require.NoError(t, err)
+			require.Equal(t, data, got)
+
+			_, err = compression.Decompress(c, compressed, int64(len(data)-1))
+			require.ErrorIs(t, err, compression.ErrTooLarge)
+
+			empty, err := compression.Compress(c, nil)
+			require.NoError(t, err)
+			got, err = compression.Decompress(c, empty, 0)
+			require.NoError(t, err)
+			require.Empty(t, got)
+
+			_, err = compression.Decompress(c, data, 0)
+			require.Error(t, err)
+		})
+	}
+	require.Equal(t, []string{"gzip", "snappy", "zstd"}, compression.Encodings())
+}
+
+func TestCompressData(t *testing.T) {
+	e := test.MinEvent()
+	e.DataEncoded = []byte(strings.Repeat("a", 1000))
+	require.NoError(t, compression.CompressData(Zstd, 0)(context.Background(), &e))
+	require.Equal(t, "zstd", e.Extensions()["dataencoding"])
+
+	require.NoError(t, compression.DecompressData(0)(context.Background(), &e))
+	require.Equal(t, strings.Repeat("a", 1000), string(e.Data()))
+}
@@ -0,0 +1,13 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package klauspost registers the "zstd" and "snappy" content codings, with the
+github.com/klauspost/compress implementations, in the compression package of
+the SDK. Importing it is enough to compress and decompress data with them:
+
+	import _ "github.com/cloudevents/sdk-go/binding/compression/klauspost/v2"
+*/
+package klauspost
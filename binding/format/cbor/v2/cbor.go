@@ -0,0 +1,338 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package format
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	stdtime "time"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+const (
+	specversion         = "specversion"
+	id                  = "id"
+	source              = "source"
+	typ                 = "type"
+	datacontenttype     = "datacontenttype"
+	dataschema          = "dataschema"
+	schemaurl           = "schemaurl"
+	datacontentencoding = "datacontentencoding"
+	subject             = "subject"
+	time                = "time"
+	data                = "data"
+)
+
+// CBOR tag numbers used for attribute values, as registered in
+// https://www.iana.org/assignments/cbor-tags/cbor-tags.xhtml
+const (
+	tagDateTimeString = 0
+	tagEpochDateTime  = 1
+	tagURI            = 32
+)
+
+var (
+	zeroTime = stdtime.Time{}
+	// CBOR is the built-in "application/cloudevents+cbor" format.
+	CBOR = cborFmt{}
+
+	encMode cbor.EncMode
+)
+
+const (
+	ApplicationCloudEventsCBOR = "application/cloudevents+cbor"
+)
+
+// StringOfApplicationCloudEventsCBOR returns a string pointer to
+// "application/cloudevents+cbor"
+func StringOfApplicationCloudEventsCBOR() *string {
+	a := ApplicationCloudEventsCBOR
+	return &a
+}
+
+func init() {
+	var err error
+	// Core deterministic encoding keeps the map key order stable, so the same
+	// event always marshals to the same bytes.
+	encMode, err = cbor.EncOptions{Sort: cbor.SortCoreDeterministic}.EncMode()
+	if err != nil {
+		panic(err)
+	}
+	format.Add(CBOR)
+}
+
+type cborFmt struct{}
+
+func (cborFmt) MediaType() string {
+	return ApplicationCloudEventsCBOR
+}
+
+func (cborFmt) Marshal(e *event.Event) ([]byte, error) {
+	m, err := ToCBOR(e)
+	if err != nil {
+		return nil, err
+	}
+	return encMode.Marshal(m)
+}
+
+func (cborFmt) Unmarshal(b []byte, e *event.Event) error {
+	m := map[string]cbor.RawMessage{}
+	if err := cbor.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	e2, err := FromCBOR(m)
+	if err != nil {
+		return err
+	}
+	*e = *e2
+	return nil
+}
+
+// ToCBOR converts an SDK event to a map of CBOR data items that can be
+// marshaled. Attribute values are mapped to native CBOR types: timestamps are
+// tagged date/time strings (tag 0) and URIs are tagged URI strings (tag 32).
+func ToCBOR(e *event.Event) (map[string]interface{}, error) {
+	container := map[string]interface{}{
+		specversion: e.SpecVersion(),
+		id:          e.ID(),
+		source:      e.Source(),
+		typ:         e.Type(),
+	}
+	if e.DataContentType() != "" {
+		container[datacontenttype] = e.DataContentType()
+	}
+	if e.DataSchema() != "" {
+		dataSchemaStr := e.DataSchema()
+		uri, err := url.Parse(dataSchemaStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to url.Parse %s: %w", dataSchemaStr, err)
+		}
+		name := dataschema
+		if e.SpecVersion() == event.CloudEventsVersionV03 {
+			name = schemaurl
+		}
+		container[name], _ = valueFor(types.URI{URL: *uri})
+	}
+	if e.DeprecatedDataContentEncoding() != "" {
+		container[datacontentencoding] = e.DeprecatedDataContentEncoding()
+	}
+	if e.Subject() != "" {
+		container[subject] = e.Subject()
+	}
+	if e.Time() != zeroTime {
+		container[time], _ = valueFor(e.Time())
+	}
+	for name, value := range e.Extensions() {
+		v, err := valueFor(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode attribute %s: %s", name, err)
+		}
+		container[name] = v
+	}
+	if e.Data() != nil {
+		if IsCBOR(e.DataContentType()) {
+			// CBOR data is embedded as a native data item rather than as a
+			// byte string, so that it can be read without a second decode.
+			if err := cbor.Wellformed(e.Data()); err != nil {
+				return nil, fmt.Errorf("data is declared as %s but is not well-formed CBOR: %w", e.DataContentType(), err)
+			}
+			container[data] = cbor.RawMessage(e.Data())
+		} else {
+			container[data] = e.Data()
+		}
+	}
+	return container, nil
+}
+
+func valueFor(v interface{}) (interface{}, error) {
+	vv, err := types.Validate(v)
+	if err != nil {
+		return nil, err
+	}
+	switch vt := vv.(type) {
+	case bool, int32, string, []byte:
+		return vt, nil
+	case types.URI:
+		return cbor.Tag{Number: tagURI, Content: vt.String()}, nil
+	case types.URIRef:
+		// A URI-reference may be relative, which tag 32 does not allow, so it
+		// is carried as a plain text string.
+		return vt.String(), nil
+	case types.Timestamp:
+		return cbor.Tag{Number: tagDateTimeString, Content: vt.String()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported attribute type: %T", v)
+	}
+}
+
+func valueFrom(raw cbor.RawMessage) (interface{}, error) {
+	var v interface{}
+	if len(raw) > 0 && raw[0]>>5 == 6 { // major type 6: tagged data item
+		var tag cbor.RawTag
+		if err := cbor.Unmarshal(raw, &tag); err != nil {
+			return nil, err
+		}
+		switch tag.Number {
+		case tagDateTimeString, tagEpochDateTime:
+			var t stdtime.Time
+			if err := cbor.Unmarshal(raw, &t); err != nil {
+				return nil, fmt.Errorf("failed to parse timestamp value: %s", err.Error())
+			}
+			v = t.UTC()
+		case tagURI:
+			var s string
+			if err := cbor.Unmarshal(tag.Content, &s); err != nil {
+				return nil, fmt.Errorf("failed to parse URI value: %s", err.Error())
+			}
+			uri, err := url.Parse(s)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse URI value %s: %s", s, err.Error())
+			}
+			v = uri
+		default:
+			return nil, fmt.Errorf("unsupported CBOR tag: %d", tag.Number)
+		}
+		return types.Validate(v)
+	}
+	if err := cbor.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	return types.Validate(v)
+}
+
+func stringFrom(name string, raw cbor.RawMessage) (string, error) {
+	var s string
+	if err := cbor.Unmarshal(raw, &s); err != nil {
+		return "", fmt.Errorf("failed to convert attribute %s: %s", name, err)
+	}
+	return s, nil
+}
+
+// FromCBOR converts a map of CBOR data items, as produced by unmarshaling an
+// "application/cloudevents+cbor" payload, into the generic SDK event.
+func FromCBOR(container map[string]cbor.RawMessage) (*event.Event, error) {
+	raw, ok := container[specversion]
+	if !ok {
+		return nil, errors.New("missing required attribute: specversion")
+	}
+	version, err := stringFrom(specversion, raw)
+	if err != nil {
+		return nil, err
+	}
+	e := event.New(version)
+	if e.Context == nil {
+		return nil, fmt.Errorf("unknown specversion: %q", version)
+	}
+	for name, raw := range container {
+		switch name {
+		case specversion:
+			continue
+		case id, source, typ, datacontenttype, datacontentencoding, subject:
+			vs, err := stringFrom(name, raw)
+			if err != nil {
+				return nil, err
+			}
+			switch name {
+			case id:
+				e.SetID(vs)
+			case source:
+				e.SetSource(vs)
+			case typ:
+				e.SetType(vs)
+			case datacontenttype:
+				e.SetDataContentType(vs)
+			case datacontentencoding:
+				e.SetDataContentEncoding(vs)
+			case subject:
+				e.SetSubject(vs)
+			}
+		case dataschema, schemaurl:
+			v, err := valueFrom(raw)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert attribute %s: %s", name, err)
+			}
+			vs, err := types.ToURL(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert attribute %s: %s", name, err)
+			}
+			e.SetDataSchema(vs.String())
+		case time:
+			v, err := valueFrom(raw)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert attribute %s: %s", name, err)
+			}
+			vs, err := types.ToTime(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert attribute %s: %s", name, err)
+			}
+			e.SetTime(vs)
+		case data:
+			// data is handled below, once datacontenttype is known.
+		default:
+			v, err := valueFrom(raw)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert attribute %s: %s", name, err)
+			}
+			e.SetExtension(name, v)
+		}
+	}
+	if raw, ok := container[data]; ok {
+		if err := dataFrom(&e, raw); err != nil {
+			return nil, err
+		}
+	}
+	return &e, nil
+}
+
+// dataFrom sets the event data from the "data" member. Byte and text strings
+// are taken as the encoded data as-is, any other data item is kept as its
+// CBOR encoding.
+func dataFrom(e *event.Event, raw cbor.RawMessage) error {
+	switch raw[0] >> 5 {
+	case 2: // byte string
+		var b []byte
+		if err := cbor.Unmarshal(raw, &b); err != nil {
+			return fmt.Errorf("failed to convert data: %s", err)
+		}
+		if !IsCBOR(e.DataContentType()) {
+			// NOTE: Direct assignment, the same as the protobuf format, so
+			// that binary data is not flagged as base64.
+			e.DataEncoded = b
+			return nil
+		}
+	case 3: // text string
+		var s string
+		if err := cbor.Unmarshal(raw, &s); err != nil {
+			return fmt.Errorf("failed to convert data: %s", err)
+		}
+		if !IsCBOR(e.DataContentType()) {
+			e.DataEncoded = []byte(s)
+			return nil
+		}
+	}
+	if e.DataContentType() == "" {
+		e.SetDataContentType(ContentTypeCBOR)
+	}
+	e.DataEncoded = []byte(raw)
+	return nil
+}
+
+// IsCBOR returns true if the content type is "application/cbor" or uses the
+// "+cbor" structured syntax suffix.
+func IsCBOR(contentType string) bool {
+	if i := strings.IndexRune(contentType, ';'); i != -1 {
+		contentType = contentType[:i]
+	}
+	contentType = strings.TrimSpace(strings.ToLower(contentType))
+	return contentType == ContentTypeCBOR || strings.HasSuffix(contentType, "+cbor")
+}
@@ -0,0 +1,240 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package format_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	stdtime "time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/require"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/event/datacodec"
+
+	cborfmt "github.com/cloudevents/sdk-go/binding/format/cbor/v2"
+)
+
+func newTestEvent() event.Event {
+	const test = "test"
+	e := event.New()
+	e.SetID(test)
+	e.SetTime(stdtime.Date(2021, 1, 1, 1, 1, 1, 1, stdtime.UTC))
+	e.SetExtension(test, test)
+	e.SetExtension("int", 1)
+	e.SetExtension("bool", true)
+	e.SetExtension("uri", &url.URL{
+		Scheme: "https",
+		Host:   "test-uri",
+	})
+	e.SetExtension("bytes", []byte(test))
+	e.SetExtension("timestamp", stdtime.Date(2021, 2, 1, 1, 1, 1, 1, stdtime.UTC))
+	e.SetSubject(test)
+	e.SetSource(test)
+	e.SetType(test)
+	e.SetDataSchema("https://example.com/schema")
+	return e
+}
+
+func TestCBORFormatWithoutCBORCodec(t *testing.T) {
+	require := require.New(t)
+	e := newTestEvent()
+	require.NoError(e.SetData(event.ApplicationJSON, "foo"))
+
+	b, err := cborfmt.CBOR.Marshal(&e)
+	require.NoError(err)
+	var e2 event.Event
+	require.NoError(cborfmt.CBOR.Unmarshal(b, &e2))
+	require.Equal(e, e2)
+}
+
+func TestCBORFormatWithCBORCodec(t *testing.T) {
+	require := require.New(t)
+	e := newTestEvent()
+	payload := map[string]interface{}{"temperature": int64(21), "unit": "C"}
+	require.NoError(e.SetData(cborfmt.ContentTypeCBOR, payload))
+
+	b, err := format.Marshal(cborfmt.ApplicationCloudEventsCBOR, &e)
+	require.NoError(err)
+	var e2 event.Event
+	require.NoError(format.Unmarshal(cborfmt.ApplicationCloudEventsCBOR, b, &e2))
+	require.Equal(e, e2)
+
+	// The data must be embedded as a native CBOR map, not a byte string.
+	var raw map[string]interface{}
+	require.NoError(cbor.Unmarshal(b, &raw))
+	require.IsType(map[interface{}]interface{}{}, raw["data"])
+
+	payload2 := map[string]interface{}{}
+	require.NoError(e2.DataAs(&payload2))
+	require.Equal(payload["unit"], payload2["unit"])
+	require.EqualValues(21, payload2["temperature"])
+}
+
+func TestCBORFormatV03(t *testing.T) {
+	require := require.New(t)
+	e := event.New(cloudevents.VersionV03)
+	e.SetID("abc-123")
+	e.SetSource("/source")
+	e.SetType("some.type")
+	e.SetDataSchema("https://example.com/schema")
+	e.SetExtension("extra", "value")
+	require.NoError(e.SetData("text/plain", "hello"))
+
+	b, err := cborfmt.CBOR.Marshal(&e)
+	require.NoError(err)
+
+	var raw map[string]cbor.RawMessage
+	require.NoError(cbor.Unmarshal(b, &raw))
+	require.Contains(raw, "schemaurl")
+	require.NotContains(raw, "dataschema")
+
+	var e2 event.Event
+	require.NoError(cborfmt.CBOR.Unmarshal(b, &e2))
+	require.Equal(e, e2)
+}
+
+func TestCBORFormatNativeTypes(t *testing.T) {
+	require := require.New(t)
+	e := newTestEvent()
+	b, err := cborfmt.CBOR.Marshal(&e)
+	require.NoError(err)
+
+	var raw map[string]cbor.RawMessage
+	require.NoError(cbor.Unmarshal(b, &raw))
+
+	var tag cbor.RawTag
+	require.NoError(cbor.Unmarshal(raw["time"], &tag))
+	require.Equal(uint64(0), tag.Number)
+	require.NoError(cbor.Unmarshal(raw["dataschema"], &tag))
+	require.Equal(uint64(32), tag.Number)
+	require.NoError(cbor.Unmarshal(raw["uri"], &tag))
+	require.Equal(uint64(32), tag.Number)
+
+	var i int64
+	require.NoError(cbor.Unmarshal(raw["int"], &i))
+	require.Equal(int64(1), i)
+	var bs []byte
+	require.NoError(cbor.Unmarshal(raw["bytes"], &bs))
+	require.Equal([]byte("test"), bs)
+}
+
+func TestFromCBOR(t *testing.T) {
+	mustMarshal := func(v interface{}) cbor.RawMessage {
+		b, err := cbor.Marshal(v)
+		require.NoError(t, err)
+		return b
+	}
+	tests := []struct {
+		name    string
+		in      map[string]cbor.RawMessage
+		want    *event.Event
+		wantErr bool
+	}{{
+		name: "epoch time",
+		in: map[string]cbor.RawMessage{
+			"specversion": mustMarshal("1.0"),
+			"id":          mustMarshal("abc-123"),
+			"source":      mustMarshal("/source"),
+			"type":        mustMarshal("some.type"),
+			"time":        mustMarshal(cbor.Tag{Number: 1, Content: 1609462861}),
+		},
+		want: func() *event.Event {
+			out := event.New(cloudevents.VersionV1)
+			out.SetID("abc-123")
+			out.SetSource("/source")
+			out.SetType("some.type")
+			out.SetTime(stdtime.Date(2021, 1, 1, 1, 1, 1, 0, stdtime.UTC))
+			return &out
+		}(),
+	}, {
+		name: "text data",
+		in: map[string]cbor.RawMessage{
+			"specversion":     mustMarshal("1.0"),
+			"id":              mustMarshal("abc-123"),
+			"source":          mustMarshal("/source"),
+			"type":            mustMarshal("some.type"),
+			"datacontenttype": mustMarshal("text/plain"),
+			"data":            mustMarshal(`this is some text with a "quote"`),
+		},
+		want: func() *event.Event {
+			out := event.New(cloudevents.VersionV1)
+			out.SetID("abc-123")
+			out.SetSource("/source")
+			out.SetType("some.type")
+			_ = out.SetData("text/plain", `this is some text with a "quote"`)
+			return &out
+		}(),
+	}, {
+		name: "native data without content type",
+		in: map[string]cbor.RawMessage{
+			"specversion": mustMarshal("1.0"),
+			"id":          mustMarshal("abc-123"),
+			"source":      mustMarshal("/source"),
+			"type":        mustMarshal("some.type"),
+			"data":        mustMarshal([]int{1, 2, 3}),
+		},
+		want: func() *event.Event {
+			out := event.New(cloudevents.VersionV1)
+			out.SetID("abc-123")
+			out.SetSource("/source")
+			out.SetType("some.type")
+			_ = out.SetData(cborfmt.ContentTypeCBOR, []int{1, 2, 3})
+			return &out
+		}(),
+	}, {
+		name: "missing specversion",
+		in: map[string]cbor.RawMessage{
+			"id": mustMarshal("abc-123"),
+		},
+		wantErr: true,
+	}, {
+		name: "unknown tag",
+		in: map[string]cbor.RawMessage{
+			"specversion": mustMarshal("1.0"),
+			"extra":       mustMarshal(cbor.Tag{Number: 2, Content: []byte{1}}),
+		},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cborfmt.FromCBOR(tt.in)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestToCBORMalformedData(t *testing.T) {
+	e := newTestEvent()
+	e.SetDataContentType(cborfmt.ContentTypeCBOR)
+	e.DataEncoded = []byte{0xff}
+	_, err := cborfmt.ToCBOR(&e)
+	require.Error(t, err)
+}
+
+func TestDataCodecStructuredSuffix(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	in := map[string]string{"hello": "world"}
+
+	b, err := datacodec.Encode(ctx, "application/vnd.custom-app+cbor", in)
+	require.NoError(err)
+	out := map[string]string{}
+	require.NoError(datacodec.Decode(ctx, "application/vnd.custom-app+cbor", b, &out))
+	require.Equal(in, out)
+
+	require.True(cborfmt.IsCBOR("application/vnd.custom-app+cbor; charset=binary"))
+	require.False(cborfmt.IsCBOR(event.ApplicationJSON))
+}
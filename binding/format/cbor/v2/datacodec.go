@@ -0,0 +1,50 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package format
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/cloudevents/sdk-go/v2/event/datacodec"
+)
+
+const (
+	// ContentTypeCBOR indicates that the data attribute is a CBOR data item.
+	ContentTypeCBOR = "application/cbor"
+)
+
+func init() {
+	datacodec.AddDecoder(ContentTypeCBOR, DecodeData)
+	datacodec.AddEncoder(ContentTypeCBOR, EncodeData)
+
+	datacodec.AddStructuredSuffixDecoder("cbor", DecodeData)
+	datacodec.AddStructuredSuffixEncoder("cbor", EncodeData)
+}
+
+// DecodeData converts CBOR encoded bytes into out, which must be a pointer.
+func DecodeData(ctx context.Context, in []byte, out interface{}) error {
+	if in == nil {
+		return nil
+	}
+	if err := cbor.Unmarshal(in, out); err != nil {
+		return fmt.Errorf("[cbor] found bytes, but failed to unmarshal: %s", err)
+	}
+	return nil
+}
+
+// EncodeData encodes in to CBOR bytes.
+//
+// Like the official datacodec implementations, this one returns the given value
+// as-is if it is already a byte slice.
+func EncodeData(ctx context.Context, in interface{}) ([]byte, error) {
+	if b, ok := in.([]byte); ok {
+		return b, nil
+	}
+	return encMode.Marshal(in)
+}
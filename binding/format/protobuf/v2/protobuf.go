@@ -108,8 +108,10 @@ func ToProto(e *event.Event) (*pb.CloudEvent, error) {
 		}
 		container.Attributes[name] = attr
 	}
-	container.Data = &pb.CloudEvent_BinaryData{
-		BinaryData: e.Data(),
+	if e.Data() != nil {
+		container.Data = &pb.CloudEvent_BinaryData{
+			BinaryData: e.Data(),
+		}
 	}
 	if e.DataContentType() == ContentTypeProtobuf {
 		anymsg := &anypb.Any{
@@ -15,6 +15,7 @@ import (
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/test"
 	"github.com/cloudevents/sdk-go/v2/types"
 
 	format "github.com/cloudevents/sdk-go/binding/format/protobuf/v2"
@@ -94,6 +95,25 @@ func TestProtobufFormatWithProtobufCodec(t *testing.T) {
 	require.True(payload2.GetCeBoolean())
 }
 
+func TestProtobufFormatWithoutData(t *testing.T) {
+	require := require.New(t)
+	e := event.New()
+	e.SetID("test")
+	e.SetSource("test")
+	e.SetType("test")
+
+	pbEvent, err := format.ToProto(&e)
+	require.NoError(err)
+	require.Nil(pbEvent.Data)
+
+	b, err := format.Protobuf.Marshal(&e)
+	require.NoError(err)
+	var e2 event.Event
+	require.NoError(format.Protobuf.Unmarshal(b, &e2))
+	require.Nil(e2.Data())
+	require.Equal(e, e2)
+}
+
 func TestFromProto(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -214,3 +234,27 @@ func TestFromProto(t *testing.T) {
 		})
 	}
 }
+
+// FuzzProtobuf verifies that unmarshalling any input does not panic, and that
+// the valid events it unmarshals round trip once normalized by a first
+// marshal.
+func FuzzProtobuf(f *testing.F) {
+	for _, e := range test.GenerateEvents(1, 50) {
+		b, err := format.Protobuf.Marshal(&e)
+		require.NoError(f, err)
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var e event.Event
+		if err := format.Protobuf.Unmarshal(b, &e); err != nil || e.Validate() != nil {
+			return
+		}
+		test.AssertRoundTrip(t, e, false, func(e event.Event) event.Event {
+			b, err := format.Protobuf.Marshal(&e)
+			require.NoError(t, err)
+			var got event.Event
+			require.NoError(t, format.Protobuf.Unmarshal(b, &got))
+			return got
+		})
+	})
+}
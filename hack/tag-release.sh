@@ -29,6 +29,10 @@ MODULES=(
   "observability/opentelemetry"
   "sql"
   "binding/format/protobuf"
+  "binding/format/cbor"
+  "binding/compression/klauspost"
+  "auth/jwt"
+  "schema/jsonschema"
 )
 
 REPOINT=(
@@ -48,6 +52,10 @@ REPOINT_SAMPLES=(
   "github.com/cloudevents/sdk-go/observability/opentelemetry/v2"
   "github.com/cloudevents/sdk-go/sql/v2"
   "github.com/cloudevents/sdk-go/binding/format/protobuf/v2"
+  "github.com/cloudevents/sdk-go/binding/format/cbor/v2"
+  "github.com/cloudevents/sdk-go/binding/compression/klauspost/v2"
+  "github.com/cloudevents/sdk-go/auth/jwt/v2"
+  "github.com/cloudevents/sdk-go/schema/jsonschema/v2"
   "github.com/cloudevents/sdk-go/v2"                       # NOTE: this needs to be last.
 )
 
@@ -22,6 +22,7 @@ require (
 	go.opentelemetry.io/otel/metric v1.44.0 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
 	go.uber.org/zap v1.28.0 // indirect
+	golang.org/x/time v0.15.0 // indirect
 )
 
 replace github.com/cloudevents/sdk-go/v2 => ../../../v2
@@ -106,6 +106,7 @@ func (p *Protocol) applyOptions(opts ...Option) error {
 }
 
 // Send message by kafka.Producer. You must monitor the Events() channel when using this function.
+// The key of the Kafka message is the one set with WithMessageKey or, if unset, the partitionkey extension of the event.
 func (p *Protocol) Send(ctx context.Context, in binding.Message, transformers ...binding.Transformer) (err error) {
 	if p.producer == nil {
 		return errors.New("producer client must be set")
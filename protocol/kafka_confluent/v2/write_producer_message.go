@@ -13,6 +13,7 @@ import (
 	"github.com/cloudevents/sdk-go/v2/binding"
 	"github.com/cloudevents/sdk-go/v2/binding/format"
 	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	"github.com/cloudevents/sdk-go/v2/extensions"
 	"github.com/cloudevents/sdk-go/v2/types"
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 )
@@ -27,12 +28,20 @@ var (
 
 // WriteProducerMessage fills the provided pubMessage with the message m.
 // Using context you can tweak the encoding processing (more details on binding.Write documentation).
+// If kafkaMsg has no key yet, it is set from the partitionkey extension of the message, if any. Structured
+// messages are then decoded to read it, and are written in the binary encoding unless the context prefers the
+// structured one, e.g. with binding.WithPreferredEventEncoding.
 func WriteProducerMessage(ctx context.Context, in binding.Message, kafkaMsg *kafka.Message,
 	transformers ...binding.Transformer,
 ) error {
 	structuredWriter := (*kafkaMessageWriter)(kafkaMsg)
 	binaryWriter := (*kafkaMessageWriter)(kafkaMsg)
 
+	var key string
+	if kafkaMsg.Key == nil {
+		transformers = append(transformers, extensions.PartitionKeyTransformer(&key))
+	}
+
 	_, err := binding.Write(
 		ctx,
 		in,
@@ -40,6 +49,9 @@ func WriteProducerMessage(ctx context.Context, in binding.Message, kafkaMsg *kaf
 		binaryWriter,
 		transformers...,
 	)
+	if key != "" {
+		kafkaMsg.Key = []byte(key)
+	}
 	return err
 }
 
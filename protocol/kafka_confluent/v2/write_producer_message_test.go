@@ -16,6 +16,7 @@ import (
 	"github.com/cloudevents/sdk-go/v2/binding"
 	. "github.com/cloudevents/sdk-go/v2/binding/test"
 	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/extensions"
 	. "github.com/cloudevents/sdk-go/v2/test"
 )
 
@@ -28,7 +29,7 @@ func TestWriteProducerMessage(t *testing.T) {
 	}{
 		{
 			name:    "Structured to Structured",
-			context: ctx,
+			context: binding.WithPreferredEventEncoding(ctx, binding.EncodingStructured),
 			messageFactory: func(e event.Event) binding.Message {
 				return MustCreateMockStructuredMessage(t, e)
 			},
@@ -80,3 +81,54 @@ func TestWriteProducerMessage(t *testing.T) {
 		}
 	})
 }
+
+func TestWriteProducerMessagePartitionKey(t *testing.T) {
+	tests := []struct {
+		name         string
+		partitionKey interface{}
+		key          []byte
+		structured   bool
+		expectedKey  []byte
+	}{
+		{
+			name:         "from partitionkey extension",
+			partitionKey: "hello-key",
+			expectedKey:  []byte("hello-key"),
+		},
+		{
+			name:         "from integer partitionkey extension",
+			partitionKey: int32(42),
+			expectedKey:  []byte("42"),
+		},
+		{
+			name:         "from partitionkey extension of structured message",
+			partitionKey: "hello-key",
+			structured:   true,
+			expectedKey:  []byte("hello-key"),
+		},
+		{
+			name:         "explicit key wins",
+			partitionKey: "hello-key",
+			key:          []byte("explicit-key"),
+			expectedKey:  []byte("explicit-key"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := FullEvent()
+			e.SetExtension(extensions.PartitionKeyExtensionKey, tt.partitionKey)
+
+			topic := "test-topic"
+			kafkaMessage := &kafka.Message{
+				TopicPartition: kafka.TopicPartition{Topic: &topic},
+				Key:            tt.key,
+			}
+			m := MustCreateMockBinaryMessage(e)
+			if tt.structured {
+				m = MustCreateMockStructuredMessage(t, e)
+			}
+			require.NoError(t, WriteProducerMessage(ctx, m, kafkaMessage))
+			require.Equal(t, tt.expectedKey, kafkaMessage.Key)
+		})
+	}
+}
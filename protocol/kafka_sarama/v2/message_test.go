@@ -6,6 +6,8 @@
 package kafka_sarama_test
 
 import (
+	"context"
+	"net/url"
 	"testing"
 
 	"github.com/IBM/sarama"
@@ -15,6 +17,7 @@ import (
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/cloudevents/sdk-go/v2/binding"
 	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/event"
 	"github.com/cloudevents/sdk-go/v2/test"
 )
 
@@ -92,3 +95,63 @@ func mustToSaramaConsumerHeaders(m map[string]string) []*sarama.RecordHeader {
 	}
 	return res
 }
+
+// FuzzNewMessageFromConsumerMessage verifies that parsing any headers and
+// value does not panic, and that the valid events they carry round trip once
+// normalized by a first write. The headers are URL query encoded.
+func FuzzNewMessageFromConsumerMessage(f *testing.F) {
+	for i, e := range test.GenerateEvents(1, 50) {
+		ctx := binding.WithForceBinary(context.Background())
+		if i%2 == 0 {
+			ctx = binding.WithForceStructured(context.Background())
+		}
+		cm := mustToConsumerMessage(f, ctx, e)
+		headers := url.Values{}
+		for _, h := range cm.Headers {
+			headers.Add(string(h.Key), string(h.Value))
+		}
+		f.Add(headers.Encode(), cm.Value)
+	}
+	f.Fuzz(func(t *testing.T, query string, value []byte) {
+		headers, err := url.ParseQuery(query)
+		if err != nil {
+			return
+		}
+		cm := &sarama.ConsumerMessage{Value: value}
+		for k, vs := range headers {
+			for _, v := range vs {
+				cm.Headers = append(cm.Headers, &sarama.RecordHeader{Key: []byte(k), Value: []byte(v)})
+			}
+		}
+		e, err := binding.ToEvent(context.Background(), kafka_sarama.NewMessageFromConsumerMessage(cm))
+		if err != nil || e.Validate() != nil {
+			return
+		}
+		// Extensions decoded before the specversion header are validated as
+		// 1.0 extensions, while 0.3 events accept any extension name.
+		for name := range e.Extensions() {
+			if !event.IsExtensionNameValid(name) {
+				return
+			}
+		}
+		test.AssertRoundTrip(t, *e, true, func(e event.Event) event.Event {
+			m := kafka_sarama.NewMessageFromConsumerMessage(mustToConsumerMessage(t, context.Background(), e))
+			return test.MustToEvent(t, context.Background(), m)
+		})
+	})
+}
+
+func mustToConsumerMessage(t testing.TB, ctx context.Context, e event.Event) *sarama.ConsumerMessage {
+	pm := &sarama.ProducerMessage{}
+	require.NoError(t, kafka_sarama.WriteProducerMessage(ctx, binding.ToMessage(&e), pm))
+	cm := &sarama.ConsumerMessage{}
+	if pm.Value != nil {
+		value, err := pm.Value.Encode()
+		require.NoError(t, err)
+		cm.Value = value
+	}
+	for _, h := range pm.Headers {
+		cm.Headers = append(cm.Headers, &sarama.RecordHeader{Key: h.Key, Value: h.Value})
+	}
+	return cm
+}
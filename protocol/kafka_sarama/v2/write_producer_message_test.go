@@ -16,6 +16,7 @@ import (
 	"github.com/cloudevents/sdk-go/v2/binding"
 	. "github.com/cloudevents/sdk-go/v2/binding/test"
 	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/extensions"
 	. "github.com/cloudevents/sdk-go/v2/test"
 )
 
@@ -158,7 +159,7 @@ func TestEncodeKafkaProducerMessage(t *testing.T) {
 
 				eventIn := ConvertEventExtensionsToString(t, e.Clone())
 				if tt.addPartitionKey {
-					eventIn.SetExtension(partitionKey, testKey)
+					eventIn.SetExtension(extensions.PartitionKeyExtensionKey, testKey)
 				}
 				messageIn := tt.messageFactory(eventIn)
 
@@ -197,3 +198,15 @@ func TestEncodeKafkaProducerMessage(t *testing.T) {
 	})
 
 }
+
+func TestEncodeKafkaProducerMessageIntegerPartitionKey(t *testing.T) {
+	e := FullEvent()
+	e.SetExtension(extensions.PartitionKeyExtensionKey, int32(42))
+	kafkaMessage := &sarama.ProducerMessage{Topic: "aaa"}
+
+	require.NoError(t, WriteProducerMessage(context.TODO(), MustCreateMockBinaryMessage(e), kafkaMessage))
+	require.NotNil(t, kafkaMessage.Key)
+	val, err := kafkaMessage.Key.Encode()
+	require.NoError(t, err)
+	require.Equal(t, "42", string(val))
+}
@@ -63,6 +63,18 @@ func WithSendSubject(sendSubject string) ProtocolOption {
 	}
 }
 
+// WithPartitionKeySubject appends the partitionkey extension of sent events, when set, as the last token of the
+// send subject. Events sharing a partition key are then published on the same subject, so that they can be
+// partitioned or filtered by subject. The partition key must be a valid subject token. Structured messages are
+// decoded to read it, and are sent in the binary encoding unless binding.WithForceStructured is used, as with any
+// transformer.
+func WithPartitionKeySubject() ProtocolOption {
+	return func(p *Protocol) error {
+		p.partitionKeySubject = true
+		return nil
+	}
+}
+
 // WithConsumerConfig creates a unordered consumer used in the protocol receiver.
 // This option is mutually exclusive with WithOrderedConsumerConfig.
 func WithConsumerConfig(consumerConfig *jetstream.ConsumerConfig) ProtocolOption {
@@ -10,9 +10,11 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"sync"
 
 	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/extensions"
 	"github.com/cloudevents/sdk-go/v2/protocol"
 
 	"github.com/nats-io/nats.go"
@@ -54,8 +56,9 @@ type Protocol struct {
 	jetstreamConsumer     jetstream.Consumer
 
 	// sender
-	publishOpts []jetstream.PublishOpt
-	sendSubject string
+	publishOpts         []jetstream.PublishOpt
+	sendSubject         string
+	partitionKeySubject bool
 }
 
 // New creates a new NATS protocol.
@@ -120,8 +123,9 @@ func (p *Protocol) Send(ctx context.Context, in binding.Message, transformers ..
 		}
 	}()
 
-	if _, err = p.jetStream.StreamNameBySubject(ctx, subject); err != nil {
-		return err
+	var partitionKey string
+	if p.partitionKeySubject {
+		transformers = append(transformers, extensions.PartitionKeyTransformer(&partitionKey))
 	}
 
 	writer := new(bytes.Buffer)
@@ -130,6 +134,17 @@ func (p *Protocol) Send(ctx context.Context, in binding.Message, transformers ..
 		return err
 	}
 
+	if partitionKey != "" {
+		if strings.ContainsAny(partitionKey, ".*> \t\r\n") {
+			return fmt.Errorf("partition key %q is not a valid subject token", partitionKey)
+		}
+		subject = subject + "." + partitionKey
+	}
+
+	if _, err = p.jetStream.StreamNameBySubject(ctx, subject); err != nil {
+		return err
+	}
+
 	natsMsg := &nats.Msg{
 		Subject: subject,
 		Data:    writer.Bytes(),
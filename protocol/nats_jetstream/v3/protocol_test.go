@@ -9,6 +9,9 @@ import (
 	"context"
 	"testing"
 
+	"github.com/cloudevents/sdk-go/v2/binding"
+	bindingtest "github.com/cloudevents/sdk-go/v2/binding/test"
+	"github.com/cloudevents/sdk-go/v2/extensions"
 	"github.com/cloudevents/sdk-go/v2/test"
 	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
@@ -83,3 +86,79 @@ func TestSend(t *testing.T) {
 		})
 	}
 }
+
+func TestSendPartitionKeySubject(t *testing.T) {
+	tests := []struct {
+		name         string
+		partitionKey interface{}
+		wantSubject  string
+		wantErr      bool
+	}{
+		{
+			name:        "no partition key",
+			wantSubject: "test.subject",
+		},
+		{
+			name:         "partition key",
+			partitionKey: "device-42",
+			wantSubject:  "test.subject.device-42",
+		},
+		{
+			name:         "integer partition key",
+			partitionKey: int32(42),
+			wantSubject:  "test.subject.42",
+		},
+		{
+			name:         "invalid subject token",
+			partitionKey: "device.42",
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		for _, structured := range []bool{false, true} {
+			name := tt.name
+			if structured {
+				name += " structured"
+			}
+			t.Run(name, func(t *testing.T) {
+				testSendPartitionKeySubject(t, tt.partitionKey, structured, tt.wantSubject, tt.wantErr)
+			})
+		}
+	}
+}
+
+func testSendPartitionKeySubject(t *testing.T, partitionKey interface{}, structured bool, wantSubject string, wantErr bool) {
+	var gotSubject string
+	mockJS := &mockJetStream{
+		streamNameBySubjectFunc: func(ctx context.Context, subject string) (string, error) {
+			return "test-stream", nil
+		},
+		publishMsgFunc: func(ctx context.Context, msg *nats.Msg, opts ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+			gotSubject = msg.Subject
+			return nil, nil
+		},
+	}
+
+	p := &Protocol{
+		jetStream:           mockJS,
+		sendSubject:         "test.subject",
+		partitionKeySubject: true,
+	}
+
+	e := test.FullEvent()
+	if partitionKey != nil {
+		e.SetExtension(extensions.PartitionKeyExtensionKey, partitionKey)
+	}
+	m := binding.ToMessage(&e)
+	if structured {
+		m = bindingtest.MustCreateMockStructuredMessage(t, e)
+	}
+	err := p.Send(context.Background(), m)
+	if (err != nil) != wantErr {
+		t.Fatalf("Send() error = %v, wantErr %v", err, wantErr)
+	}
+	if gotSubject != wantSubject {
+		t.Errorf("unexpected subject in publish: got %s, want %s", gotSubject, wantSubject)
+	}
+}
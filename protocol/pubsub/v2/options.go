@@ -227,6 +227,18 @@ func WithMessageOrdering() Option {
 	}
 }
 
+// WithPartitionKeyOrdering enables message ordering, and uses the partitionkey
+// extension of sent events as their ordering key, unless one is set with
+// WithOrderingKey. Structured messages are decoded to read it, and are sent in
+// the binary encoding unless binding.WithForceStructured is used.
+func WithPartitionKeyOrdering() Option {
+	return func(t *Protocol) error {
+		t.MessageOrdering = true
+		t.partitionKeyOrdering = true
+		return nil
+	}
+}
+
 // WithMessageOrderingFromEnv enables message ordering for all topics and
 // subscriptions from a given environment variable name.
 func WithMessageOrderingFromEnv(key string) Option {
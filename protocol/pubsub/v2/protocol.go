@@ -15,6 +15,7 @@ import (
 	"github.com/cloudevents/sdk-go/protocol/pubsub/v2/internal"
 	"github.com/cloudevents/sdk-go/v2/binding"
 	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/extensions"
 	"github.com/cloudevents/sdk-go/v2/protocol"
 	"golang.org/x/sync/errgroup"
 )
@@ -47,6 +48,10 @@ type Protocol struct {
 	// MessageOrdering enables message ordering for all topics and subscriptions.
 	MessageOrdering bool
 
+	// partitionKeyOrdering uses the partitionkey extension of sent events as
+	// their ordering key.
+	partitionKeyOrdering bool
+
 	projectID string
 	topicID   string
 
@@ -121,9 +126,20 @@ func (t *Protocol) Send(ctx context.Context, in binding.Message, transformers ..
 		msg.OrderingKey = key
 	}
 
+	// With WithPartitionKeyOrdering, the partitionkey extension is used as the
+	// ordering key, unless one is set explicitly. Structured messages are
+	// decoded to read it.
+	var partitionKey string
+	if t.MessageOrdering && t.partitionKeyOrdering && msg.OrderingKey == "" {
+		transformers = append(transformers, extensions.PartitionKeyTransformer(&partitionKey))
+	}
+
 	if err := WritePubSubMessage(ctx, in, msg, transformers...); err != nil {
 		return err
 	}
+	if partitionKey != "" {
+		msg.OrderingKey = partitionKey
+	}
 
 	if _, err := conn.Publish(ctx, msg); err != nil {
 		return err
@@ -238,6 +254,8 @@ var _ protocol.Closer = (*Protocol)(nil)
 type withOrderingKey struct{}
 
 // WithOrderingKey allows to set the Pub/Sub ordering key for publishing events.
+// When message ordering is enabled and no ordering key is set, the partitionkey
+// extension of the event is used instead.
 func WithOrderingKey(ctx context.Context, key string) context.Context {
 	return context.WithValue(ctx, withOrderingKey{}, key)
 }
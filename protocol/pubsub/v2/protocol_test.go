@@ -14,6 +14,9 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 
+	"github.com/cloudevents/sdk-go/v2/binding"
+	bindingtest "github.com/cloudevents/sdk-go/v2/binding/test"
+	"github.com/cloudevents/sdk-go/v2/extensions"
 	"github.com/cloudevents/sdk-go/v2/test"
 )
 
@@ -141,3 +144,78 @@ func TestPublishMessageHasOrderingKey(t *testing.T) {
 	err = prot.Send(WithOrderingKey(ctx, orderingKey), test.FullMessage())
 	require.NoError(err)
 }
+
+func TestPublishMessagePartitionKeyAsOrderingKey(t *testing.T) {
+	for name, tc := range map[string]struct {
+		partitionKey interface{}
+		orderingKey  string
+		structured   bool
+	}{
+		"string":             {partitionKey: "foobar", orderingKey: "foobar"},
+		"integer":            {partitionKey: int32(42), orderingKey: "42"},
+		"structured string":  {partitionKey: "foobar", orderingKey: "foobar", structured: true},
+		"structured integer": {partitionKey: int32(42), orderingKey: "42", structured: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.Background()
+			pc := &testPubsubClient{}
+			defer pc.Close()
+
+			projectID, topicID := "test-project", "test-topic"
+
+			client, err := pc.NewWithOrderInterceptor(ctx, projectID, tc.orderingKey)
+			require.NoError(err, "create pubsub client")
+			defer client.Close()
+
+			prot, err := New(ctx,
+				WithClient(client),
+				WithProjectID(projectID),
+				WithTopicID(topicID),
+				WithPartitionKeyOrdering(),
+				AllowCreateTopic(true),
+			)
+			require.NoError(err, "create protocol")
+
+			e := test.FullEvent()
+			e.SetExtension(extensions.PartitionKeyExtensionKey, tc.partitionKey)
+			m := binding.ToMessage(&e)
+			if tc.structured {
+				m = bindingtest.MustCreateMockStructuredMessage(t, e)
+			}
+			err = prot.Send(ctx, m)
+			require.NoError(err)
+		})
+	}
+}
+
+func TestPublishMessagePartitionKeyWithoutPartitionKeyOrdering(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	pc := &testPubsubClient{}
+	defer pc.Close()
+
+	projectID, topicID := "test-project", "test-topic"
+
+	client, err := pc.NewWithOrderInterceptor(ctx, projectID, "")
+	require.NoError(err, "create pubsub client")
+	defer client.Close()
+
+	prot, err := New(ctx,
+		WithClient(client),
+		WithProjectID(projectID),
+		WithTopicID(topicID),
+		WithMessageOrdering(),
+		AllowCreateTopic(true),
+	)
+	require.NoError(err, "create protocol")
+
+	// Structured messages are sent as-is, without ordering key.
+	e := test.FullEvent()
+	e.SetExtension(extensions.PartitionKeyExtensionKey, "foobar")
+	err = prot.Send(ctx, bindingtest.MustCreateMockStructuredMessage(t, e))
+	require.NoError(err)
+	msgs := pc.srv.Messages()
+	require.Len(msgs, 1)
+	require.NotContains(msgs[0].Attributes, prefix+"id")
+}
@@ -11,6 +11,7 @@ require (
 	github.com/modern-go/reflect2 v1.0.2 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
 	go.uber.org/zap v1.28.0 // indirect
+	golang.org/x/time v0.15.0 // indirect
 )
 
 replace github.com/cloudevents/sdk-go/v2 => ../../v2
@@ -0,0 +1,109 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package jsonschema validates JSON event data against JSON Schema documents referenced by the `dataschema` attribute.
+
+To validate the events sent and received by a client against schemas stored next to the application:
+
+	c, err := client.New(p, client.WithSchemaValidation(jsonschema.NewDirResolver("./schemas")))
+
+or embedded in the binary:
+
+	//go:embed schemas
+	var schemas embed.FS
+
+	c, err := client.New(p, client.WithSchemaValidation(jsonschema.NewFSResolver(schemas)))
+*/
+package jsonschema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	js "github.com/santhosh-tekuri/jsonschema/v5"
+
+	"github.com/cloudevents/sdk-go/v2/event/datacodec/schema"
+)
+
+// NewFSResolver returns a schema.Resolver that loads JSON Schema documents
+// from fsys. See schema.NewFSResolver for how URIs are mapped to files.
+func NewFSResolver(fsys fs.FS) schema.Resolver {
+	return schema.NewFSResolver(fsys, Compile)
+}
+
+// NewDirResolver returns a schema.Resolver that loads JSON Schema documents
+// from the local directory dir.
+func NewDirResolver(dir string) schema.Resolver {
+	return schema.NewDirResolver(dir, Compile)
+}
+
+// Compile compiles a JSON Schema document. It implements schema.Compiler.
+// The draft is taken from the document's "$schema" keyword, defaulting to
+// 2020-12.
+func Compile(uri string, doc []byte) (schema.Schema, error) {
+	c := js.NewCompiler()
+	c.Draft = js.Draft2020
+	if err := c.AddResource(uri, bytes.NewReader(doc)); err != nil {
+		return nil, err
+	}
+	s, err := c.Compile(uri)
+	if err != nil {
+		return nil, err
+	}
+	return jsonSchema{s: s}, nil
+}
+
+type jsonSchema struct {
+	s *js.Schema
+}
+
+// Validate implements schema.Schema.Validate
+func (j jsonSchema) Validate(_ context.Context, contentType string, data []byte) error {
+	if !isJSON(contentType) {
+		return fmt.Errorf("[jsonschema] unsupported content type: %q", contentType)
+	}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return &schema.ValidationError{Violations: []schema.Violation{{Message: "invalid JSON: " + err.Error()}}}
+	}
+
+	err := j.s.Validate(v)
+	if verr, ok := err.(*js.ValidationError); ok {
+		return &schema.ValidationError{Violations: violations(nil, verr)}
+	}
+	return err
+}
+
+// violations flattens the tree of validation errors into its leaves, which
+// carry the actual reasons the data was rejected.
+func violations(out []schema.Violation, verr *js.ValidationError) []schema.Violation {
+	if len(verr.Causes) == 0 {
+		return append(out, schema.Violation{Location: verr.InstanceLocation, Message: verr.Message})
+	}
+	for _, c := range verr.Causes {
+		out = violations(out, c)
+	}
+	return out
+}
+
+func isJSON(contentType string) bool {
+	if i := strings.IndexRune(contentType, ';'); i != -1 {
+		contentType = contentType[:i]
+	}
+	contentType = strings.TrimSpace(strings.ToLower(contentType))
+	switch contentType {
+	case "", "application/json", "text/json":
+		return true
+	}
+	return strings.HasSuffix(contentType, "+json")
+}
@@ -0,0 +1,100 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package jsonschema_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/event/datacodec/schema"
+
+	"github.com/cloudevents/sdk-go/schema/jsonschema/v2"
+)
+
+const orderSchema = "https://example.com/schemas/order.json"
+
+func TestValidate(t *testing.T) {
+	r := jsonschema.NewDirResolver("testdata")
+	s, err := r.Resolve(context.Background(), orderSchema)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		contentType string
+		data        string
+		want        []schema.Violation
+		wantErr     string
+	}{
+		"valid": {
+			contentType: event.ApplicationJSON,
+			data:        `{"id":"abc","quantity":2}`,
+		},
+		"valid structured suffix": {
+			contentType: "application/vnd.order+json; charset=utf-8",
+			data:        `{"id":"abc","quantity":2}`,
+		},
+		"missing and out of range": {
+			contentType: event.ApplicationJSON,
+			data:        `{"quantity":0}`,
+			want: []schema.Violation{
+				{Location: "", Message: "missing properties: 'id'"},
+				{Location: "/quantity", Message: "must be >= 1 but found 0"},
+			},
+		},
+		"invalid json": {
+			contentType: event.ApplicationJSON,
+			data:        `{`,
+			want:        []schema.Violation{{Message: "invalid JSON: unexpected EOF"}},
+		},
+		"not json": {
+			contentType: event.ApplicationXML,
+			data:        `<order/>`,
+			wantErr:     `[jsonschema] unsupported content type: "application/xml"`,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			err := s.Validate(context.Background(), tc.contentType, []byte(tc.data))
+			switch {
+			case tc.wantErr != "":
+				require.EqualError(t, err, tc.wantErr)
+			case tc.want != nil:
+				var verr *schema.ValidationError
+				require.ErrorAs(t, err, &verr)
+				require.ElementsMatch(t, tc.want, verr.Violations)
+			default:
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEventValidation(t *testing.T) {
+	ctx := context.Background()
+	r := jsonschema.NewFSResolver(fstest.MapFS{
+		"schemas/order.json": {Data: []byte(`{"type":"object","required":["id"]}`)},
+	})
+
+	e := event.New()
+	e.SetID("id")
+	e.SetSource("source")
+	e.SetType("type")
+	e.SetDataSchema(orderSchema)
+	require.NoError(t, e.SetValidatedData(ctx, r, event.ApplicationJSON, map[string]string{"id": "abc"}))
+
+	err := e.SetValidatedData(ctx, r, event.ApplicationJSON, map[string]string{"name": "abc"})
+	var verr event.ValidationError
+	require.ErrorAs(t, err, &verr)
+	require.ErrorContains(t, verr["data"], "missing properties: 'id'")
+}
+
+func TestCompileError(t *testing.T) {
+	_, err := jsonschema.Compile("https://example.com/bad.json", []byte(`{"type": 1}`))
+	require.Error(t, err)
+}
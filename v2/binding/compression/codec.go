@@ -0,0 +1,142 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Codec compresses and decompresses data with a content coding.
+type Codec interface {
+	// Encoding is the name of the content coding, as registered for the HTTP
+	// Content-Encoding header, e.g. "gzip".
+	Encoding() string
+	// NewWriter returns a writer compressing to w. Closing it flushes the
+	// compressed data, but does not close w.
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	// NewReader returns a reader decompressing r. Closing it does not close r.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// Gzip is the "gzip" content coding.
+var Gzip Codec = gzipCodec{}
+
+// ErrTooLarge is returned when decompressed data exceeds its size limit.
+var ErrTooLarge = errors.New("decompressed data too large")
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]Codec{}
+)
+
+func init() {
+	Add(Gzip)
+}
+
+// Add registers c, replacing the codec of its encoding if any.
+func Add(c Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[strings.ToLower(c.Encoding())] = c
+}
+
+// Lookup returns the codec of encoding, or nil if it is not registered.
+// Encodings are case-insensitive.
+func Lookup(encoding string) Codec {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	return codecs[strings.ToLower(strings.TrimSpace(encoding))]
+}
+
+// Encodings returns the registered encodings, sorted.
+func Encodings() []string {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	encodings := make([]string, 0, len(codecs))
+	for encoding := range codecs {
+		encodings = append(encodings, encoding)
+	}
+	sort.Strings(encodings)
+	return encodings
+}
+
+// Compress returns data compressed with c.
+func Compress(c Codec, data []byte) ([]byte, error) {
+	var b bytes.Buffer
+	w, err := c.NewWriter(&b)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		_ = w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Decompress returns data decompressed with c. If limit is positive,
+// decompressing more than limit bytes fails with ErrTooLarge.
+func Decompress(c Codec, data []byte, limit int64) ([]byte, error) {
+	r, err := c.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(LimitReader(r, limit))
+}
+
+// LimitReader returns a reader failing with ErrTooLarge once more than limit
+// bytes are read from r, or r itself if limit is not positive.
+func LimitReader(r io.Reader, limit int64) io.Reader {
+	if limit <= 0 {
+		return r
+	}
+	return &limitedReader{r: r, limit: limit}
+}
+
+type limitedReader struct {
+	r     io.Reader
+	limit int64
+	read  int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.read > l.limit {
+		return 0, fmt.Errorf("%w: more than %d bytes", ErrTooLarge, l.limit)
+	}
+	// Read one byte more than the limit, to tell whether it is exceeded.
+	if left := l.limit - l.read + 1; int64(len(p)) > left {
+		p = p[:left]
+	}
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.read > l.limit {
+		return n - int(l.read-l.limit), fmt.Errorf("%w: more than %d bytes", ErrTooLarge, l.limit)
+	}
+	return n, err
+}
+
+type gzipCodec struct{}
+
+func (gzipCodec) Encoding() string { return "gzip" }
+
+func (gzipCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+func (gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
@@ -0,0 +1,70 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package compression
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodecs(t *testing.T) {
+	data := []byte(strings.Repeat(`{"name":"Jane","email":"jane@example.com"}`, 100))
+	for _, c := range []Codec{Gzip} {
+		c := c
+		t.Run(c.Encoding(), func(t *testing.T) {
+			require.Equal(t, c, Lookup(strings.ToUpper(c.Encoding())))
+
+			compressed, err := Compress(c, data)
+			require.NoError(t, err)
+			require.Less(t, len(compressed), len(data))
+
+			got, err := Decompress(c, compressed, 0)
+			require.NoError(t, err)
+			require.Equal(t, data, got)
+
+			empty, err := Compress(c, nil)
+			require.NoError(t, err)
+			got, err = Decompress(c, empty, 0)
+			require.NoError(t, err)
+			require.Empty(t, got)
+
+			_, err = Decompress(c, data, 0)
+			require.Error(t, err)
+		})
+	}
+	require.Equal(t, []string{"gzip"}, Encodings())
+	require.Nil(t, Lookup("br"))
+}
+
+func TestDecompress_limit(t *testing.T) {
+	data := bytes.Repeat([]byte("a"), 1000)
+	compressed, err := Compress(Gzip, data)
+	require.NoError(t, err)
+
+	got, err := Decompress(Gzip, compressed, 1000)
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+
+	_, err = Decompress(Gzip, compressed, 999)
+	require.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestLimitReader(t *testing.T) {
+	r := LimitReader(strings.NewReader("abcdef"), 3)
+	got, err := io.ReadAll(r)
+	require.ErrorIs(t, err, ErrTooLarge)
+	require.Equal(t, "abc", string(got))
+	_, err = r.Read(make([]byte, 1))
+	require.ErrorIs(t, err, ErrTooLarge)
+
+	got, err = io.ReadAll(LimitReader(strings.NewReader("abc"), 3))
+	require.NoError(t, err)
+	require.Equal(t, "abc", string(got))
+}
@@ -0,0 +1,26 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package compression compresses the data of events, with the "gzip" content
+coding, or others registered with Add. The "zstd" and "snappy" content codings
+are registered by importing the
+github.com/cloudevents/sdk-go/binding/compression/klauspost/v2 module.
+
+On HTTP, the protocol/http package uses the Content-Encoding and
+Accept-Encoding headers, see http.WithCompression, for both binary and
+structured messages.
+
+On other protocols, such as Kafka or AMQP, CompressData compresses the data of
+the events sent, and sets their "dataencoding" extension, defined in the
+extensions package, to the encoding. The datacontenttype of the events is
+kept: the data must be decompressed before it applies. In binary mode the
+compressed data is the payload of the message, and in structured mode it is
+base64 encoded. DecompressData restores the data of the events received:
+
+	sender := pipeline.NewSender(p, compression.CompressData(compression.Gzip, 1024))
+	receiver := pipeline.NewReceiver(p, compression.DecompressData(16<<20))
+*/
+package compression
@@ -0,0 +1,68 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package compression
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/extensions"
+)
+
+// CompressData returns a transformer compressing the data of events with c,
+// and setting their dataencoding extension to its encoding. Data smaller than
+// threshold bytes, and data already encoded, is not compressed. The data keeps
+// its datacontenttype, and is base64 encoded in structured mode.
+func CompressData(c Codec, threshold int) binding.EventTransformerFunc {
+	return func(ctx context.Context, e *event.Event) error {
+		data := e.Data()
+		if len(data) == 0 || len(data) < threshold {
+			return nil
+		}
+		if _, ok := extensions.DataEncoding.Get(*e); ok {
+			return nil
+		}
+		compressed, err := Compress(c, data)
+		if err != nil {
+			return err
+		}
+		if err := extensions.DataEncoding.Set(e, c.Encoding()); err != nil {
+			return err
+		}
+		e.DataEncoded = compressed
+		e.DataBase64 = true
+		return nil
+	}
+}
+
+// DecompressData returns a transformer decompressing the data of events with
+// the codec of their dataencoding extension, and removing it. If limit is
+// positive, decompressing more than limit bytes fails with ErrTooLarge. Data
+// which is not valid UTF-8 stays base64 encoded in structured mode. Events
+// without the extension are not modified.
+func DecompressData(limit int64) binding.EventTransformerFunc {
+	return func(ctx context.Context, e *event.Event) error {
+		encoding, ok := extensions.DataEncoding.Get(*e)
+		if !ok {
+			return nil
+		}
+		c := Lookup(encoding)
+		if c == nil {
+			return fmt.Errorf("unsupported data encoding %q", encoding)
+		}
+		data, err := Decompress(c, e.Data(), limit)
+		if err != nil {
+			return fmt.Errorf("cannot decompress %q data: %w", encoding, err)
+		}
+		extensions.DataEncoding.Delete(e)
+		e.DataEncoded = data
+		e.DataBase64 = !utf8.Valid(data)
+		return nil
+	}
+}
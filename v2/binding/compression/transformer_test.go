@@ -0,0 +1,90 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package compression
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	bindingtest "github.com/cloudevents/sdk-go/v2/binding/test"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/extensions"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+func TestCompressData(t *testing.T) {
+	data := `{"text":"` + strings.Repeat("a", 1000) + `"}`
+	e := test.MinEvent()
+	e.SetDataContentType(event.ApplicationJSON)
+	e.DataEncoded = []byte(data)
+
+	// Small data is not compressed.
+	small := test.MinEvent()
+	require.NoError(t, small.SetData(event.ApplicationJSON, "small"))
+	require.NoError(t, CompressData(Gzip, 100)(context.Background(), &small))
+	require.NotContains(t, small.Extensions(), extensions.DataEncodingExtensionKey)
+	require.Equal(t, `"small"`, string(small.Data()))
+
+	m := binding.WithEventTransformers(context.Background(), binding.ToMessage(&e), CompressData(Gzip, 100))
+
+	// In structured mode, the data is base64 encoded.
+	var structured bindingtest.MockStructuredMessage
+	_, err := binding.Write(context.Background(), m, &structured, nil)
+	require.NoError(t, err)
+	var envelope map[string]interface{}
+	require.NoError(t, json.Unmarshal(structured.Bytes, &envelope))
+	require.Equal(t, "gzip", envelope["dataencoding"])
+	require.Equal(t, event.ApplicationJSON, envelope["datacontenttype"])
+	require.Contains(t, envelope, "data_base64")
+	require.NotContains(t, envelope, "data")
+
+	// In binary mode, the data is the payload.
+	var binary bindingtest.MockBinaryMessage
+	_, err = binding.Write(context.Background(), m, nil, &binary)
+	require.NoError(t, err)
+	require.Less(t, len(binary.Body), len(data))
+	require.Equal(t, "gzip", binary.Extensions["dataencoding"])
+
+	for _, wire := range []binding.Message{&structured, &binary} {
+		got := test.MustToEvent(t, context.Background(), binding.WithEventTransformers(context.Background(), wire, DecompressData(0)))
+		require.JSONEq(t, data, string(got.Data()))
+		require.False(t, got.DataBase64)
+		require.NotContains(t, got.Extensions(), extensions.DataEncodingExtensionKey)
+	}
+
+	// Data already encoded is not compressed again.
+	compressed := test.MustToEvent(t, context.Background(), &binary)
+	require.NoError(t, CompressData(Gzip, 0)(context.Background(), &compressed))
+	require.Equal(t, "gzip", compressed.Extensions()["dataencoding"])
+	require.Equal(t, binary.Body, compressed.Data())
+}
+
+func TestDecompressData_errors(t *testing.T) {
+	e := test.MinEvent()
+	e.DataEncoded = []byte(strings.Repeat("a", 1000))
+	require.NoError(t, CompressData(Gzip, 0)(context.Background(), &e))
+
+	tooLarge := e.Clone()
+	require.ErrorIs(t, DecompressData(999)(context.Background(), &tooLarge), ErrTooLarge)
+
+	unsupported := e.Clone()
+	unsupported.SetExtension("dataencoding", "br")
+	require.Error(t, DecompressData(0)(context.Background(), &unsupported))
+
+	invalid := e.Clone()
+	invalid.DataEncoded = []byte("not gzip")
+	require.Error(t, DecompressData(0)(context.Background(), &invalid))
+
+	// Events without the extension are not modified.
+	plain := test.MinEvent()
+	require.NoError(t, DecompressData(0)(context.Background(), &plain))
+	test.AssertEventEquals(t, test.MinEvent(), plain)
+}
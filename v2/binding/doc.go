@@ -35,6 +35,8 @@ in order to simplify the encoding process.
 
 The encoding process can be customized in order to mutate the final result with binding.TransformerFactory.
 A bunch of these are provided directly by the binding/transformer module.
+Transformers only access the metadata of messages: to rewrite the data too, wrap the message with
+binding.WithEventTransformers, which applies binding.EventTransformer(s) to its event when it is encoded.
 
 Usually binding.Message implementations can be encoded only one time, because the encoding process drain the message itself.
 In order to consume a message several times, the binding/buffering package provides several APIs to buffer the Message.
@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/cloudevents/sdk-go/v2/event"
@@ -88,6 +89,16 @@ func unknown(mediaType string) error {
 // Add a new Format. It can be retrieved by Lookup(f.MediaType())
 func Add(f Format) { formats[f.MediaType()] = f }
 
+// MediaTypes returns the sorted media types of the added formats.
+func MediaTypes() []string {
+	mediaTypes := make([]string, 0, len(formats))
+	for mt := range formats {
+		mediaTypes = append(mediaTypes, mt)
+	}
+	sort.Strings(mediaTypes)
+	return mediaTypes
+}
+
 // Marshal an event to bytes using the mediaType event format.
 func Marshal(mediaType string, e *event.Event) ([]byte, error) {
 	if f := formats[mediaType]; f != nil {
@@ -13,6 +13,7 @@ import (
 
 	"github.com/cloudevents/sdk-go/v2/binding/format"
 	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/test"
 	"github.com/cloudevents/sdk-go/v2/types"
 )
 
@@ -121,6 +122,36 @@ func TestAdd(t *testing.T) {
 	require.Equal([]byte("undummy!"), e.Data())
 }
 
+func TestMediaTypes(t *testing.T) {
+	mediaTypes := format.MediaTypes()
+	require.Contains(t, mediaTypes, event.ApplicationCloudEventsJSON)
+	require.Contains(t, mediaTypes, event.ApplicationCloudEventsBatchJSON)
+	require.IsIncreasing(t, mediaTypes)
+}
+
+// FuzzJSON verifies that unmarshalling any input does not panic, and that the
+// valid events it unmarshals round trip once normalized by a first marshal.
+func FuzzJSON(f *testing.F) {
+	for _, e := range test.GenerateEvents(1, 50) {
+		b, err := format.JSON.Marshal(&e)
+		require.NoError(f, err)
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var e event.Event
+		if err := format.JSON.Unmarshal(b, &e); err != nil || e.Validate() != nil {
+			return
+		}
+		test.AssertRoundTrip(t, e, true, func(e event.Event) event.Event {
+			b, err := format.JSON.Marshal(&e)
+			require.NoError(t, err)
+			var got event.Event
+			require.NoError(t, format.JSON.Unmarshal(b, &got), string(b))
+			return got
+		})
+	})
+}
+
 func assertJsonEquals(t *testing.T, want map[string]interface{}, got []byte) {
 	var gotToCompare map[string]interface{}
 	require.NoError(t, json.Unmarshal(got, &gotToCompare))
@@ -45,7 +45,7 @@ func ToEvent(ctx context.Context, message MessageReader, transformers ...Transfo
 			case MessageWrapper:
 				m = mt.GetWrappedMessage()
 			default:
-				break
+				return nil, ErrCannotConvertToEvent
 			}
 		}
 		return nil, ErrCannotConvertToEvent
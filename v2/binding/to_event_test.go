@@ -103,6 +103,21 @@ func TestToEvent_wrapped_unknown(t *testing.T) {
 	require.Equal(t, binding.ErrUnknownEncoding, err)
 }
 
+// eventEncodingMessage claims the event encoding without being an event message.
+type eventEncodingMessage struct {
+	binding.Message
+}
+
+func (eventEncodingMessage) ReadEncoding() binding.Encoding {
+	return binding.EncodingEvent
+}
+
+func TestToEvent_wrapped_unknown_event_message(t *testing.T) {
+	got, err := binding.ToEvent(context.Background(), binding.WithFinish(eventEncodingMessage{UnknownMessage}, func(err error) {}))
+	require.Nil(t, got)
+	require.Equal(t, binding.ErrCannotConvertToEvent, err)
+}
+
 func TestToEvent_transformers_applied_once(t *testing.T) {
 	EachEvent(t, Events(), func(t *testing.T, v event.Event) {
 		testCases := []toEventTestCase{
@@ -0,0 +1,156 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package binding
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+type transformMessage struct {
+	Message
+	ctx          context.Context
+	transformers EventTransformers
+
+	once   sync.Once
+	event  *event.Event
+	format format.Format
+	err    error
+}
+
+var (
+	_ Message               = (*transformMessage)(nil)
+	_ MessageMetadataReader = (*transformMessage)(nil)
+	_ MessageContext        = (*transformMessage)(nil)
+	_ MessageWrapper        = (*transformMessage)(nil)
+)
+
+// WithEventTransformers returns a wrapper for m that applies transformers to
+// the event of m, including its data.
+//
+// The transformation is lazy: m is read and transformed once, when the wrapper
+// is first read, for instance when a protocol.Sender writes it. The wrapper
+// keeps the encoding of m: structured messages are marshalled again with their
+// format, and binary messages stay binary. The Transformers passed to the
+// write functions are applied after transformers, so they see the transformed
+// event.
+//
+// ctx is passed to transformers. Its Finish() calls m.Finish().
+func WithEventTransformers(ctx context.Context, m Message, transformers ...EventTransformer) Message {
+	return &transformMessage{Message: m, ctx: ctx, transformers: transformers}
+}
+
+// formatRecorder records the format of a structured message while converting
+// it to an event.
+type formatRecorder struct {
+	event  event.Event
+	format format.Format
+}
+
+func (r *formatRecorder) SetStructuredEvent(ctx context.Context, f format.Format, ev io.Reader) error {
+	r.format = f
+	return (*messageToEventBuilder)(&r.event).SetStructuredEvent(ctx, f, ev)
+}
+
+// transform returns the transformed event of the message, reading and
+// transforming it on the first call.
+func (m *transformMessage) transform() (*event.Event, error) {
+	m.once.Do(func() {
+		var e event.Event
+		if m.Message.ReadEncoding() == EncodingStructured {
+			r := formatRecorder{}
+			if m.err = m.Message.ReadStructured(m.ctx, &r); m.err != nil {
+				return
+			}
+			e, m.format = r.event, r.format
+		} else {
+			read, err := ToEvent(m.ctx, m.Message)
+			if err != nil {
+				m.err = err
+				return
+			}
+			// Do not modify the event of event messages.
+			e = read.Clone()
+		}
+		if m.err = m.transformers.TransformEvent(m.ctx, &e); m.err == nil {
+			m.event = &e
+		}
+	})
+	return m.event, m.err
+}
+
+func (m *transformMessage) ReadStructured(ctx context.Context, w StructuredWriter) error {
+	if m.Message.ReadEncoding() != EncodingStructured {
+		return ErrNotStructured
+	}
+	e, err := m.transform()
+	if err != nil {
+		return err
+	}
+	b, err := m.format.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return w.SetStructuredEvent(ctx, m.format, bytes.NewReader(b))
+}
+
+func (m *transformMessage) ReadBinary(ctx context.Context, w BinaryWriter) error {
+	if m.Message.ReadEncoding() != EncodingBinary {
+		return ErrNotBinary
+	}
+	e, err := m.transform()
+	if err != nil {
+		return err
+	}
+	return (*EventMessage)(e).ReadBinary(ctx, w)
+}
+
+func (m *transformMessage) GetAttribute(k spec.Kind) (spec.Attribute, interface{}) {
+	e, err := m.transform()
+	if err != nil {
+		return nil, nil
+	}
+	return (*EventMessage)(e).GetAttribute(k)
+}
+
+func (m *transformMessage) GetExtension(name string) interface{} {
+	e, err := m.transform()
+	if err != nil {
+		return nil
+	}
+	return (*EventMessage)(e).GetExtension(name)
+}
+
+// Context returns the Context of the wrapped message, so that handlers can
+// still access its transport information, or else the Context passed to
+// WithEventTransformers.
+func (m *transformMessage) Context() context.Context {
+	if mctx, ok := m.Message.(MessageContext); ok {
+		return mctx.Context()
+	}
+	return m.ctx
+}
+
+// GetWrappedMessage returns the wrapped message. For event messages, it
+// returns a copy of the transformed event instead, so that ToEvent reads it,
+// or nil if the event cannot be transformed.
+func (m *transformMessage) GetWrappedMessage() Message {
+	if m.Message.ReadEncoding() != EncodingEvent {
+		return m.Message
+	}
+	e, err := m.transform()
+	if err != nil {
+		return nil
+	}
+	c := e.Clone()
+	return (*EventMessage)(&c)
+}
@@ -0,0 +1,158 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package binding_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	bindingtest "github.com/cloudevents/sdk-go/v2/binding/test"
+	"github.com/cloudevents/sdk-go/v2/binding/transformer"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+func TestWithEventTransformers(t *testing.T) {
+	e := test.FullEvent()
+	setData := binding.EventTransformerFunc(func(ctx context.Context, e *event.Event) error {
+		return e.SetData(event.TextPlain, "transformed")
+	})
+	want := e.Clone()
+	require.NoError(t, want.SetData(event.TextPlain, "transformed"))
+
+	tests := map[string]struct {
+		message  binding.Message
+		encoding binding.Encoding
+	}{
+		"structured": {bindingtest.MustCreateMockStructuredMessage(t, e), binding.EncodingStructured},
+		"binary":     {bindingtest.MustCreateMockBinaryMessage(e), binding.EncodingBinary},
+		"event":      {binding.ToMessage(&e), binding.EncodingEvent},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			m := binding.WithEventTransformers(context.Background(), tc.message, setData)
+			require.Equal(t, tc.encoding, m.ReadEncoding())
+
+			// The encoding is kept.
+			var structured bindingtest.MockStructuredMessage
+			var binary bindingtest.MockBinaryMessage
+			enc, err := binding.Write(context.Background(), m, &structured, &binary)
+			require.NoError(t, err)
+			var got event.Event
+			switch enc {
+			case binding.EncodingStructured:
+				got = test.MustToEvent(t, context.Background(), &structured)
+			case binding.EncodingBinary:
+				got = test.MustToEvent(t, context.Background(), &binary)
+			}
+			if tc.encoding != binding.EncodingEvent {
+				require.Equal(t, tc.encoding, enc)
+			}
+			test.AssertEventEquals(t, test.ConvertEventExtensionsToString(t, want), test.ConvertEventExtensionsToString(t, got))
+
+			// Metadata transformers see the transformed event.
+			var seen interface{}
+			transformed, err := binding.ToEvent(context.Background(), m, transformer.SetAttribute(spec.DataContentType, func(v interface{}) (interface{}, error) {
+				seen = v
+				return v, nil
+			}), transformer.AddExtension("added", "value"))
+			require.NoError(t, err)
+			require.Equal(t, event.TextPlain, seen)
+			require.Equal(t, "value", transformed.Extensions()["added"])
+
+			// Event messages are not modified.
+			test.AssertEventEquals(t, test.FullEvent(), e)
+		})
+	}
+}
+
+func TestWithEventTransformers_lazy(t *testing.T) {
+	e := test.MinEvent()
+	calls := 0
+	m := binding.WithEventTransformers(context.Background(), binding.ToMessage(&e), binding.EventTransformerFunc(func(ctx context.Context, e *event.Event) error {
+		calls++
+		e.SetExtension("calls", calls)
+		return nil
+	}))
+	require.Equal(t, 0, calls)
+
+	for i := 0; i < 3; i++ {
+		got := test.MustToEvent(t, context.Background(), m)
+		require.Equal(t, int32(1), got.Extensions()["calls"])
+	}
+	require.Equal(t, int32(1), m.(binding.MessageMetadataReader).GetExtension("calls"))
+	require.Equal(t, 1, calls)
+}
+
+func TestWithEventTransformers_error(t *testing.T) {
+	e := test.MinEvent()
+	failed := errors.New("failed")
+	fail := binding.EventTransformerFunc(func(ctx context.Context, e *event.Event) error {
+		return failed
+	})
+	tests := map[string]struct {
+		message binding.Message
+		want    error
+	}{
+		"binary": {bindingtest.MustCreateMockBinaryMessage(e), failed},
+		// Event messages are read through GetWrappedMessage.
+		"event": {binding.ToMessage(&e), binding.ErrCannotConvertToEvent},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			m := binding.WithEventTransformers(context.Background(), tc.message, fail)
+			_, err := binding.ToEvent(context.Background(), m)
+			require.ErrorIs(t, err, tc.want)
+			_, err = binding.Write(context.Background(), m, nil, &bindingtest.MockBinaryMessage{})
+			require.ErrorIs(t, err, tc.want)
+		})
+	}
+}
+
+func TestWithEventTransformers_context(t *testing.T) {
+	e := test.MinEvent()
+	ctx := context.WithValue(context.Background(), contextKey{}, "transform")
+	m := binding.WithEventTransformers(ctx, binding.ToMessage(&e))
+	require.Equal(t, "transform", m.(binding.MessageContext).Context().Value(contextKey{}))
+
+	// The context of the wrapped message takes precedence.
+	wrapped := &contextMessage{Message: bindingtest.MustCreateMockBinaryMessage(e), ctx: context.WithValue(ctx, contextKey{}, "wrapped")}
+	m = binding.WithEventTransformers(ctx, wrapped)
+	require.Equal(t, "wrapped", m.(binding.MessageContext).Context().Value(contextKey{}))
+	require.Same(t, wrapped, m.(binding.MessageWrapper).GetWrappedMessage())
+}
+
+type contextKey struct{}
+
+type contextMessage struct {
+	binding.Message
+	ctx context.Context
+}
+
+func (m *contextMessage) Context() context.Context {
+	return m.ctx
+}
+
+func TestWithEventTransformers_wrapped(t *testing.T) {
+	e := test.MinEvent()
+	addExtension := binding.EventTransformerFunc(func(ctx context.Context, e *event.Event) error {
+		e.SetExtension("transformed", true)
+		return nil
+	})
+	m := binding.WithFinish(binding.WithEventTransformers(context.Background(), binding.ToMessage(&e), addExtension), nil)
+	require.Equal(t, binding.EncodingEvent, m.ReadEncoding())
+
+	got, err := binding.ToEvent(context.Background(), m)
+	require.NoError(t, err)
+	require.Equal(t, true, got.Extensions()["transformed"])
+}
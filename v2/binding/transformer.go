@@ -5,6 +5,12 @@
 
 package binding
 
+import (
+	"context"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
 // Transformer is an interface that implements a transformation
 // process while transferring the event from the Message
 // implementation to the provided encoder
@@ -40,3 +46,36 @@ func (t Transformers) Transform(r MessageMetadataReader, w MessageMetadataWriter
 }
 
 var _ Transformer = (Transformers)(nil)
+
+// EventTransformer is an interface that implements a transformation of the
+// event of a message, including its data, unlike Transformer which can only
+// access the metadata of messages.
+//
+// EventTransformers are applied to messages wrapped with WithEventTransformers,
+// which reads the event of the message when it is first read.
+type EventTransformer interface {
+	TransformEvent(ctx context.Context, e *event.Event) error
+}
+
+// EventTransformerFunc is a type alias to implement an EventTransformer through a function pointer
+type EventTransformerFunc func(ctx context.Context, e *event.Event) error
+
+func (t EventTransformerFunc) TransformEvent(ctx context.Context, e *event.Event) error {
+	return t(ctx, e)
+}
+
+var _ EventTransformer = (EventTransformerFunc)(nil)
+
+// EventTransformers is a utility alias to run several EventTransformer
+type EventTransformers []EventTransformer
+
+func (t EventTransformers) TransformEvent(ctx context.Context, e *event.Event) error {
+	for _, transformer := range t {
+		if err := transformer.TransformEvent(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ EventTransformer = (EventTransformers)(nil)
@@ -0,0 +1,139 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package transformer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/internal/jsondata"
+)
+
+// SetData sets the data and the datacontenttype of events using the provided
+// function. updater gets the datacontenttype and the encoded data of the event,
+// as returned by event.Event.Data, and returns the new ones. Data which is not
+// valid UTF-8 is base64 encoded in structured mode.
+func SetData(updater func(ctx context.Context, contentType string, data []byte) (string, []byte, error)) binding.EventTransformerFunc {
+	return func(ctx context.Context, e *event.Event) error {
+		contentType, data, err := updater(ctx, e.DataContentType(), e.Data())
+		if err != nil {
+			return err
+		}
+		e.SetDataContentType(contentType)
+		e.DataEncoded = data
+		e.DataBase64 = e.DataBase64 || !utf8.Valid(data)
+		return nil
+	}
+}
+
+// ConvertData encodes the data of events with the datacodec of contentType,
+// after decoding it with the datacodec of their datacontenttype, e.g. to
+// convert JSON data to CBOR. Events without data, or with this content type,
+// are not modified.
+func ConvertData(contentType string) binding.EventTransformerFunc {
+	return func(ctx context.Context, e *event.Event) error {
+		if len(e.Data()) == 0 || e.DataContentType() == contentType {
+			return nil
+		}
+		var data interface{}
+		if err := e.DataAs(&data); err != nil {
+			return err
+		}
+		return e.SetData(contentType, data)
+	}
+}
+
+// MapJSON moves the fields of the JSON data of events. mapping associates the
+// path of a field to its new path, or to "" to delete it. Paths are the names
+// of the nested fields, separated by dots, e.g. "customer.name". Fields
+// missing from the data are skipped, and so are events without JSON object
+// data.
+func MapJSON(mapping map[string]string) binding.EventTransformerFunc {
+	from := make([]string, 0, len(mapping))
+	for path := range mapping {
+		from = append(from, path)
+	}
+	sort.Strings(from)
+
+	return func(ctx context.Context, e *event.Event) error {
+		if len(e.Data()) == 0 || !jsondata.IsJSON(e.DataMediaType()) {
+			return nil
+		}
+		var raw json.RawMessage
+		if err := e.DataAs(&raw); err != nil {
+			return err
+		}
+		var data interface{}
+		d := json.NewDecoder(bytes.NewReader(raw))
+		d.UseNumber()
+		if err := d.Decode(&data); err != nil {
+			return err
+		}
+		obj, ok := data.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		// Remove all the fields before setting them, to allow swaps.
+		values := make(map[string]interface{}, len(from))
+		for _, path := range from {
+			if v, ok := removeJSONField(obj, strings.Split(path, ".")); ok {
+				values[path] = v
+			}
+		}
+		for _, path := range from {
+			v, ok := values[path]
+			if !ok || mapping[path] == "" {
+				continue
+			}
+			if err := setJSONField(obj, strings.Split(mapping[path], "."), v); err != nil {
+				return fmt.Errorf("cannot move %q to %q: %w", path, mapping[path], err)
+			}
+		}
+		return e.SetData(e.DataContentType(), obj)
+	}
+}
+
+func removeJSONField(obj map[string]interface{}, path []string) (interface{}, bool) {
+	for _, name := range path[:len(path)-1] {
+		child, ok := obj[name].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		obj = child
+	}
+	name := path[len(path)-1]
+	v, ok := obj[name]
+	delete(obj, name)
+	return v, ok
+}
+
+func setJSONField(obj map[string]interface{}, path []string, v interface{}) error {
+	for _, name := range path[:len(path)-1] {
+		switch child := obj[name].(type) {
+		case map[string]interface{}:
+			obj = child
+		case nil:
+			if _, ok := obj[name]; ok {
+				return fmt.Errorf("field %q is null", name)
+			}
+			c := map[string]interface{}{}
+			obj[name] = c
+			obj = c
+		default:
+			return fmt.Errorf("field %q is not an object", name)
+		}
+	}
+	obj[path[len(path)-1]] = v
+	return nil
+}
@@ -0,0 +1,137 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package transformer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	. "github.com/cloudevents/sdk-go/v2/test"
+)
+
+func TestSetData(t *testing.T) {
+	e := MinEvent()
+	require.NoError(t, e.SetData(event.ApplicationJSON, map[string]string{"a": "b"}))
+
+	require.NoError(t, SetData(func(ctx context.Context, contentType string, data []byte) (string, []byte, error) {
+		require.Equal(t, event.ApplicationJSON, contentType)
+		require.JSONEq(t, `{"a":"b"}`, string(data))
+		return event.TextPlain, []byte("text"), nil
+	})(context.Background(), &e))
+	require.Equal(t, event.TextPlain, e.DataContentType())
+	require.Equal(t, []byte("text"), e.Data())
+	require.False(t, e.DataBase64)
+
+	require.NoError(t, SetData(func(ctx context.Context, contentType string, data []byte) (string, []byte, error) {
+		return "application/octet-stream", []byte{0xff, 0xfe}, nil
+	})(context.Background(), &e))
+	require.Equal(t, []byte{0xff, 0xfe}, e.Data())
+	require.True(t, e.DataBase64)
+}
+
+func TestConvertData(t *testing.T) {
+	e := MinEvent()
+	require.NoError(t, ConvertData(event.TextPlain)(context.Background(), &e))
+	require.Nil(t, e.Data())
+
+	require.NoError(t, e.SetData(event.ApplicationJSON, "hello"))
+	require.NoError(t, ConvertData(event.TextPlain)(context.Background(), &e))
+	require.Equal(t, event.TextPlain, e.DataContentType())
+	require.Equal(t, "hello", string(e.Data()))
+
+	require.NoError(t, e.SetData(event.ApplicationJSON, map[string]int{"a": 1}))
+	require.NoError(t, ConvertData("application/vnd.example+json")(context.Background(), &e))
+	require.Equal(t, "application/vnd.example+json", e.DataContentType())
+	require.JSONEq(t, `{"a":1}`, string(e.Data()))
+
+	require.Error(t, ConvertData(event.ApplicationXML)(context.Background(), &e))
+}
+
+func TestMapJSON(t *testing.T) {
+	tests := map[string]struct {
+		mapping     map[string]string
+		contentType string
+		data        string
+		want        string
+		wantErr     bool
+	}{
+		"rename": {
+			mapping: map[string]string{"a": "b"},
+			data:    `{"a":1,"c":2}`,
+			want:    `{"b":1,"c":2}`,
+		},
+		"nested": {
+			mapping: map[string]string{"a.b": "c.d.e", "a.f": "g"},
+			data:    `{"a":{"b":1,"f":2}}`,
+			want:    `{"a":{},"c":{"d":{"e":1}},"g":2}`,
+		},
+		"delete": {
+			mapping: map[string]string{"a.b": ""},
+			data:    `{"a":{"b":1,"c":2}}`,
+			want:    `{"a":{"c":2}}`,
+		},
+		"swap": {
+			mapping: map[string]string{"a": "b", "b": "a"},
+			data:    `{"a":1,"b":2}`,
+			want:    `{"a":2,"b":1}`,
+		},
+		"missing fields": {
+			mapping: map[string]string{"a": "b", "c.d": "e", "f.g": "h"},
+			data:    `{"c":1,"f":null}`,
+			want:    `{"c":1,"f":null}`,
+		},
+		"large numbers": {
+			mapping: map[string]string{"a": "b"},
+			data:    `{"a":12345678901234567890}`,
+			want:    `{"b":12345678901234567890}`,
+		},
+		"JSON suffix": {
+			mapping:     map[string]string{"a": "b"},
+			contentType: "application/vnd.example+json",
+			data:        `{"a":1}`,
+			want:        `{"b":1}`,
+		},
+		"not an object": {
+			mapping: map[string]string{"a": "b"},
+			data:    `[{"a":1}]`,
+			want:    `[{"a":1}]`,
+		},
+		"not JSON": {
+			mapping:     map[string]string{"a": "b"},
+			contentType: event.TextPlain,
+			data:        `{"a":1}`,
+			want:        `{"a":1}`,
+		},
+		"field is not an object": {
+			mapping: map[string]string{"a": "b.c"},
+			data:    `{"a":1,"b":2}`,
+			wantErr: true,
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			if tc.contentType == "" {
+				tc.contentType = event.ApplicationJSON
+			}
+			e := MinEvent()
+			e.SetDataContentType(tc.contentType)
+			e.DataEncoded = []byte(tc.data)
+
+			err := MapJSON(tc.mapping)(context.Background(), &e)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.contentType, e.DataContentType())
+			require.JSONEq(t, tc.want, string(e.Data()))
+		})
+	}
+}
@@ -6,8 +6,12 @@
 package transformer
 
 import (
+	"fmt"
+
 	"github.com/cloudevents/sdk-go/v2/binding"
 	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/types"
 )
 
 // Version converts the event context version to the specified one.
@@ -42,3 +46,108 @@ func Version(newVersion spec.Version) binding.TransformerFunc {
 		return nil
 	}
 }
+
+// ConvertVersion converts the event context version to the specified one.
+// Unlike Version, attributes that have no equivalent in the new version are not
+// dropped: a v0.3 schemaurl that is not an absolute URI is kept in the
+// "schemaurl" extension when converting to v1.0, and restored when converting
+// back, the same as event.Event.ConvertVersion does.
+//
+// A v0.3 datacontentencoding is carried as the "datacontentencoding" extension
+// by binary messages, and is left untouched. Transformers do not modify the
+// data, use event.Event.ConvertVersion to decode base64 data.
+func ConvertVersion(newVersion spec.Version) binding.TransformerFunc {
+	return convertVersion(newVersion, false)
+}
+
+// StrictConvertVersion is like ConvertVersion, but returns an error wrapping
+// event.ErrLossyConversion instead of keeping an attribute in an extension.
+func StrictConvertVersion(newVersion spec.Version) binding.TransformerFunc {
+	return convertVersion(newVersion, true)
+}
+
+func convertVersion(newVersion spec.Version, strict bool) binding.TransformerFunc {
+	return func(reader binding.MessageMetadataReader, writer binding.MessageMetadataWriter) error {
+		_, sv := reader.GetAttribute(spec.SpecVersion)
+		if newVersion.String() == sv {
+			return nil
+		}
+
+		// Read everything up front: when converting an event in place, the
+		// reader and the writer are the same event.
+		type change struct {
+			oldAttr spec.Attribute
+			newAttr spec.Attribute
+			val     interface{}
+		}
+		var changes []change
+		hasDataSchema := false
+		for _, newAttr := range newVersion.Attributes() {
+			oldAttr, val := reader.GetAttribute(newAttr.Kind())
+			if oldAttr != nil && val != nil {
+				changes = append(changes, change{oldAttr: oldAttr, newAttr: newAttr, val: val})
+				hasDataSchema = hasDataSchema || newAttr.Kind() == spec.DataSchema
+			}
+		}
+		schemaURLExt := extension(reader, event.SchemaURLKey)
+		if schemaURLExt != nil && hasDataSchema && newVersion.String() == event.CloudEventsVersionV03 {
+			return fmt.Errorf("%w: both dataschema and the %q extension are set", event.ErrLossyConversion, event.SchemaURLKey)
+		}
+		if strict && newVersion.String() == event.CloudEventsVersionV1 && extension(reader, event.DataContentEncodingKey) != nil {
+			return fmt.Errorf("%w: datacontentencoding has no equivalent in %s", event.ErrLossyConversion, event.CloudEventsVersionV1)
+		}
+
+		if newVersion.String() == event.CloudEventsVersionV03 && schemaURLExt != nil {
+			if err := writer.SetExtension(event.SchemaURLKey, nil); err != nil {
+				return err
+			}
+		}
+
+		for _, c := range changes {
+			if err := writer.SetAttribute(c.oldAttr, nil); err != nil {
+				return err
+			}
+			switch c.newAttr.Kind() {
+			case spec.SpecVersion:
+				c.val = newVersion.String()
+			case spec.DataSchema:
+				if newVersion.String() == event.CloudEventsVersionV1 {
+					u, err := types.ToURL(c.val)
+					if err != nil {
+						return err
+					}
+					if !u.IsAbs() {
+						if strict {
+							return fmt.Errorf("%w: schemaurl %q is not an absolute URI", event.ErrLossyConversion, u.String())
+						}
+						if err := writer.SetExtension(event.SchemaURLKey, types.URIRef{URL: *u}); err != nil {
+							return err
+						}
+						continue
+					}
+				}
+			}
+			if err := writer.SetAttribute(c.newAttr, c.val); err != nil {
+				return err
+			}
+		}
+
+		if newVersion.String() == event.CloudEventsVersionV03 && schemaURLExt != nil {
+			u, err := types.ToURL(schemaURLExt)
+			if err != nil {
+				return err
+			}
+			return writer.SetAttribute(newVersion.AttributeFromKind(spec.DataSchema), u.String())
+		}
+		return nil
+	}
+}
+
+// extension returns the value of an extension, or nil if it is not set.
+func extension(reader binding.MessageMetadataReader, name string) interface{} {
+	v := reader.GetExtension(name)
+	if s, ok := v.(string); ok && s == "" {
+		return nil
+	}
+	return v
+}
@@ -58,3 +58,77 @@ func TestVersionTranscoder(t *testing.T) {
 		},
 	})
 }
+
+func TestConvertVersionTranscoder(t *testing.T) {
+	relSchema := types.URIRef{URL: url.URL{Path: "schemas/order.json"}}
+	var testEventV03 = event.Event{
+		Context: event.EventContextV03{
+			Source:    types.URIRef{URL: url.URL{Path: "source"}},
+			ID:        "id",
+			Type:      "type",
+			SchemaURL: &relSchema,
+		}.AsV03(),
+	}
+
+	var testEventV1 = event.Event{
+		Context: event.EventContextV1{
+			Source:     types.URIRef{URL: url.URL{Path: "source"}},
+			ID:         "id",
+			Type:       "type",
+			Extensions: map[string]interface{}{event.SchemaURLKey: relSchema},
+		}.AsV1(),
+	}
+
+	data := []byte("\"data\"")
+	require.NoError(t, testEventV03.SetData(event.ApplicationJSON, data))
+	require.NoError(t, testEventV1.SetData(event.ApplicationJSON, data))
+
+	test.RunTransformerTests(t, context.Background(), []test.TransformerTestArgs{
+		{
+			Name:         "V03 -> V1 with Mock Binary message",
+			InputMessage: test.MustCreateMockBinaryMessage(testEventV03),
+			WantEvent:    testEventV1,
+			Transformers: binding.Transformers{ConvertVersion(spec.V1)},
+		},
+		{
+			Name:         "V03 -> V1 with Event message",
+			InputEvent:   testEventV03,
+			WantEvent:    testEventV1,
+			Transformers: binding.Transformers{ConvertVersion(spec.V1)},
+		},
+		{
+			Name:         "V1 -> V03 with Mock Binary message",
+			InputMessage: test.MustCreateMockBinaryMessage(testEventV1),
+			WantEvent:    testEventV03,
+			Transformers: binding.Transformers{ConvertVersion(spec.V03)},
+		},
+		{
+			Name:         "V1 -> V03 with Event message",
+			InputEvent:   testEventV1,
+			WantEvent:    testEventV03,
+			Transformers: binding.Transformers{ConvertVersion(spec.V03)},
+		},
+		{
+			Name:         "V1 -> V03 strict with Event message",
+			InputEvent:   testEventV1,
+			WantEvent:    testEventV03,
+			Transformers: binding.Transformers{StrictConvertVersion(spec.V03)},
+		},
+	})
+}
+
+func TestStrictConvertVersionLossy(t *testing.T) {
+	e := event.Event{
+		Context: event.EventContextV03{
+			Source:    types.URIRef{URL: url.URL{Path: "source"}},
+			ID:        "id",
+			Type:      "type",
+			SchemaURL: &types.URIRef{URL: url.URL{Path: "schemas/order.json"}},
+		}.AsV03(),
+	}
+
+	_, err := binding.ToEvent(context.Background(), test.MustCreateMockBinaryMessage(e), StrictConvertVersion(spec.V1))
+	require.ErrorIs(t, err, event.ErrLossyConversion)
+	_, err = binding.ToEvent(context.Background(), binding.ToMessage(&e), StrictConvertVersion(spec.V1))
+	require.ErrorIs(t, err, event.ErrLossyConversion)
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/cloudevents/sdk-go/v2/binding"
 	cecontext "github.com/cloudevents/sdk-go/v2/context"
 	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/event/datacodec/schema"
 	"github.com/cloudevents/sdk-go/v2/protocol"
 )
 
@@ -102,6 +103,7 @@ type ceClient struct {
 	pollGoroutines            int
 	blockingCallback          bool
 	ackMalformedEvent         bool
+	schemaResolver            schema.Resolver
 }
 
 func (c *ceClient) applyOptions(opts ...Option) error {
@@ -132,6 +134,9 @@ func (c *ceClient) Send(ctx context.Context, e event.Event) protocol.Result {
 	if err = e.Validate(); err != nil {
 		return err
 	}
+	if err = e.ValidateData(ctx, c.schemaResolver); err != nil {
+		return err
+	}
 
 	// Event has been defaulted and validated, record we are going to perform send.
 	ctx, cb := c.observabilityService.RecordSendingEvent(ctx, e)
@@ -161,6 +166,9 @@ func (c *ceClient) Request(ctx context.Context, e event.Event) (*event.Event, pr
 	if err = e.Validate(); err != nil {
 		return nil, err
 	}
+	if err = e.ValidateData(ctx, c.schemaResolver); err != nil {
+		return nil, err
+	}
 
 	// Event has been defaulted and validated, record we are going to perform request.
 	ctx, cb := c.observabilityService.RecordRequestEvent(ctx, e)
@@ -212,6 +220,7 @@ func (c *ceClient) StartReceiver(ctx context.Context, fn interface{}) error {
 		c.inboundContextDecorators,
 		c.eventDefaulterFns,
 		c.ackMalformedEvent,
+		c.schemaResolver,
 	)
 	if err != nil {
 		return err
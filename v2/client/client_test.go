@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -27,6 +28,7 @@ import (
 	"github.com/cloudevents/sdk-go/v2/binding/test"
 	"github.com/cloudevents/sdk-go/v2/client"
 	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/event/datacodec/schema"
 	"github.com/cloudevents/sdk-go/v2/protocol"
 	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
 	"github.com/cloudevents/sdk-go/v2/types"
@@ -575,3 +577,81 @@ func (m *mockReceiver) Receive(ctx context.Context) (binding.Message, error) {
 		close(m.finished)
 	}), nil
 }
+
+// evenLength is a toy schema that rejects data of odd length.
+type evenLength struct{}
+
+func (evenLength) Validate(_ context.Context, _ string, data []byte) error {
+	if len(data)%2 == 1 {
+		return &schema.ValidationError{Violations: []schema.Violation{{Message: "odd length"}}}
+	}
+	return nil
+}
+
+func TestClientSchemaValidation(t *testing.T) {
+	r := schema.ResolverFunc(func(_ context.Context, uri string) (schema.Schema, error) {
+		if uri != "https://example.com/even" {
+			return nil, schema.ErrNotFound
+		}
+		return evenLength{}, nil
+	})
+	newEvent := func(data string) event.Event {
+		e := event.New()
+		e.SetID("id")
+		e.SetSource("/unit/test/client")
+		e.SetType("unit.test.client")
+		e.SetDataSchema("https://example.com/even")
+		_ = e.SetData(event.TextPlain, data)
+		return e
+	}
+
+	p, err := cehttp.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiver, err := client.New(p, client.WithSchemaValidation(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := make(chan event.Event, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = receiver.StartReceiver(ctx, func(e event.Event) {
+			received <- e
+		})
+	}()
+	server := httptest.NewServer(p)
+	defer server.Close()
+
+	// Received events are validated.
+	sender := simpleBinaryClient(server.URL)
+	if result := sender.Send(ctx, newEvent("ab")); !protocol.IsACK(result) {
+		t.Fatalf("expected ACK, got %v", result)
+	}
+	if got := <-received; string(got.Data()) != "ab" {
+		t.Errorf("unexpected data %q", got.Data())
+	}
+	result := sender.Send(ctx, newEvent("abc"))
+	var httpResult *cehttp.Result
+	if !protocol.ResultAs(result, &httpResult) || httpResult.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %v", result)
+	}
+
+	// Sent events are validated.
+	cp, err := cehttp.New(cehttp.WithTarget(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	validatingSender, err := client.New(cp, client.WithSchemaValidation(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var verr event.ValidationError
+	if result := validatingSender.Send(ctx, newEvent("abc")); !errors.As(result, &verr) {
+		t.Fatalf("expected a validation error, got %v", result)
+	}
+	if _, err := client.New(cp, client.WithSchemaValidation(nil)); err == nil {
+		t.Error("expected an error for a nil resolver")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/cloudevents/sdk-go/v2/binding"
 	cecontext "github.com/cloudevents/sdk-go/v2/context"
 	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/event/datacodec/schema"
 	"github.com/cloudevents/sdk-go/v2/protocol"
 )
 
@@ -29,12 +30,14 @@ func newReceiveInvoker(
 	inboundContextDecorators []func(context.Context, binding.Message) context.Context,
 	fns []EventDefaulter,
 	ackMalformedEvent bool,
+	schemaResolver schema.Resolver,
 ) (Invoker, error) {
 	r := &receiveInvoker{
 		eventDefaulterFns:        fns,
 		observabilityService:     observabilityService,
 		inboundContextDecorators: inboundContextDecorators,
 		ackMalformedEvent:        ackMalformedEvent,
+		schemaResolver:           schemaResolver,
 	}
 
 	if fn, err := receiver(fn); err != nil {
@@ -52,6 +55,7 @@ type receiveInvoker struct {
 	eventDefaulterFns        []EventDefaulter
 	inboundContextDecorators []func(context.Context, binding.Message) context.Context
 	ackMalformedEvent        bool
+	schemaResolver           schema.Resolver
 }
 
 func (r *receiveInvoker) Invoke(ctx context.Context, m binding.Message, respFn protocol.ResponseFn) (err error) {
@@ -70,7 +74,13 @@ func (r *receiveInvoker) Invoke(ctx context.Context, m binding.Message, respFn p
 	case r.fn != nil:
 		// Check if event is valid before invoking the receiver function
 		if e != nil {
-			if validationErr := e.Validate(); validationErr != nil {
+			validationErr := e.Validate()
+			if validationErr == nil {
+				// The data is validated with the context of the message, such
+				// as the one of the HTTP request.
+				validationErr = e.ValidateData(computeInboundContext(m, ctx, nil), r.schemaResolver)
+			}
+			if validationErr != nil {
 				r.observabilityService.RecordReceivedMalformedEvent(ctx, validationErr)
 				return respFn(ctx, nil, protocol.NewReceipt(r.ackMalformedEvent, "validation error in incoming event: %w", validationErr))
 			}
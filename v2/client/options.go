@@ -10,6 +10,7 @@ import (
 	"fmt"
 
 	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event/datacodec/schema"
 )
 
 // Option is the function signature required to be considered an client.Option.
@@ -139,3 +140,19 @@ func WithAckMalformedEvent() Option {
 		return nil
 	}
 }
+
+// WithSchemaValidation validates the data of the events sent and received
+// against their dataschema, resolved with r. Events which do not conform are
+// not sent, and received ones are rejected like the other invalid events.
+// Data referencing a schema unknown to r is not validated.
+func WithSchemaValidation(r schema.Resolver) Option {
+	return func(i interface{}) error {
+		if c, ok := i.(*ceClient); ok {
+			if r == nil {
+				return fmt.Errorf("client option was given an nil schema resolver")
+			}
+			c.schemaResolver = r
+		}
+		return nil
+	}
+}
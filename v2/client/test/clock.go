@@ -0,0 +1,122 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package test
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+)
+
+// VirtualClock is a cecontext.Clock whose time only moves with Advance, to
+// test time defaulters and retry backoffs without waiting.
+type VirtualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*virtualTimer
+	// changed is closed when timers are added.
+	changed chan struct{}
+}
+
+var _ cecontext.Clock = (*VirtualClock)(nil)
+
+// NewVirtualClock returns a VirtualClock set to now.
+func NewVirtualClock(now time.Time) *VirtualClock {
+	return &VirtualClock{now: now, changed: make(chan struct{})}
+}
+
+// Now implements cecontext.Clock.
+func (c *VirtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer implements cecontext.Clock. The timer fires when the clock is
+// advanced past its deadline.
+func (c *VirtualClock) NewTimer(d time.Duration) cecontext.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &virtualTimer{clock: c, deadline: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- c.now
+		return t
+	}
+	c.timers = append(c.timers, t)
+	close(c.changed)
+	c.changed = make(chan struct{})
+	return t
+}
+
+// Advance moves the time forward by d, firing the timers which are due, in
+// order of deadline.
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].deadline.Before(c.timers[j].deadline) })
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.deadline.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.c <- c.now
+	}
+	c.timers = pending
+}
+
+// Timers returns the number of timers waiting to fire.
+func (c *VirtualClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil blocks until at least n timers are waiting to fire, such as a
+// retry backoff, or until timeout. It returns whether there are.
+func (c *VirtualClock) BlockUntil(n int, timeout time.Duration) bool {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		c.mu.Lock()
+		if len(c.timers) >= n {
+			c.mu.Unlock()
+			return true
+		}
+		changed := c.changed
+		c.mu.Unlock()
+		select {
+		case <-changed:
+		case <-deadline.C:
+			return false
+		}
+	}
+}
+
+type virtualTimer struct {
+	clock    *VirtualClock
+	deadline time.Time
+	c        chan time.Time
+}
+
+func (t *virtualTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *virtualTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	for i, other := range t.clock.timers {
+		if other == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,303 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/client"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+// DefaultHarnessTimeout is the time a Harness waits for the client to handle
+// a delivered event.
+const DefaultHarnessTimeout = 5 * time.Second
+
+// HarnessEpoch is the initial time of the virtual clock of a Harness.
+var HarnessEpoch = time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Harness runs a real client over an in-memory protocol, capturing the
+// results, responses and Finish errors of the events it receives, and the
+// events it sends. The contexts of the client use a virtual clock, which
+// drives the time defaulters and the retry backoffs.
+type Harness struct {
+	// Client is the client under test.
+	Client client.Client
+	// Clock is the clock of the contexts of the client.
+	Clock *VirtualClock
+	// Timeout is the time waited for the client to handle a delivered event.
+	// It is DefaultHarnessTimeout by default.
+	Timeout time.Duration
+
+	t        *testing.T
+	inbound  chan *Delivery
+	received chan error
+
+	mu        sync.Mutex
+	sent      []event.Event
+	asserted  int
+	onSend    func(event.Event) protocol.Result
+	onRequest func(event.Event) (*event.Event, protocol.Result)
+}
+
+// Delivery is an event delivered to the client of a Harness, and how the
+// client handled it.
+type Delivery struct {
+	// Event is the delivered event.
+	Event event.Event
+	// Result is the result the client responded with.
+	Result protocol.Result
+	// Response is the event the client responded with, if any.
+	Response *event.Event
+	// FinishErr is the error the client finished the message with.
+	FinishErr error
+
+	t         *testing.T
+	responded chan struct{}
+	finished  chan struct{}
+}
+
+// NewHarness returns a Harness of a client created with opts. The receiver
+// of the client is stopped when the test ends.
+func NewHarness(t *testing.T, opts ...client.Option) *Harness {
+	h := &Harness{
+		Clock:   NewVirtualClock(HarnessEpoch),
+		Timeout: DefaultHarnessTimeout,
+		t:       t,
+		inbound: make(chan *Delivery),
+	}
+	opts = append([]client.Option{client.WithInboundContextDecorator(func(ctx context.Context, _ binding.Message) context.Context {
+		return cecontext.WithClock(ctx, h.Clock)
+	})}, opts...)
+	c, err := client.New((*harnessProtocol)(h), opts...)
+	require.NoError(t, err)
+	h.Client = c
+	return h
+}
+
+// StartReceiver starts the receiver of the client with fn, until the test
+// ends.
+func (h *Harness) StartReceiver(fn interface{}) {
+	h.t.Helper()
+	require.Nil(h.t, h.received, "receiver already started")
+	ctx, cancel := context.WithCancel(context.Background())
+	h.received = make(chan error, 1)
+	go func() {
+		h.received <- h.Client.StartReceiver(ctx, fn)
+	}()
+	h.t.Cleanup(func() {
+		cancel()
+		if err := <-h.received; err != nil {
+			h.t.Errorf("StartReceiver: %v", err)
+		}
+	})
+}
+
+// Deliver delivers e to the receiver of the client, and returns how it was
+// handled.
+func (h *Harness) Deliver(e event.Event) *Delivery {
+	h.t.Helper()
+	require.NotNil(h.t, h.received, "receiver not started")
+	d := &Delivery{Event: e, t: h.t, responded: make(chan struct{}), finished: make(chan struct{})}
+	timeout := time.NewTimer(h.Timeout)
+	defer timeout.Stop()
+	select {
+	case h.inbound <- d:
+	case err := <-h.received:
+		h.received <- err
+		require.FailNow(h.t, fmt.Sprintf("receiver stopped: %v", err))
+	case <-timeout.C:
+		require.FailNow(h.t, "timed out delivering the event")
+	}
+	for _, done := range []chan struct{}{d.responded, d.finished} {
+		select {
+		case <-done:
+		case <-timeout.C:
+			require.FailNow(h.t, "timed out waiting for the event to be handled")
+		}
+	}
+	return d
+}
+
+// OnSend sets the function returning the result of the events sent by the
+// client. Events are acknowledged by default.
+func (h *Harness) OnSend(fn func(event.Event) protocol.Result) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onSend = fn
+}
+
+// OnRequest sets the function returning the response and the result of the
+// requests of the client. Requests are acknowledged without response by
+// default.
+func (h *Harness) OnRequest(fn func(event.Event) (*event.Event, protocol.Result)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onRequest = fn
+}
+
+// Send sends e with the client, with the clock of the harness.
+func (h *Harness) Send(ctx context.Context, e event.Event) protocol.Result {
+	return h.Client.Send(cecontext.WithClock(ctx, h.Clock), e)
+}
+
+// Request sends the request e with the client, with the clock of the
+// harness.
+func (h *Harness) Request(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+	return h.Client.Request(cecontext.WithClock(ctx, h.Clock), e)
+}
+
+// Sent returns the events sent by the client, including requests and retries.
+func (h *Harness) Sent() []event.Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]event.Event(nil), h.sent...)
+}
+
+// RequireSent requires that the next sent event, which was not returned by a
+// previous call, matches matchers, and returns it.
+func (h *Harness) RequireSent(matchers ...test.EventMatcher) event.Event {
+	h.t.Helper()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	require.Less(h.t, h.asserted, len(h.sent), "no event sent")
+	e := h.sent[h.asserted]
+	h.asserted++
+	test.AssertEvent(h.t, e, matchers...)
+	return e
+}
+
+// RequireNothingSent requires that all the sent events were returned by
+// RequireSent.
+func (h *Harness) RequireNothingSent() {
+	h.t.Helper()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	require.Equal(h.t, h.asserted, len(h.sent), "unexpected sent events %v", h.sent[h.asserted:])
+}
+
+// RequireACK requires that the client acknowledged the event.
+func (d *Delivery) RequireACK() *Delivery {
+	d.t.Helper()
+	require.True(d.t, protocol.IsACK(d.Result), "result %v is not an ACK", d.Result)
+	return d
+}
+
+// RequireNACK requires that the client did not acknowledge the event.
+func (d *Delivery) RequireNACK() *Delivery {
+	d.t.Helper()
+	require.False(d.t, protocol.IsACK(d.Result), "result is an ACK")
+	return d
+}
+
+// RequireResponse requires that the client responded with an event matching
+// matchers.
+func (d *Delivery) RequireResponse(matchers ...test.EventMatcher) *Delivery {
+	d.t.Helper()
+	require.NotNil(d.t, d.Response, "no response")
+	test.AssertEvent(d.t, *d.Response, matchers...)
+	return d
+}
+
+// RequireNoResponse requires that the client responded without event.
+func (d *Delivery) RequireNoResponse() *Delivery {
+	d.t.Helper()
+	require.Nil(d.t, d.Response, "unexpected response")
+	return d
+}
+
+// harnessProtocol is the in-memory protocol of a Harness.
+type harnessProtocol Harness
+
+var (
+	_ protocol.Sender    = (*harnessProtocol)(nil)
+	_ protocol.Requester = (*harnessProtocol)(nil)
+	_ protocol.Responder = (*harnessProtocol)(nil)
+)
+
+// record records e as sent, and returns the function handling it.
+func (p *harnessProtocol) record(e event.Event) (func(event.Event) protocol.Result, func(event.Event) (*event.Event, protocol.Result)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent = append(p.sent, e.Clone())
+	return p.onSend, p.onRequest
+}
+
+// Send implements protocol.Sender. Results which are not ACKs are retried
+// with the retry parameters of the context.
+func (p *harnessProtocol) Send(ctx context.Context, m binding.Message, transformers ...binding.Transformer) (err error) {
+	defer func() { _ = m.Finish(err) }()
+	e, err := binding.ToEvent(ctx, m, transformers...)
+	if err != nil {
+		return err
+	}
+	params := cecontext.RetriesFrom(ctx)
+	for tries := 0; ; tries++ {
+		var result protocol.Result
+		if onSend, _ := p.record(*e); onSend != nil {
+			result = onSend(*e)
+		}
+		if protocol.IsACK(result) || params.Backoff(ctx, tries+1) != nil {
+			return result
+		}
+	}
+}
+
+// Request implements protocol.Requester.
+func (p *harnessProtocol) Request(ctx context.Context, m binding.Message, transformers ...binding.Transformer) (_ binding.Message, err error) {
+	defer func() { _ = m.Finish(err) }()
+	e, err := binding.ToEvent(ctx, m, transformers...)
+	if err != nil {
+		return nil, err
+	}
+	_, onRequest := p.record(*e)
+	if onRequest == nil {
+		return nil, nil
+	}
+	resp, result := onRequest(*e)
+	if resp == nil {
+		return nil, result
+	}
+	return binding.ToMessage(resp), result
+}
+
+// Respond implements protocol.Responder.
+func (p *harnessProtocol) Respond(ctx context.Context) (binding.Message, protocol.ResponseFn, error) {
+	select {
+	case <-ctx.Done():
+		return nil, nil, io.EOF
+	case d := <-p.inbound:
+		e := d.Event.Clone()
+		m := binding.WithFinish(binding.ToMessage(&e), func(err error) {
+			d.FinishErr = err
+			close(d.finished)
+		})
+		return m, func(ctx context.Context, m binding.Message, r protocol.Result, transformers ...binding.Transformer) error {
+			defer close(d.responded)
+			d.Result = r
+			if m != nil {
+				defer func() { _ = m.Finish(nil) }()
+				resp, err := binding.ToEvent(ctx, m, transformers...)
+				if err != nil {
+					return err
+				}
+				d.Response = resp
+			}
+			return nil
+		}, nil
+	}
+}
@@ -0,0 +1,102 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/client"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+func TestHarness_receive(t *testing.T) {
+	h := NewHarness(t, client.WithTimeNow())
+	failed := errors.New("failed")
+	h.StartReceiver(func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		switch e.ID() {
+		case "nack":
+			return nil, failed
+		case "reply":
+			resp := test.MinEvent()
+			return &resp, nil
+		}
+		return nil, nil
+	})
+
+	e := test.MinEvent()
+	e.SetID("ack")
+	d := h.Deliver(e).RequireACK().RequireNoResponse()
+	require.NoError(t, d.FinishErr)
+
+	e.SetID("nack")
+	d = h.Deliver(e).RequireNACK()
+	require.True(t, errors.Is(d.Result, failed))
+	require.NoError(t, d.FinishErr)
+
+	// Responses are defaulted with the virtual clock.
+	e.SetID("reply")
+	h.Clock.Advance(time.Hour)
+	h.Deliver(e).RequireACK().RequireResponse(test.HasType("com.example.MinEvent"), test.HasTime(HarnessEpoch.Add(time.Hour)))
+}
+
+func TestHarness_send(t *testing.T) {
+	h := NewHarness(t, client.WithTimeNow())
+	require.True(t, protocol.IsACK(h.Send(context.Background(), test.MinEvent())))
+	h.RequireSent(test.HasId("min-event"), test.HasTime(HarnessEpoch))
+	h.RequireNothingSent()
+
+	// NACKs are retried with backoffs on the virtual clock.
+	attempts := 0
+	h.OnSend(func(event.Event) protocol.Result {
+		attempts++
+		if attempts < 3 {
+			return protocol.ResultNACK
+		}
+		return nil
+	})
+	ctx := cecontext.WithRetriesConstantBackoff(context.Background(), time.Minute, 3)
+	result := make(chan protocol.Result)
+	go func() { result <- h.Send(ctx, test.MinEvent()) }()
+	for i := 0; i < 2; i++ {
+		require.True(t, h.Clock.BlockUntil(1, 5*time.Second))
+		h.Clock.Advance(time.Minute)
+	}
+	require.True(t, protocol.IsACK(<-result))
+	require.Len(t, h.Sent(), 4)
+
+	reply := test.FullEvent()
+	h.OnRequest(func(event.Event) (*event.Event, protocol.Result) { return &reply, nil })
+	resp, err := h.Request(context.Background(), test.MinEvent())
+	require.True(t, protocol.IsACK(err))
+	test.AssertEventEquals(t, reply, *resp)
+}
+
+func TestVirtualClock(t *testing.T) {
+	c := NewVirtualClock(HarnessEpoch)
+	t1, t2, t3 := c.NewTimer(2*time.Second), c.NewTimer(time.Second), c.NewTimer(3*time.Second)
+	require.Equal(t, 3, c.Timers())
+	require.True(t, t3.Stop())
+	require.False(t, t3.Stop())
+
+	c.Advance(time.Second)
+	require.Equal(t, HarnessEpoch.Add(time.Second), <-t2.C())
+	require.Equal(t, 1, c.Timers())
+	c.Advance(time.Hour)
+	require.Equal(t, HarnessEpoch.Add(time.Hour+time.Second), <-t1.C())
+	require.False(t, t1.Stop())
+	require.False(t, c.BlockUntil(1, time.Millisecond))
+
+	// Timers without delay fire right away.
+	<-c.NewTimer(0).C()
+}
@@ -0,0 +1,62 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package context
+
+import (
+	"context"
+	"time"
+)
+
+// Clock tells the time and creates timers. It is used by the time defaulters
+// and the retry backoffs, so that tests can control time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTimer returns a Timer sending the current time on its channel after
+	// at least d.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single event timer of a Clock.
+type Timer interface {
+	// C returns the channel on which the time is sent when the timer fires.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing. It returns false if the timer
+	// already fired or was stopped.
+	Stop() bool
+}
+
+// RealClock is the Clock of the time package.
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTimer(d time.Duration) Timer { return realTimer{time.NewTimer(d)} }
+
+type realTimer struct{ *time.Timer }
+
+func (t realTimer) C() <-chan time.Time { return t.Timer.C }
+
+// Opaque key type used to store the clock
+type clockKeyType struct{}
+
+var clockKey = clockKeyType{}
+
+// WithClock returns back a new context with the given clock.
+func WithClock(ctx context.Context, clock Clock) context.Context {
+	return context.WithValue(ctx, clockKey, clock)
+}
+
+// ClockFrom looks in the given context and returns the clock if found,
+// otherwise RealClock.
+func ClockFrom(ctx context.Context) Clock {
+	if clock, ok := ctx.Value(clockKey).(Clock); ok {
+		return clock
+	}
+	return RealClock
+}
@@ -0,0 +1,35 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package context_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+)
+
+type fixedClock struct {
+	cecontext.Clock
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time { return c.now }
+
+func TestClockFrom(t *testing.T) {
+	require.Equal(t, cecontext.RealClock, cecontext.ClockFrom(context.Background()))
+
+	clock := fixedClock{Clock: cecontext.RealClock, now: time.Unix(0, 0)}
+	ctx := cecontext.WithClock(context.Background(), clock)
+	require.Equal(t, time.Unix(0, 0), cecontext.ClockFrom(ctx).Now())
+
+	timer := cecontext.RealClock.NewTimer(time.Millisecond)
+	<-timer.C()
+	require.False(t, timer.Stop())
+}
@@ -9,6 +9,7 @@ import (
 	"context"
 	"errors"
 	"math"
+	"math/rand/v2"
 	"time"
 )
 
@@ -21,6 +22,25 @@ const (
 	BackoffStrategyExponential = "exponential"
 )
 
+// JitterStrategy randomizes the delays between retries, so that clients
+// failing at the same time do not retry at the same time.
+type JitterStrategy string
+
+const (
+	// JitterStrategyNone uses the delay of the backoff strategy.
+	JitterStrategyNone = "none"
+	// JitterStrategyFull uses a random delay between 0 and the delay of the
+	// backoff strategy.
+	JitterStrategyFull = "full"
+	// JitterStrategyEqual uses half of the delay of the backoff strategy, plus
+	// a random delay up to the other half.
+	JitterStrategyEqual = "equal"
+	// JitterStrategyDecorrelated uses a random delay between Period and three
+	// times the previous delay, regardless of the backoff strategy. MaxPeriod
+	// should be set to bound it.
+	JitterStrategyDecorrelated = "decorrelated"
+)
+
 var DefaultRetryParams = RetryParams{Strategy: BackoffStrategyNone}
 
 // RetryParams holds parameters applied to retries
@@ -37,40 +57,105 @@ type RetryParams struct {
 	// - for linear strategy: interval between retries = Period * retries
 	// - for exponential strategy: interval between retries = Period * retries^2
 	Period time.Duration
+
+	// MaxPeriod is the maximum delay between retries. If 0, the delay is not
+	// bounded.
+	MaxPeriod time.Duration
+
+	// Jitter is the jitter strategy applied to the delays between retries. If
+	// empty, no jitter is applied.
+	Jitter JitterStrategy
 }
 
 // BackoffFor tries will return the time duration that should be used for this
 // current try count.
 // `tries` is assumed to be the number of times the caller has already retried.
 func (r *RetryParams) BackoffFor(tries int) time.Duration {
+	var d time.Duration
 	switch r.Strategy {
 	case BackoffStrategyConstant:
-		return r.Period
+		d = r.Period
 	case BackoffStrategyLinear:
-		return r.Period * time.Duration(tries)
+		d = r.Period * time.Duration(tries)
 	case BackoffStrategyExponential:
 		exp := math.Exp2(float64(tries))
-		return r.Period * time.Duration(exp)
+		d = r.Period * time.Duration(exp)
 	case BackoffStrategyNone:
 		fallthrough // default
 	default:
-		return r.Period
+		d = r.Period
 	}
+	return r.bound(d)
+}
+
+// JitteredBackoffFor returns the delay of BackoffFor with the jitter strategy
+// applied. `prev` is the previous delay, used by the decorrelated strategy; it
+// is 0 before the first retry.
+func (r *RetryParams) JitteredBackoffFor(tries int, prev time.Duration) time.Duration {
+	switch r.Jitter {
+	case JitterStrategyFull:
+		return randDuration(r.BackoffFor(tries))
+	case JitterStrategyEqual:
+		d := r.BackoffFor(tries)
+		return d/2 + randDuration(d-d/2)
+	case JitterStrategyDecorrelated:
+		if prev < r.Period {
+			prev = r.Period
+		}
+		return r.bound(r.Period + randDuration(3*prev-r.Period))
+	default:
+		return r.BackoffFor(tries)
+	}
+}
+
+func (r *RetryParams) bound(d time.Duration) time.Duration {
+	if r.MaxPeriod > 0 && d > r.MaxPeriod {
+		return r.MaxPeriod
+	}
+	return d
+}
+
+// randDuration returns a random duration in [0, d).
+func randDuration(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d)
 }
 
 // Backoff is a blocking call to wait for the correct amount of time for the retry.
 // `tries` is assumed to be the number of times the caller has already retried.
 func (r *RetryParams) Backoff(ctx context.Context, tries int) error {
+	return r.Wait(ctx, tries, r.JitteredBackoffFor(tries, 0))
+}
+
+// Wait is a blocking call to wait for the delay d before the retry, such as
+// one requested by the server. It fails without waiting if the context has a
+// deadline before the end of the delay. The delay, and the time left before
+// the deadline, are measured with the Clock of the context.
+// `tries` is assumed to be the number of times the caller has already retried.
+func (r *RetryParams) Wait(ctx context.Context, tries int, d time.Duration) error {
 	if tries > r.MaxTries {
 		return errors.New("too many retries")
 	}
-	ticker := time.NewTicker(r.BackoffFor(tries))
+	clock := ClockFrom(ctx)
+	if deadline, ok := ctx.Deadline(); ok && deadline.Sub(clock.Now()) < d {
+		return errors.New("retry delay exceeds the context deadline")
+	}
+	if d <= 0 {
+		select {
+		case <-ctx.Done():
+			return errors.New("context has been cancelled")
+		default:
+			return nil
+		}
+	}
+	timer := clock.NewTimer(d)
 	select {
 	case <-ctx.Done():
-		ticker.Stop()
+		timer.Stop()
 		return errors.New("context has been cancelled")
-	case <-ticker.C:
-		ticker.Stop()
+	case <-timer.C():
 	}
 	return nil
 }
@@ -117,3 +117,122 @@ func TestRetryParams_BackoffFor(t *testing.T) {
 		})
 	}
 }
+
+func TestRetryParams_JitteredBackoffFor(t *testing.T) {
+	tests := map[string]struct {
+		rp       *RetryParams
+		tries    int
+		prev     time.Duration
+		min, max time.Duration
+	}{
+		"none": {
+			rp:    &RetryParams{Strategy: BackoffStrategyExponential, Period: time.Second},
+			tries: 2,
+			min:   4 * time.Second,
+			max:   4 * time.Second,
+		},
+		"none bounded": {
+			rp:    &RetryParams{Strategy: BackoffStrategyExponential, Period: time.Second, MaxPeriod: 3 * time.Second},
+			tries: 2,
+			min:   3 * time.Second,
+			max:   3 * time.Second,
+		},
+		"full": {
+			rp:    &RetryParams{Strategy: BackoffStrategyExponential, Period: time.Second, Jitter: JitterStrategyFull},
+			tries: 2,
+			min:   0,
+			max:   4 * time.Second,
+		},
+		"equal": {
+			rp:    &RetryParams{Strategy: BackoffStrategyExponential, Period: time.Second, Jitter: JitterStrategyEqual},
+			tries: 2,
+			min:   2 * time.Second,
+			max:   4 * time.Second,
+		},
+		"decorrelated first": {
+			rp:    &RetryParams{Strategy: BackoffStrategyExponential, Period: time.Second, Jitter: JitterStrategyDecorrelated},
+			tries: 1,
+			min:   time.Second,
+			max:   3 * time.Second,
+		},
+		"decorrelated": {
+			rp:    &RetryParams{Strategy: BackoffStrategyExponential, Period: time.Second, Jitter: JitterStrategyDecorrelated},
+			tries: 3,
+			prev:  5 * time.Second,
+			min:   time.Second,
+			max:   15 * time.Second,
+		},
+		"decorrelated bounded": {
+			rp:    &RetryParams{Strategy: BackoffStrategyExponential, Period: time.Second, MaxPeriod: 2 * time.Second, Jitter: JitterStrategyDecorrelated},
+			tries: 3,
+			prev:  5 * time.Second,
+			min:   time.Second,
+			max:   2 * time.Second,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				if got := tc.rp.JitteredBackoffFor(tc.tries, tc.prev); got < tc.min || got > tc.max {
+					t.Fatalf("JitteredBackoffFor() = %v, want between %v and %v", got, tc.min, tc.max)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryParams_Wait(t *testing.T) {
+	rp := &RetryParams{Strategy: BackoffStrategyConstant, MaxTries: 3}
+
+	if err := rp.Wait(context.Background(), 1, 0); err != nil {
+		t.Errorf("Wait() error = %v, want nil", err)
+	}
+	if err := rp.Wait(context.Background(), 4, time.Nanosecond); err == nil {
+		t.Errorf("Wait() error = nil, want too many retries")
+	}
+
+	// A delay past the deadline fails without waiting.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	start := time.Now()
+	if err := rp.Wait(ctx, 1, time.Hour); err == nil {
+		t.Errorf("Wait() error = nil, want deadline error")
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Wait() waited for %v", time.Since(start))
+	}
+}
+
+// firedClock is a Clock at now, whose timers fire immediately.
+type firedClock struct {
+	now time.Time
+}
+
+func (c firedClock) Now() time.Time { return c.now }
+
+func (c firedClock) NewTimer(time.Duration) Timer { return firedTimer{} }
+
+type firedTimer struct{}
+
+func (firedTimer) C() <-chan time.Time {
+	c := make(chan time.Time, 1)
+	c <- time.Time{}
+	return c
+}
+
+func (firedTimer) Stop() bool { return false }
+
+func TestRetryParams_Wait_clock(t *testing.T) {
+	rp := &RetryParams{Strategy: BackoffStrategyConstant, MaxTries: 3}
+	deadline := time.Now().Add(time.Minute)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	// The time left before the deadline is measured with the clock.
+	if err := rp.Wait(WithClock(ctx, firedClock{now: deadline.Add(-2 * time.Hour)}), 1, time.Hour); err != nil {
+		t.Errorf("Wait() error = %v, want nil", err)
+	}
+	if err := rp.Wait(WithClock(ctx, firedClock{now: deadline.Add(-time.Second)}), 1, time.Minute); err == nil {
+		t.Errorf("Wait() error = nil, want deadline error")
+	}
+}
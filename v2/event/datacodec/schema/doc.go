@@ -0,0 +1,11 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package schema holds the pluggable dataschema subsystem. A Resolver maps the `dataschema` attribute of an event to a
+Schema, which validates the encoded event data. Validation is opt-in: pass a Resolver to client.WithSchemaValidation,
+event.Event.ValidateData or event.Event.SetValidatedData.
+*/
+package schema
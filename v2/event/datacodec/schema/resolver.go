@@ -0,0 +1,98 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+// Compiler compiles the schema document found for uri into a Schema.
+type Compiler func(uri string, doc []byte) (Schema, error)
+
+type fsResolver struct {
+	fsys    fs.FS
+	compile Compiler
+
+	mu    sync.Mutex
+	cache map[string]Schema
+}
+
+// NewFSResolver returns a Resolver that loads schema documents from fsys, for
+// example an embed.FS, and compiles them with compile. Compiled schemas are
+// cached.
+//
+// A `dataschema` URI is looked up by its host and path, so that
+// "https://example.com/schemas/order.json" resolves to the file
+// "example.com/schemas/order.json", falling back to "schemas/order.json".
+// Files that do not exist result in ErrNotFound.
+func NewFSResolver(fsys fs.FS, compile Compiler) Resolver {
+	return &fsResolver{
+		fsys:    fsys,
+		compile: compile,
+		cache:   map[string]Schema{},
+	}
+}
+
+// NewDirResolver returns a Resolver that loads schema documents from the local
+// directory dir. See NewFSResolver for how URIs are mapped to files.
+func NewDirResolver(dir string, compile Compiler) Resolver {
+	return NewFSResolver(os.DirFS(dir), compile)
+}
+
+func (r *fsResolver) Resolve(_ context.Context, uri string) (Schema, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.cache[uri]; ok {
+		return s, nil
+	}
+
+	doc, err := r.load(uri)
+	if err != nil {
+		return nil, err
+	}
+	s, err := r.compile(uri, doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema %q: %w", uri, err)
+	}
+	r.cache[uri] = s
+	return s, nil
+}
+
+func (r *fsResolver) load(uri string) ([]byte, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	p := u.Path
+	if u.Opaque != "" {
+		p = u.Opaque
+	}
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+
+	candidates := []string{p}
+	if u.Host != "" {
+		candidates = []string{path.Join(u.Host, p), p}
+	}
+	for _, name := range candidates {
+		if !fs.ValidPath(name) || name == "." {
+			continue
+		}
+		doc, err := fs.ReadFile(r.fsys, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return doc, err
+	}
+	return nil, ErrNotFound
+}
@@ -0,0 +1,100 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotFound is returned by a Resolver when it has no schema for a URI.
+// Data referencing an unknown schema is not validated.
+var ErrNotFound = errors.New("schema not found")
+
+// Schema validates encoded event data.
+type Schema interface {
+	// Validate returns a *ValidationError if data does not conform to the
+	// schema, or any other error if the data could not be validated at all.
+	Validate(ctx context.Context, contentType string, data []byte) error
+}
+
+// Resolver resolves a `dataschema` URI to a Schema.
+type Resolver interface {
+	// Resolve returns the Schema for uri, or ErrNotFound.
+	Resolve(ctx context.Context, uri string) (Schema, error)
+}
+
+// ResolverFunc is an adapter to allow the use of ordinary functions as a Resolver.
+type ResolverFunc func(ctx context.Context, uri string) (Schema, error)
+
+// Resolve implements Resolver.Resolve
+func (f ResolverFunc) Resolve(ctx context.Context, uri string) (Schema, error) {
+	return f(ctx, uri)
+}
+
+// Violation is a single place where data does not conform to its schema.
+type Violation struct {
+	// Location points to the offending value within the data, for example a
+	// JSON pointer. It is empty when the violation applies to the whole data.
+	Location string
+	// Message describes the violation.
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.Location == "" {
+		return v.Message
+	}
+	return v.Location + ": " + v.Message
+}
+
+// ValidationError is returned when data does not conform to its dataschema.
+// Event validation reports it under the "data" key of event.ValidationError.
+type ValidationError struct {
+	// DataSchema is the URI of the schema the data was validated against.
+	DataSchema string
+	// Violations lists every violation found.
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	b := strings.Builder{}
+	b.WriteString(fmt.Sprintf("data does not conform to schema %q", e.DataSchema))
+	for i, v := range e.Violations {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		b.WriteString(v.String())
+	}
+	return b.String()
+}
+
+// Validate resolves dataSchema with r and validates data against it. It returns
+// nil if r is nil, dataSchema is empty, or r does not know the schema.
+func Validate(ctx context.Context, r Resolver, dataSchema string, contentType string, data []byte) error {
+	if r == nil || dataSchema == "" {
+		return nil
+	}
+
+	s, err := r.Resolve(ctx, dataSchema)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to resolve schema %q: %w", dataSchema, err)
+	}
+
+	err = s.Validate(ctx, contentType, data)
+	var verr *ValidationError
+	if errors.As(err, &verr) && verr.DataSchema == "" {
+		verr.DataSchema = dataSchema
+	}
+	return err
+}
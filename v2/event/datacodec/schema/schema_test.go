@@ -0,0 +1,115 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package schema_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event/datacodec/schema"
+)
+
+// prefixSchema is a toy schema requiring data to start with a prefix.
+type prefixSchema string
+
+func (p prefixSchema) Validate(_ context.Context, _ string, data []byte) error {
+	if !strings.HasPrefix(string(data), string(p)) {
+		return &schema.ValidationError{Violations: []schema.Violation{{Message: "missing prefix " + string(p)}}}
+	}
+	return nil
+}
+
+func compilePrefix(_ string, doc []byte) (schema.Schema, error) {
+	if len(doc) == 0 {
+		return nil, errors.New("empty schema")
+	}
+	return prefixSchema(doc), nil
+}
+
+func TestFSResolver(t *testing.T) {
+	fsys := fstest.MapFS{
+		"example.com/schemas/a.txt": {Data: []byte("a")},
+		"schemas/b.txt":             {Data: []byte("b")},
+		"empty.txt":                 {Data: []byte{}},
+	}
+	r := schema.NewFSResolver(fsys, compilePrefix)
+	ctx := context.Background()
+
+	testCases := map[string]struct {
+		uri     string
+		want    schema.Schema
+		wantErr error
+	}{
+		"host and path": {
+			uri:  "https://example.com/schemas/a.txt",
+			want: prefixSchema("a"),
+		},
+		"path fallback": {
+			uri:  "https://other.example.com/schemas/b.txt",
+			want: prefixSchema("b"),
+		},
+		"relative": {
+			uri:  "schemas/b.txt",
+			want: prefixSchema("b"),
+		},
+		"urn": {
+			uri:  "urn:schemas/b.txt",
+			want: prefixSchema("b"),
+		},
+		"not found": {
+			uri:     "https://example.com/schemas/c.txt",
+			wantErr: schema.ErrNotFound,
+		},
+		"escaping root": {
+			uri:     "../../etc/passwd",
+			wantErr: schema.ErrNotFound,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			got, err := r.Resolve(ctx, tc.uri)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+
+	_, err := r.Resolve(ctx, "empty.txt")
+	require.ErrorContains(t, err, "failed to compile schema")
+}
+
+func TestValidate(t *testing.T) {
+	ctx := context.Background()
+	fsys := fstest.MapFS{"a.txt": {Data: []byte("a")}}
+
+	// Disabled without a Resolver.
+	require.NoError(t, schema.Validate(ctx, nil, "a.txt", "text/plain", []byte("nope")))
+
+	r := schema.NewFSResolver(fsys, compilePrefix)
+	require.NoError(t, schema.Validate(ctx, r, "a.txt", "text/plain", []byte("apple")))
+	require.NoError(t, schema.Validate(ctx, r, "", "text/plain", []byte("nope")))
+	require.NoError(t, schema.Validate(ctx, r, "unknown.txt", "text/plain", []byte("nope")))
+
+	err := schema.Validate(ctx, r, "a.txt", "text/plain", []byte("nope"))
+	var verr *schema.ValidationError
+	require.ErrorAs(t, err, &verr)
+	require.Equal(t, "a.txt", verr.DataSchema)
+	require.Equal(t, []schema.Violation{{Message: "missing prefix a"}}, verr.Violations)
+	require.EqualError(t, err, `data does not conform to schema "a.txt": missing prefix a`)
+
+	r = schema.ResolverFunc(func(context.Context, string) (schema.Schema, error) {
+		return nil, errors.New("boom")
+	})
+	require.ErrorContains(t, schema.Validate(ctx, r, "a.txt", "text/plain", nil), "boom")
+}
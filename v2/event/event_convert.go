@@ -0,0 +1,182 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package event
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// ErrLossyConversion is returned when converting an event between spec
+// versions cannot be done without losing or moving information.
+var ErrLossyConversion = errors.New("lossy spec version conversion")
+
+// ConvertVersion returns a copy of the event converted to specVersion.
+//
+// Unlike SetSpecVersion, nothing is lost on the way:
+//
+//   - v0.3 data with datacontentencoding "base64" becomes binary data
+//     (DataBase64) in v1.0, and binary v1.0 data gets datacontentencoding
+//     "base64" in v0.3.
+//   - A v0.3 schemaurl that is not an absolute URI, as v1.0 dataschema
+//     requires, is kept in the "schemaurl" extension.
+//   - Any other v0.3 datacontentencoding is kept in the "datacontentencoding"
+//     extension.
+//
+// Both extensions are turned back into attributes when converting to v0.3, so
+// a round-trip gives back the original event. An error wrapping
+// ErrLossyConversion is returned if an extension cannot be carried over, for
+// example a v0.3 extension named after a v1.0 attribute, or two v0.3
+// extensions whose names only differ by case.
+func (e Event) ConvertVersion(specVersion string) (Event, error) {
+	return e.convertVersion(specVersion, false)
+}
+
+// ConvertVersionStrict is like ConvertVersion, but returns an error wrapping
+// ErrLossyConversion instead of keeping an attribute in an extension, or when
+// an extension name would not be kept as-is.
+func (e Event) ConvertVersionStrict(specVersion string) (Event, error) {
+	return e.convertVersion(specVersion, true)
+}
+
+func (e Event) convertVersion(specVersion string, strict bool) (Event, error) {
+	if e.Context == nil {
+		return Event{}, errors.New("missing Event.Context")
+	}
+	out := e.Clone()
+	if e.SpecVersion() == specVersion {
+		return out, nil
+	}
+
+	switch specVersion {
+	case CloudEventsVersionV1:
+		ec := e.Context.AsV03()
+		ctx, err := convertV03ToV1(ec, strict)
+		if err != nil {
+			return Event{}, err
+		}
+		if ec.DataContentEncoding != nil && *ec.DataContentEncoding == Base64 && !e.DataBase64 {
+			// Data set with SetData on a v0.3 event is kept base64 encoded.
+			if len(e.DataEncoded) > 0 {
+				data, err := e.legacyConvertData(e.DataEncoded)
+				if err != nil {
+					return Event{}, err
+				}
+				out.DataEncoded = data
+			}
+			out.DataBase64 = true
+		}
+		out.Context = ctx
+	case CloudEventsVersionV03:
+		ctx, err := convertV1ToV03(e.Context.AsV1(), e.DataBase64)
+		if err != nil {
+			return Event{}, err
+		}
+		out.Context = ctx
+	default:
+		return Event{}, fmt.Errorf("unrecognized event version %s", specVersion)
+	}
+	return out, nil
+}
+
+func convertV03ToV1(ec *EventContextV03, strict bool) (*EventContextV1, error) {
+	ret := &EventContextV1{
+		ID:              ec.ID,
+		Time:            ec.Time,
+		Type:            ec.Type,
+		DataContentType: ec.DataContentType,
+		Source:          types.URIRef{URL: ec.Source.URL},
+		Subject:         ec.Subject,
+		Extensions:      make(map[string]interface{}),
+	}
+
+	if ec.SchemaURL != nil {
+		if ec.SchemaURL.URL.IsAbs() {
+			ret.DataSchema = &types.URI{URL: ec.SchemaURL.URL}
+		} else if strict {
+			return nil, fmt.Errorf("%w: schemaurl %q is not an absolute URI", ErrLossyConversion, ec.SchemaURL.String())
+		} else {
+			ret.Extensions[SchemaURLKey] = *ec.SchemaURL
+		}
+	}
+
+	if ec.DataContentEncoding != nil && *ec.DataContentEncoding != Base64 {
+		if strict {
+			return nil, fmt.Errorf("%w: datacontentencoding %q has no equivalent in %s", ErrLossyConversion, *ec.DataContentEncoding, CloudEventsVersionV1)
+		}
+		ret.Extensions[DataContentEncodingKey] = *ec.DataContentEncoding
+	}
+
+	for k, v := range ec.Extensions {
+		lk := strings.ToLower(k)
+		if _, ok := specV1Attributes[lk]; ok {
+			return nil, fmt.Errorf("%w: extension %q is an attribute in %s", ErrLossyConversion, k, CloudEventsVersionV1)
+		}
+		if strict && (k != lk || !IsExtensionNameValid(k)) {
+			return nil, fmt.Errorf("%w: extension name %q is not valid in %s", ErrLossyConversion, k, CloudEventsVersionV1)
+		}
+		if _, ok := ret.Extensions[lk]; ok {
+			return nil, fmt.Errorf("%w: extension %q collides with another extension in %s", ErrLossyConversion, k, CloudEventsVersionV1)
+		}
+		ret.Extensions[lk] = v
+	}
+	if len(ret.Extensions) == 0 {
+		ret.Extensions = nil
+	}
+	return ret, nil
+}
+
+func convertV1ToV03(ec *EventContextV1, dataBase64 bool) (*EventContextV03, error) {
+	ret := &EventContextV03{
+		ID:              ec.ID,
+		Time:            ec.Time,
+		Type:            ec.Type,
+		DataContentType: ec.DataContentType,
+		Source:          types.URIRef{URL: ec.Source.URL},
+		Subject:         ec.Subject,
+		Extensions:      make(map[string]interface{}),
+	}
+
+	if ec.DataSchema != nil {
+		ret.SchemaURL = &types.URIRef{URL: ec.DataSchema.URL}
+	}
+	if dataBase64 {
+		enc := Base64
+		ret.DataContentEncoding = &enc
+	}
+
+	for k, v := range ec.Extensions {
+		switch k {
+		case SchemaURLKey:
+			if ret.SchemaURL != nil {
+				return nil, fmt.Errorf("%w: both dataschema and the %q extension are set", ErrLossyConversion, k)
+			}
+			u, err := types.ToURL(v)
+			if err != nil {
+				return nil, fmt.Errorf("extension %q: %w", k, err)
+			}
+			ret.SchemaURL = &types.URIRef{URL: *u}
+		case DataContentEncodingKey:
+			if ret.DataContentEncoding != nil {
+				return nil, fmt.Errorf("%w: both binary data and the %q extension are set", ErrLossyConversion, k)
+			}
+			s, err := types.ToString(v)
+			if err != nil {
+				return nil, fmt.Errorf("extension %q: %w", k, err)
+			}
+			ret.DataContentEncoding = &s
+		default:
+			ret.Extensions[k] = v
+		}
+	}
+	if len(ret.Extensions) == 0 {
+		ret.Extensions = nil
+	}
+	return ret, nil
+}
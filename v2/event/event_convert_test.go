@@ -0,0 +1,210 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package event_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+func TestConvertVersion(t *testing.T) {
+	now := types.Timestamp{Time: time.Date(2021, 1, 2, 3, 4, 5, 6, time.UTC)}
+	absSchema, _ := url.Parse("https://example.com/schema")
+	relSchema, _ := url.Parse("schemas/order.json")
+	source, _ := url.Parse("/source")
+
+	// fullV03 sets every v0.3 attribute.
+	fullV03 := func() event.EventContextV03 {
+		return event.EventContextV03{
+			ID:                  "ABC-123",
+			Time:                &now,
+			Type:                "com.example.simple",
+			SchemaURL:           &types.URIRef{URL: *absSchema},
+			DataContentType:     event.StringOfApplicationJSON(),
+			DataContentEncoding: event.StringOfBase64(),
+			Source:              types.URIRef{URL: *source},
+			Subject:             strptr("topic"),
+			Extensions:          map[string]interface{}{"exbool": true, "exint": int32(42), "exstring": "exstring"},
+		}
+	}
+	// fullV1 sets every v1.0 attribute.
+	fullV1 := func() event.EventContextV1 {
+		return event.EventContextV1{
+			ID:              "ABC-123",
+			Time:            &now,
+			Type:            "com.example.simple",
+			DataSchema:      &types.URI{URL: *absSchema},
+			DataContentType: event.StringOfApplicationJSON(),
+			Source:          types.URIRef{URL: *source},
+			Subject:         strptr("topic"),
+			Extensions:      map[string]interface{}{"exbool": true, "exint": int32(42), "exstring": "exstring"},
+		}
+	}
+	data := []byte(`{"hello":"world"}`)
+
+	testCases := map[string]struct {
+		in        event.Event
+		version   string
+		want      event.Event
+		wantErr   bool
+		strictErr bool
+	}{
+		"v0.3 -> v1.0, every attribute": {
+			in:      event.Event{Context: fullV03().AsV03(), DataEncoded: data, DataBase64: true},
+			version: event.CloudEventsVersionV1,
+			want:    event.Event{Context: fullV1().AsV1(), DataEncoded: data, DataBase64: true},
+		},
+		"v1.0 -> v0.3, every attribute": {
+			in:      event.Event{Context: fullV1().AsV1(), DataEncoded: data, DataBase64: true},
+			version: event.CloudEventsVersionV03,
+			want:    event.Event{Context: fullV03().AsV03(), DataEncoded: data, DataBase64: true},
+		},
+		"v0.3 -> v1.0, base64 data set with SetData": {
+			in: func() event.Event {
+				e := event.New(event.CloudEventsVersionV03)
+				e.SetDataContentEncoding(event.Base64)
+				_ = e.SetData(event.ApplicationJSON, data)
+				return e
+			}(),
+			version: event.CloudEventsVersionV1,
+			want: func() event.Event {
+				e := event.New(event.CloudEventsVersionV1)
+				e.SetDataContentType(event.ApplicationJSON)
+				e.DataEncoded = data
+				e.DataBase64 = true
+				return e
+			}(),
+		},
+		"v0.3 -> v1.0, relative schemaurl": {
+			in: event.Event{Context: func() *event.EventContextV03 {
+				ec := fullV03()
+				ec.SchemaURL = &types.URIRef{URL: *relSchema}
+				return ec.AsV03()
+			}(), DataEncoded: data, DataBase64: true},
+			version: event.CloudEventsVersionV1,
+			want: event.Event{Context: func() *event.EventContextV1 {
+				ec := fullV1()
+				ec.DataSchema = nil
+				ec.Extensions[event.SchemaURLKey] = types.URIRef{URL: *relSchema}
+				return ec.AsV1()
+			}(), DataEncoded: data, DataBase64: true},
+			strictErr: true,
+		},
+		"v0.3 -> v1.0, unknown datacontentencoding": {
+			in: event.Event{Context: func() *event.EventContextV03 {
+				ec := fullV03()
+				ec.DataContentEncoding = strptr("gzip")
+				return ec.AsV03()
+			}(), DataEncoded: data},
+			version: event.CloudEventsVersionV1,
+			want: event.Event{Context: func() *event.EventContextV1 {
+				ec := fullV1()
+				ec.Extensions[event.DataContentEncodingKey] = "gzip"
+				return ec.AsV1()
+			}(), DataEncoded: data},
+			strictErr: true,
+		},
+		"v0.3 -> v1.0, upper case extension": {
+			in: event.Event{Context: func() *event.EventContextV03 {
+				ec := fullV03()
+				ec.DataContentEncoding = nil
+				ec.Extensions = map[string]interface{}{"ExtName": "value"}
+				return ec.AsV03()
+			}()},
+			version: event.CloudEventsVersionV1,
+			want: event.Event{Context: func() *event.EventContextV1 {
+				ec := fullV1()
+				ec.Extensions = map[string]interface{}{"extname": "value"}
+				return ec.AsV1()
+			}()},
+			strictErr: true,
+		},
+		"v0.3 -> v1.0, extension named after a v1.0 attribute": {
+			in: event.Event{Context: func() *event.EventContextV03 {
+				ec := fullV03()
+				ec.Extensions = map[string]interface{}{"dataschema": "value"}
+				return ec.AsV03()
+			}()},
+			version: event.CloudEventsVersionV1,
+			wantErr: true,
+		},
+		"v0.3 -> v1.0, colliding extensions": {
+			in: event.Event{Context: func() *event.EventContextV03 {
+				ec := fullV03()
+				ec.Extensions = map[string]interface{}{"ext": "a", "EXT": "b"}
+				return ec.AsV03()
+			}()},
+			version: event.CloudEventsVersionV1,
+			wantErr: true,
+		},
+		"v1.0 -> v0.3, dataschema and schemaurl extension": {
+			in: event.Event{Context: func() *event.EventContextV1 {
+				ec := fullV1()
+				ec.Extensions[event.SchemaURLKey] = "other"
+				return ec.AsV1()
+			}()},
+			version: event.CloudEventsVersionV03,
+			wantErr: true,
+		},
+		"unknown version": {
+			in:      event.Event{Context: fullV1().AsV1()},
+			version: "0.2",
+			wantErr: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			got, err := tc.in.ConvertVersion(tc.version)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+
+			_, err = tc.in.ConvertVersionStrict(tc.version)
+			if tc.strictErr {
+				require.ErrorIs(t, err, event.ErrLossyConversion)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConvertVersionRoundTrip(t *testing.T) {
+	for _, in := range []string{
+		`{"specversion":"0.3","id":"1","source":"/s","type":"t","schemaurl":"schemas/order.json","datacontentencoding":"base64","datacontenttype":"application/octet-stream","subject":"sub","time":"2021-01-02T03:04:05Z","ext":1,"data":"aGVsbG8="}`,
+		`{"specversion":"0.3","id":"1","source":"/s","type":"t","schemaurl":"https://example.com/schema","datacontenttype":"application/json","data":{"hello":"world"}}`,
+		`{"specversion":"1.0","id":"1","source":"/s","type":"t","dataschema":"https://example.com/schema","datacontenttype":"application/octet-stream","subject":"sub","time":"2021-01-02T03:04:05Z","ext":true,"data_base64":"aGVsbG8="}`,
+		`{"specversion":"1.0","id":"1","source":"/s","type":"t","datacontenttype":"text/plain","data":"hello"}`,
+	} {
+		var e event.Event
+		require.NoError(t, json.Unmarshal([]byte(in), &e))
+		other := event.CloudEventsVersionV1
+		if e.SpecVersion() == other {
+			other = event.CloudEventsVersionV03
+		}
+
+		converted, err := e.ConvertVersion(other)
+		require.NoError(t, err)
+		require.Equal(t, other, converted.SpecVersion())
+		back, err := converted.ConvertVersion(e.SpecVersion())
+		require.NoError(t, err)
+		require.Equal(t, e, back)
+
+		b, err := json.Marshal(back)
+		require.NoError(t, err)
+		require.JSONEq(t, in, string(b))
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"strconv"
 
 	"github.com/cloudevents/sdk-go/v2/event/datacodec"
+	"github.com/cloudevents/sdk-go/v2/event/datacodec/schema"
 )
 
 // SetData encodes the given payload with the given content type.
@@ -42,6 +43,25 @@ func (e *Event) SetData(contentType string, obj interface{}) error {
 	return nil
 }
 
+// SetValidatedData encodes the given payload like SetData, then validates it
+// against the dataschema of the event with r. If the data does not conform,
+// the previous data and content type are restored and a ValidationError is
+// returned.
+func (e *Event) SetValidatedData(ctx context.Context, r schema.Resolver, contentType string, obj interface{}) error {
+	prevContentType := e.DataContentType()
+	prevData, prevBase64 := e.DataEncoded, e.DataBase64
+
+	err := e.SetData(contentType, obj)
+	if err == nil {
+		err = e.ValidateData(ctx, r)
+	}
+	if err != nil {
+		e.SetDataContentType(prevContentType)
+		e.DataEncoded, e.DataBase64 = prevData, prevBase64
+	}
+	return err
+}
+
 // Deprecated: Delete when we do not have to support Spec v0.3.
 func (e *Event) legacySetData(obj interface{}) error {
 	data, err := datacodec.Encode(context.Background(), e.DataMediaType(), obj)
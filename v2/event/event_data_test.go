@@ -15,6 +15,7 @@ import (
 
 	"github.com/cloudevents/sdk-go/v2/event"
 	"github.com/cloudevents/sdk-go/v2/event/datacodec"
+	"github.com/cloudevents/sdk-go/v2/event/datacodec/schema"
 	"github.com/cloudevents/sdk-go/v2/types"
 )
 
@@ -313,3 +314,58 @@ func mustEncodeWithDataCodec(tb testing.TB, ct string, in interface{}) []byte {
 	require.NoError(tb, err)
 	return data
 }
+
+// oddLength is a toy schema that rejects data of odd length.
+type oddLength struct{}
+
+func (oddLength) Validate(_ context.Context, _ string, data []byte) error {
+	if len(data)%2 == 1 {
+		return &schema.ValidationError{Violations: []schema.Violation{{Message: "odd length"}}}
+	}
+	return nil
+}
+
+func TestEventSetValidatedData(t *testing.T) {
+	ctx := context.Background()
+	r := schema.ResolverFunc(func(_ context.Context, uri string) (schema.Schema, error) {
+		if uri != "https://example.com/even" {
+			return nil, schema.ErrNotFound
+		}
+		return oddLength{}, nil
+	})
+
+	for _, version := range []string{event.CloudEventsVersionV03, event.CloudEventsVersionV1} {
+		t.Run(version, func(t *testing.T) {
+			e := event.New(version)
+			e.SetID("id")
+			e.SetSource("source")
+			e.SetType("type")
+			e.SetDataSchema("https://example.com/even")
+
+			require.NoError(t, e.SetValidatedData(ctx, r, event.TextPlain, "ab"))
+			require.NoError(t, e.ValidateData(ctx, r))
+
+			// Invalid data is not set.
+			err := e.SetValidatedData(ctx, r, event.ApplicationJSON, "abc")
+			var verr event.ValidationError
+			require.ErrorAs(t, err, &verr)
+			var serr *schema.ValidationError
+			require.ErrorAs(t, verr["data"], &serr)
+			require.Equal(t, "https://example.com/even", serr.DataSchema)
+			require.Equal(t, event.TextPlain, e.DataContentType())
+			require.Equal(t, "ab", string(e.Data()))
+
+			// Validation is opt-in.
+			require.NoError(t, e.SetData(event.TextPlain, "abc"))
+			require.NoError(t, e.Validate())
+			require.NoError(t, e.ValidateData(ctx, nil))
+
+			verr = nil
+			require.ErrorAs(t, e.ValidateData(ctx, r), &verr)
+			require.Contains(t, verr, "data")
+
+			e.SetDataSchema("https://example.com/unknown")
+			require.NoError(t, e.ValidateData(ctx, r))
+		})
+	}
+}
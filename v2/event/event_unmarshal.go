@@ -353,10 +353,15 @@ func consumeDataAsBytes(e *Event, isBase64 bool, b []byte) error {
 	if isBase64 {
 		e.DataBase64 = true
 
+		iter := jsoniter.ParseBytes(jsoniter.ConfigFastest, b)
+		base64Encoded := iter.ReadString() // handles escaping
+		if iter.Error != nil {
+			return fmt.Errorf("unexpected data_base64 payload, expected a string: %w", iter.Error)
+		}
+
 		// Allocate payload byte buffer
-		base64Encoded := b[1 : len(b)-1] // remove quotes
 		e.DataEncoded = make([]byte, base64.StdEncoding.DecodedLen(len(base64Encoded)))
-		length, err := base64.StdEncoding.Decode(e.DataEncoded, base64Encoded)
+		length, err := base64.StdEncoding.Decode(e.DataEncoded, []byte(base64Encoded))
 		if err != nil {
 			return err
 		}
@@ -1123,6 +1123,20 @@ func TestUnmarshalWithOrderingError(t *testing.T) {
 			Add("data", base64.StdEncoding.EncodeToString([]byte(`{"hello":"world"}`))).
 			Add("datacontentencoding", "base54").
 			End(),
+		"non-string data_base64": new(orderedJsonObjectBuilder).Start().
+			Add("specversion", "1.0").
+			Add("id", "ABC-123").
+			Add("type", "com.example.test").
+			Add("source", "http://example.com/source").
+			Add("data_base64", 1).
+			End(),
+		"non-string data_base64 before specversion": new(orderedJsonObjectBuilder).Start().
+			Add("data_base64", []int{1}).
+			Add("id", "ABC-123").
+			Add("type", "com.example.test").
+			Add("source", "http://example.com/source").
+			Add("specversion", "1.0").
+			End(),
 	}
 	for n, tc := range testCases {
 		t.Run(n, func(t *testing.T) {
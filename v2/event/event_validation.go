@@ -6,8 +6,11 @@
 package event
 
 import (
+	"context"
 	"fmt"
 	"strings"
+
+	"github.com/cloudevents/sdk-go/v2/event/datacodec/schema"
 )
 
 type ValidationError map[string]error
@@ -48,3 +51,29 @@ func (e Event) Validate() error {
 	}
 	return nil
 }
+
+// ValidateData validates the event data against its dataschema, resolved with
+// r. Violations are reported under the "data" key of a ValidationError. Events
+// without data or dataschema, or whose dataschema is unknown to r, are valid.
+func (e Event) ValidateData(ctx context.Context, r schema.Resolver) error {
+	if e.Context == nil || len(e.DataEncoded) == 0 || r == nil {
+		return nil
+	}
+	dataSchema := e.DataSchema()
+	if dataSchema == "" {
+		return nil
+	}
+
+	data := e.DataEncoded
+	if e.SpecVersion() != CloudEventsVersionV1 {
+		var err error
+		if data, err = e.legacyConvertData(data); err != nil {
+			return ValidationError{"data": err}
+		}
+	}
+
+	if err := schema.Validate(ctx, r, dataSchema, e.DataMediaType(), data); err != nil {
+		return ValidationError{"data": err}
+	}
+	return nil
+}
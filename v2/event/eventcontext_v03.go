@@ -99,6 +99,9 @@ func (ec *EventContextV03) SetExtension(name string, value interface{}) error {
 	if _, ok := specV03Attributes[strings.ToLower(name)]; ok {
 		return fmt.Errorf("bad key %q: CloudEvents spec attribute MUST NOT be overwritten by extension", name)
 	}
+	if strings.EqualFold(name, "data") {
+		return fmt.Errorf("bad key %q: CloudEvents data MUST NOT be overwritten by extension", name)
+	}
 
 	if value == nil {
 		delete(ec.Extensions, name)
@@ -231,6 +231,15 @@ func TestEventContextV03_SetExtension(t *testing.T) {
 			extensionKey: "schemaurl",
 			want:         []string{"bad key"},
 		},
+		"invalid extension uses data": {
+			ctx: event.EventContextV03{
+				ID:     "ABC-123",
+				Type:   "com.example.simple",
+				Source: *source,
+			},
+			extensionKey: "Data",
+			want:         []string{"bad key", "CloudEvents data"},
+		},
 	}
 
 	for n, tc := range testCases {
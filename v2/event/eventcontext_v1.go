@@ -95,6 +95,9 @@ func (ec *EventContextV1) SetExtension(name string, value interface{}) error {
 	if _, ok := specV1Attributes[strings.ToLower(name)]; ok {
 		return fmt.Errorf("bad key %q: CloudEvents spec attribute MUST NOT be overwritten by extension", name)
 	}
+	if strings.EqualFold(name, "data") {
+		return fmt.Errorf("bad key %q: CloudEvents data MUST NOT be overwritten by extension", name)
+	}
 
 	name = strings.ToLower(name)
 	if ec.Extensions == nil {
@@ -235,6 +235,15 @@ func TestEventContextV1_SetExtension(t *testing.T) {
 			extensionKey: "ce-source",
 			want:         []string{"bad key"},
 		},
+		"invalid extension uses data": {
+			ctx: event.EventContextV1{
+				ID:     "ABC-123",
+				Type:   "com.example.simple",
+				Source: *source,
+			},
+			extensionKey: "Data",
+			want:         []string{"bad key", "CloudEvents data"},
+		},
 	}
 
 	for n, tc := range testCases {
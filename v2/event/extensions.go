@@ -15,6 +15,10 @@ const (
 	// DataContentEncodingKey is the key to DeprecatedDataContentEncoding for versions that do not support data content encoding
 	// directly.
 	DataContentEncodingKey = "datacontentencoding"
+
+	// SchemaURLKey is the key to a v0.3 schemaurl that cannot be carried by the dataschema attribute of versions
+	// that require an absolute URI.
+	SchemaURLKey = "schemaurl"
 )
 
 var (
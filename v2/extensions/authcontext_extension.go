@@ -0,0 +1,103 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions
+
+import (
+	"fmt"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+const (
+	AuthTypeExtensionKey   = "authtype"
+	AuthIDExtensionKey     = "authid"
+	AuthClaimsExtensionKey = "authclaims"
+)
+
+// Values of the authtype extension.
+const (
+	AuthTypeAppUser         = "app_user"
+	AuthTypeUser            = "user"
+	AuthTypeServiceAccount  = "service_account"
+	AuthTypeAPIKey          = "api_key"
+	AuthTypeSystem          = "system"
+	AuthTypeUnauthenticated = "unauthenticated"
+	AuthTypeUnknown         = "unknown"
+)
+
+var (
+	// AuthType is the typed accessor for the authtype extension.
+	AuthType = Define[string](AuthTypeExtensionKey).WithDescription("Type of principal that triggered the occurrence.").WithValidator(validateAuthType)
+	// AuthID is the typed accessor for the authid extension.
+	AuthID = Define[string](AuthIDExtensionKey).WithDescription("Unique identifier of the principal that triggered the occurrence.")
+	// AuthClaims is the typed accessor for the authclaims extension.
+	AuthClaims = Define[string](AuthClaimsExtensionKey).WithDescription("JSON string of the claims of the principal that triggered the occurrence.")
+)
+
+func validateAuthType(v string) error {
+	switch v {
+	case AuthTypeAppUser, AuthTypeUser, AuthTypeServiceAccount, AuthTypeAPIKey, AuthTypeSystem, AuthTypeUnauthenticated, AuthTypeUnknown:
+		return nil
+	}
+	return fmt.Errorf("unknown authtype %q", v)
+}
+
+// AuthContextExtension represents the authcontext extension as defined in
+// https://github.com/cloudevents/spec/blob/main/cloudevents/extensions/authcontext.md
+type AuthContextExtension struct {
+	AuthType   string `json:"authtype"`
+	AuthID     string `json:"authid"`
+	AuthClaims string `json:"authclaims"`
+}
+
+// AddAuthContext sets the authcontext extension on the event. AuthID and
+// AuthClaims are only set if not empty.
+func (a AuthContextExtension) AddAuthContext(e event.EventWriter) error {
+	if err := AuthType.Set(e, a.AuthType); err != nil {
+		return err
+	}
+	if a.AuthID != "" {
+		if err := AuthID.Set(e, a.AuthID); err != nil {
+			return err
+		}
+	}
+	if a.AuthClaims != "" {
+		return AuthClaims.Set(e, a.AuthClaims)
+	}
+	return nil
+}
+
+// GetAuthContext retrieves the authcontext extension from an event. It returns
+// false if the event has no valid authtype.
+func GetAuthContext(e event.Event) (AuthContextExtension, bool) {
+	t, ok := AuthType.Get(e)
+	if !ok {
+		return AuthContextExtension{}, false
+	}
+	id, _ := AuthID.Get(e)
+	claims, _ := AuthClaims.Get(e)
+	return AuthContextExtension{AuthType: t, AuthID: id, AuthClaims: claims}, true
+}
+
+func (a *AuthContextExtension) ReadTransformer() binding.TransformerFunc {
+	return binding.Transformers{
+		AuthType.ReadTransformer(&a.AuthType),
+		AuthID.ReadTransformer(&a.AuthID),
+		AuthClaims.ReadTransformer(&a.AuthClaims),
+	}.Transform
+}
+
+func (a *AuthContextExtension) WriteTransformer() binding.TransformerFunc {
+	t := binding.Transformers{AuthType.WriteTransformer(a.AuthType)}
+	if a.AuthID != "" {
+		t = append(t, AuthID.WriteTransformer(a.AuthID))
+	}
+	if a.AuthClaims != "" {
+		t = append(t, AuthClaims.WriteTransformer(a.AuthClaims))
+	}
+	return t.Transform
+}
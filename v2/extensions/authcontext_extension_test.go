@@ -0,0 +1,49 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	bindingtest "github.com/cloudevents/sdk-go/v2/binding/test"
+	"github.com/cloudevents/sdk-go/v2/extensions"
+)
+
+func TestAuthContext(t *testing.T) {
+	e := newRegistryTestEvent()
+	_, ok := extensions.GetAuthContext(e)
+	require.False(t, ok)
+
+	want := extensions.AuthContextExtension{
+		AuthType:   extensions.AuthTypeServiceAccount,
+		AuthID:     "sa-123",
+		AuthClaims: `{"scope":"read"}`,
+	}
+	require.NoError(t, want.AddAuthContext(&e))
+	got, ok := extensions.GetAuthContext(e)
+	require.True(t, ok)
+	require.Equal(t, want, got)
+
+	require.ErrorContains(t, extensions.AuthContextExtension{AuthType: "robot"}.AddAuthContext(&e), `unknown authtype "robot"`)
+}
+
+func TestAuthContextTransformers(t *testing.T) {
+	want := extensions.AuthContextExtension{AuthType: extensions.AuthTypeUser, AuthID: "user-1"}
+	e := newRegistryTestEvent()
+	out, err := binding.ToEvent(context.Background(), bindingtest.MustCreateMockBinaryMessage(e), want.WriteTransformer())
+	require.NoError(t, err)
+	_, ok := out.Extensions()[extensions.AuthClaimsExtensionKey]
+	require.False(t, ok)
+
+	got := extensions.AuthContextExtension{}
+	_, err = binding.ToEvent(context.Background(), bindingtest.MustCreateMockBinaryMessage(*out), got.ReadTransformer())
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
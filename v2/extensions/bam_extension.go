@@ -0,0 +1,82 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions
+
+import (
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+const (
+	BAMTxIDExtensionKey     = "bamtxid"
+	BAMTxStepExtensionKey   = "bamtxstep"
+	BAMTxStatusExtensionKey = "bamtxstatus"
+)
+
+var (
+	// BAMTxID is the typed accessor for the bamtxid extension.
+	BAMTxID = Define[string](BAMTxIDExtensionKey).WithDescription("Identifier of the business transaction the event belongs to.").WithValidator(nonEmpty)
+	// BAMTxStep is the typed accessor for the bamtxstep extension.
+	BAMTxStep = Define[string](BAMTxStepExtensionKey).WithDescription("Step of the business transaction the event reports on.")
+	// BAMTxStatus is the typed accessor for the bamtxstatus extension.
+	BAMTxStatus = Define[string](BAMTxStatusExtensionKey).WithDescription("Status of the business transaction step.")
+)
+
+// BAMExtension represents the business activity monitoring (bam) extension as
+// defined in https://github.com/cloudevents/spec/blob/main/cloudevents/extensions/bam.md
+type BAMExtension struct {
+	TxID     string `json:"bamtxid"`
+	TxStep   string `json:"bamtxstep"`
+	TxStatus string `json:"bamtxstatus"`
+}
+
+// AddBAM sets the bam extension on the event. TxStep and TxStatus are only set
+// if not empty.
+func (b BAMExtension) AddBAM(e event.EventWriter) error {
+	if err := BAMTxID.Set(e, b.TxID); err != nil {
+		return err
+	}
+	if b.TxStep != "" {
+		if err := BAMTxStep.Set(e, b.TxStep); err != nil {
+			return err
+		}
+	}
+	if b.TxStatus != "" {
+		return BAMTxStatus.Set(e, b.TxStatus)
+	}
+	return nil
+}
+
+// GetBAM retrieves the bam extension from an event. It returns false if the
+// event has no bamtxid.
+func GetBAM(e event.Event) (BAMExtension, bool) {
+	id, ok := BAMTxID.Get(e)
+	if !ok {
+		return BAMExtension{}, false
+	}
+	step, _ := BAMTxStep.Get(e)
+	status, _ := BAMTxStatus.Get(e)
+	return BAMExtension{TxID: id, TxStep: step, TxStatus: status}, true
+}
+
+func (b *BAMExtension) ReadTransformer() binding.TransformerFunc {
+	return binding.Transformers{
+		BAMTxID.ReadTransformer(&b.TxID),
+		BAMTxStep.ReadTransformer(&b.TxStep),
+		BAMTxStatus.ReadTransformer(&b.TxStatus),
+	}.Transform
+}
+
+func (b *BAMExtension) WriteTransformer() binding.TransformerFunc {
+	t := binding.Transformers{BAMTxID.WriteTransformer(b.TxID)}
+	if b.TxStep != "" {
+		t = append(t, BAMTxStep.WriteTransformer(b.TxStep))
+	}
+	if b.TxStatus != "" {
+		t = append(t, BAMTxStatus.WriteTransformer(b.TxStatus))
+	}
+	return t.Transform
+}
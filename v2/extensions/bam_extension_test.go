@@ -0,0 +1,43 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	bindingtest "github.com/cloudevents/sdk-go/v2/binding/test"
+	"github.com/cloudevents/sdk-go/v2/extensions"
+)
+
+func TestBAM(t *testing.T) {
+	e := newRegistryTestEvent()
+	_, ok := extensions.GetBAM(e)
+	require.False(t, ok)
+
+	want := extensions.BAMExtension{TxID: "tx-1", TxStep: "payment", TxStatus: "completed"}
+	require.NoError(t, want.AddBAM(&e))
+	got, ok := extensions.GetBAM(e)
+	require.True(t, ok)
+	require.Equal(t, want, got)
+
+	require.Error(t, extensions.BAMExtension{}.AddBAM(&e))
+}
+
+func TestBAMTransformers(t *testing.T) {
+	want := extensions.BAMExtension{TxID: "tx-2"}
+	e := newRegistryTestEvent()
+	out, err := binding.ToEvent(context.Background(), bindingtest.MustCreateMockBinaryMessage(e), want.WriteTransformer())
+	require.NoError(t, err)
+
+	got := extensions.BAMExtension{}
+	_, err = binding.ToEvent(context.Background(), bindingtest.MustCreateMockBinaryMessage(*out), got.ReadTransformer())
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
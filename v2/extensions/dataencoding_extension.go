@@ -0,0 +1,17 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions
+
+const DataEncodingExtensionKey = "dataencoding"
+
+// DataEncoding is the typed accessor for the dataencoding extension. It names
+// the content coding, as registered for the HTTP Content-Encoding header, e.g.
+// "gzip", applied to the data of the event: the data must be decoded before
+// being interpreted as described by the datacontenttype. See the
+// binding/compression package.
+var DataEncoding = Define[string](DataEncodingExtensionKey).
+	WithDescription("Content coding, e.g. gzip, applied to the data of the event, to be decoded before the datacontenttype applies.").
+	WithValidator(nonEmpty)
@@ -6,12 +6,19 @@
 package extensions
 
 import (
-	"github.com/cloudevents/sdk-go/v2/event"
 	"net/url"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/types"
 )
 
 const DataRefExtensionKey = "dataref"
 
+// DataRef is the typed accessor for the dataref extension. Validate checks
+// that the dataref of an event is a URI-reference.
+var DataRef = Define[types.URIRef](DataRefExtensionKey).
+	WithDescription("Reference to a location where the event payload is stored.")
+
 // DataRefExtension represents the CloudEvents Dataref (claim check pattern)
 // extension for cloudevents contexts,
 // See https://github.com/cloudevents/spec/blob/main/cloudevents/extensions/dataref.md
@@ -49,6 +49,14 @@ func TestAddDataRefExtension(t *testing.T) {
 				t.Fatalf("Retrieved dataref(%v) doesn't match set value(%s)",
 					dr, test.dataref)
 			}
+			if v, ok := e.Extensions()[DataRefExtensionKey].(string); !ok || v != test.dataref {
+				t.Fatalf("Expected dataref to be stored as the string %q, got %#v",
+					test.dataref, e.Extensions()[DataRefExtensionKey])
+			}
+			if err := Validate(e); err != nil {
+				t.Fatalf("Unexpected validation error for dataref (%s): %s",
+					test.dataref, err)
+			}
 		} else {
 			if ok || dr.DataRef != "" {
 				t.Fatalf("Expected not to find DataRefExtension, but did(%s)",
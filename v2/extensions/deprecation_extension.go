@@ -0,0 +1,109 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions
+
+import (
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+const (
+	DeprecatedExtensionKey           = "deprecated"
+	DeprecationFromExtensionKey      = "deprecationfrom"
+	DeprecationSunsetExtensionKey    = "deprecationsunset"
+	DeprecationMigrationExtensionKey = "deprecationmigration"
+)
+
+var (
+	// Deprecated is the typed accessor for the deprecated extension.
+	Deprecated = Define[bool](DeprecatedExtensionKey).WithDescription("Whether the event type is deprecated.")
+	// DeprecationFrom is the typed accessor for the deprecationfrom extension.
+	DeprecationFrom = Define[time.Time](DeprecationFromExtensionKey).WithDescription("Timestamp from which the event type is deprecated.")
+	// DeprecationSunset is the typed accessor for the deprecationsunset extension.
+	DeprecationSunset = Define[time.Time](DeprecationSunsetExtensionKey).WithDescription("Timestamp after which the event type will no longer be produced.")
+	// DeprecationMigration is the typed accessor for the deprecationmigration extension.
+	DeprecationMigration = Define[types.URI](DeprecationMigrationExtensionKey).WithDescription("URI of the documentation on how to migrate away from the event type.")
+)
+
+// DeprecationExtension represents the deprecation extension as defined in
+// https://github.com/cloudevents/spec/blob/main/cloudevents/extensions/deprecation.md
+type DeprecationExtension struct {
+	From      time.Time  `json:"deprecationfrom"`
+	Sunset    time.Time  `json:"deprecationsunset"`
+	Migration *types.URI `json:"deprecationmigration"`
+}
+
+// AddDeprecation marks the event as deprecated. The optional attributes are
+// only set if not zero.
+func (d DeprecationExtension) AddDeprecation(e event.EventWriter) error {
+	if err := Deprecated.Set(e, true); err != nil {
+		return err
+	}
+	if !d.From.IsZero() {
+		if err := DeprecationFrom.Set(e, d.From); err != nil {
+			return err
+		}
+	}
+	if !d.Sunset.IsZero() {
+		if err := DeprecationSunset.Set(e, d.Sunset); err != nil {
+			return err
+		}
+	}
+	if d.Migration != nil {
+		return DeprecationMigration.Set(e, *d.Migration)
+	}
+	return nil
+}
+
+// GetDeprecation retrieves the deprecation extension from an event. It returns
+// false if the event is not marked as deprecated.
+func GetDeprecation(e event.Event) (DeprecationExtension, bool) {
+	if deprecated, ok := Deprecated.Get(e); !ok || !deprecated {
+		return DeprecationExtension{}, false
+	}
+	d := DeprecationExtension{}
+	d.From, _ = DeprecationFrom.Get(e)
+	d.Sunset, _ = DeprecationSunset.Get(e)
+	if m, ok := DeprecationMigration.Get(e); ok {
+		d.Migration = &m
+	}
+	return d, true
+}
+
+func (d *DeprecationExtension) ReadTransformer() binding.TransformerFunc {
+	return func(reader binding.MessageMetadataReader, writer binding.MessageMetadataWriter) error {
+		var migration types.URI
+		err := binding.Transformers{
+			DeprecationFrom.ReadTransformer(&d.From),
+			DeprecationSunset.ReadTransformer(&d.Sunset),
+			DeprecationMigration.ReadTransformer(&migration),
+		}.Transform(reader, writer)
+		if err != nil {
+			return err
+		}
+		if migration.String() != "" {
+			d.Migration = &migration
+		}
+		return nil
+	}
+}
+
+func (d *DeprecationExtension) WriteTransformer() binding.TransformerFunc {
+	t := binding.Transformers{Deprecated.WriteTransformer(true)}
+	if !d.From.IsZero() {
+		t = append(t, DeprecationFrom.WriteTransformer(d.From))
+	}
+	if !d.Sunset.IsZero() {
+		t = append(t, DeprecationSunset.WriteTransformer(d.Sunset))
+	}
+	if d.Migration != nil {
+		t = append(t, DeprecationMigration.WriteTransformer(*d.Migration))
+	}
+	return t.Transform
+}
@@ -0,0 +1,63 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	bindingtest "github.com/cloudevents/sdk-go/v2/binding/test"
+	"github.com/cloudevents/sdk-go/v2/extensions"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+var (
+	testTimeValue = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	testTimestamp = types.Timestamp{Time: testTimeValue}
+)
+
+func TestDeprecation(t *testing.T) {
+	e := newRegistryTestEvent()
+	_, ok := extensions.GetDeprecation(e)
+	require.False(t, ok)
+
+	migration, _ := url.Parse("https://example.com/migrate")
+	want := extensions.DeprecationExtension{
+		From:      testTimeValue,
+		Sunset:    testTimeValue.AddDate(1, 0, 0),
+		Migration: &types.URI{URL: *migration},
+	}
+	require.NoError(t, want.AddDeprecation(&e))
+	deprecated, ok := extensions.Deprecated.Get(e)
+	require.True(t, ok)
+	require.True(t, deprecated)
+
+	got, ok := extensions.GetDeprecation(e)
+	require.True(t, ok)
+	require.Equal(t, want, got)
+
+	require.NoError(t, extensions.Deprecated.Set(&e, false))
+	_, ok = extensions.GetDeprecation(e)
+	require.False(t, ok)
+}
+
+func TestDeprecationTransformers(t *testing.T) {
+	want := extensions.DeprecationExtension{Sunset: testTimeValue}
+	e := newRegistryTestEvent()
+	out, err := binding.ToEvent(context.Background(), bindingtest.MustCreateMockBinaryMessage(e), want.WriteTransformer())
+	require.NoError(t, err)
+	require.Equal(t, true, out.Extensions()[extensions.DeprecatedExtensionKey])
+
+	got := extensions.DeprecationExtension{}
+	_, err = binding.ToEvent(context.Background(), bindingtest.MustCreateMockBinaryMessage(*out), got.ReadTransformer())
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
@@ -6,12 +6,11 @@
 package extensions
 
 import (
-	"reflect"
+	"errors"
 	"strings"
 
 	"github.com/cloudevents/sdk-go/v2/binding"
 	"github.com/cloudevents/sdk-go/v2/event"
-
 	"github.com/cloudevents/sdk-go/v2/types"
 )
 
@@ -20,29 +19,76 @@ const (
 	TraceStateExtension  = "tracestate"
 )
 
+var (
+	// TraceParent is the typed accessor for the traceparent extension. Unlike
+	// DistributedTracingExtension, it only accepts valid W3C traceparents.
+	TraceParent = Define[string](TraceParentExtension).WithDescription("W3C Trace Context traceparent of the event.").WithValidator(validateTraceParent)
+	// TraceState is the typed accessor for the tracestate extension.
+	TraceState = Define[string](TraceStateExtension).WithDescription("W3C Trace Context tracestate of the event.")
+)
+
+// validateTraceParent checks v against the traceparent format of
+// https://www.w3.org/TR/trace-context/#traceparent-header
+func validateTraceParent(v string) error {
+	// version "-" trace-id "-" parent-id "-" trace-flags
+	if len(v) < 55 {
+		return errors.New("traceparent is too short")
+	}
+	version, rest := v[:2], v[55:]
+	if !isLowerHex(version) || version == "ff" {
+		return errors.New("traceparent has an invalid version")
+	}
+	if version == "00" && rest != "" {
+		return errors.New("traceparent is too long for version 00")
+	}
+	if rest != "" && rest[0] != '-' {
+		return errors.New("traceparent has an invalid format")
+	}
+	if v[2] != '-' || v[35] != '-' || v[52] != '-' {
+		return errors.New("traceparent has an invalid format")
+	}
+	if traceID := v[3:35]; !isLowerHex(traceID) || strings.Trim(traceID, "0") == "" {
+		return errors.New("traceparent has an invalid trace-id")
+	}
+	if parentID := v[36:52]; !isLowerHex(parentID) || strings.Trim(parentID, "0") == "" {
+		return errors.New("traceparent has an invalid parent-id")
+	}
+	if !isLowerHex(v[53:55]) {
+		return errors.New("traceparent has invalid trace-flags")
+	}
+	return nil
+}
+
+func isLowerHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
 // DistributedTracingExtension represents the extension for cloudevents context
 type DistributedTracingExtension struct {
 	TraceParent string `json:"traceparent"`
 	TraceState  string `json:"tracestate"`
 }
 
-// AddTracingAttributes adds the tracing attributes traceparent and tracestate to the cloudevents context
+// AddTracingAttributes adds the tracing attributes traceparent and tracestate to the cloudevents context.
+// Nothing is added if TraceParent is empty. The traceparent is not validated, use Validate for that.
 func (d DistributedTracingExtension) AddTracingAttributes(e event.EventWriter) {
-	if d.TraceParent != "" {
-		value := reflect.ValueOf(d)
-		typeOf := value.Type()
-
-		for i := 0; i < value.NumField(); i++ {
-			k := strings.ToLower(typeOf.Field(i).Name)
-			v := value.Field(i).Interface()
-			if k == TraceStateExtension && v == "" {
-				continue
-			}
-			e.SetExtension(k, v)
-		}
+	if d.TraceParent == "" {
+		return
+	}
+	e.SetExtension(TraceParentExtension, d.TraceParent)
+	if d.TraceState != "" {
+		e.SetExtension(TraceStateExtension, d.TraceState)
 	}
 }
 
+// GetDistributedTracingExtension returns the tracing attributes of the event and
+// true, or false if the event has no traceparent. The traceparent is returned
+// as-is, even if it is not a valid W3C traceparent.
 func GetDistributedTracingExtension(event event.Event) (DistributedTracingExtension, bool) {
 	if tp, ok := event.Extensions()[TraceParentExtension]; ok {
 		if tpStr, err := types.ToString(tp); err == nil {
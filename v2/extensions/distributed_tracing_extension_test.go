@@ -297,3 +297,57 @@ func TestDistributedTracingExtension_WriteTransformer(t *testing.T) {
 		},
 	})
 }
+
+func TestTraceParentValidation(t *testing.T) {
+	tests := map[string]bool{
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01":        true,
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future": true,
+		"": false,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7":          false,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra": false,
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01":       false,
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01":       false,
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01":       false,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01":       false,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-zz":       false,
+		"00_4bf92f3577b34da6a3ce929d0e0e4736_00f067aa0ba902b7_01":       false,
+	}
+	for tp, valid := range tests {
+		t.Run(tp, func(t *testing.T) {
+			e := event.New()
+			ext := extensions.DistributedTracingExtension{TraceParent: tp, TraceState: "rojo=1"}
+			ext.AddTracingAttributes(&e)
+
+			// Invalid traceparents are kept as-is, and only reported by Validate.
+			got, ok := extensions.GetDistributedTracingExtension(e)
+			require.Equal(t, tp != "", ok)
+			if tp == "" {
+				require.Empty(t, e.Extensions())
+				return
+			}
+			require.Equal(t, ext, got)
+			_, ok = extensions.TraceParent.Get(e)
+			require.Equal(t, valid, ok)
+			if valid {
+				require.NoError(t, extensions.Validate(e))
+			} else {
+				require.Error(t, extensions.Validate(e))
+				require.Error(t, extensions.TraceParent.ValidateValue(tp))
+			}
+		})
+	}
+}
+
+func TestDistributedTracingExtension_ReadTransformer_invalid(t *testing.T) {
+	e := test.MinEvent()
+	e.SetExtension(extensions.TraceParentExtension, "legacy-trace")
+
+	ext := extensions.DistributedTracingExtension{}
+	bindingtest.RunTransformerTests(t, context.TODO(), []bindingtest.TransformerTestArgs{{
+		Name:         "Read from Mock Binary message",
+		InputMessage: bindingtest.MustCreateMockBinaryMessage(e),
+		WantEvent:    e,
+		Transformers: binding.Transformers{ext.ReadTransformer()},
+	}})
+	require.Equal(t, "legacy-trace", ext.TraceParent)
+}
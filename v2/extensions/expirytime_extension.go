@@ -17,6 +17,10 @@ const (
 	ExpiryTimeExtensionKey = "expirytime"
 )
 
+// ExpiryTime is the typed accessor for the expirytime extension.
+var ExpiryTime = Define[time.Time](ExpiryTimeExtensionKey).
+	WithDescription("Timestamp after which the event is no longer relevant and can be discarded.")
+
 // ExpiryTimeExtension represents the expirytime extension as defined in
 // https://github.com/cloudevents/spec/blob/main/cloudevents/extensions/expirytime.md
 type ExpiryTimeExtension struct {
@@ -32,10 +36,8 @@ func (e ExpiryTimeExtension) AddExpiryTime(ev event.EventWriter) {
 
 // GetExpiryTime retrieves the expirytime extension from an event.
 func GetExpiryTime(ev event.Event) (ExpiryTimeExtension, bool) {
-	if ext, ok := ev.Extensions()[ExpiryTimeExtensionKey]; ok {
-		if t, err := types.ToTime(ext); err == nil {
-			return ExpiryTimeExtension{ExpiryTime: t}, true
-		}
+	if t, ok := ExpiryTime.Get(ev); ok {
+		return ExpiryTimeExtension{ExpiryTime: t}, true
 	}
 	return ExpiryTimeExtension{}, false
 }
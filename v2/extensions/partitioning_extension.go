@@ -0,0 +1,51 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions
+
+import (
+	"errors"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+const PartitionKeyExtensionKey = "partitionkey"
+
+// PartitionKey is the typed accessor for the partitionkey extension, as
+// defined in https://github.com/cloudevents/spec/blob/main/cloudevents/extensions/partitioning.md
+//
+// Protocols with a notion of message key, such as Kafka, use it to set the key
+// of outgoing messages.
+var PartitionKey = Define[string](PartitionKeyExtensionKey).
+	WithDescription("Partition key for the event, typically used to guarantee ordering of related events.").
+	WithValidator(nonEmpty)
+
+// PartitionKeyTransformer returns a transformer that reads the partitionkey
+// extension of a message into dst. Unlike PartitionKey.ReadTransformer, values
+// which are not strings, e.g. an Integer, are accepted and formatted with
+// types.Format. dst is left unchanged if the message does not have the
+// extension.
+func PartitionKeyTransformer(dst *string) binding.TransformerFunc {
+	return func(reader binding.MessageMetadataReader, writer binding.MessageMetadataWriter) error {
+		ext := reader.GetExtension(PartitionKeyExtensionKey)
+		if types.IsZero(ext) {
+			return nil
+		}
+		s, err := types.Format(ext)
+		if err != nil {
+			return err
+		}
+		*dst = s
+		return nil
+	}
+}
+
+func nonEmpty(s string) error {
+	if s == "" {
+		return errors.New("MUST be a non-empty string")
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	bindingtest "github.com/cloudevents/sdk-go/v2/binding/test"
+	"github.com/cloudevents/sdk-go/v2/extensions"
+)
+
+func TestPartitionKey(t *testing.T) {
+	e := newRegistryTestEvent()
+
+	require.NoError(t, extensions.PartitionKey.Set(&e, "key"))
+	require.Error(t, extensions.PartitionKey.Set(&e, ""))
+
+	require.Equal(t, map[string]interface{}{"partitionkey": "key"}, e.Extensions())
+	require.NoError(t, extensions.Validate(e))
+}
+
+func TestPartitionKeyTransformer(t *testing.T) {
+	for name, tc := range map[string]struct {
+		ext  interface{}
+		want string
+	}{
+		"string":       {ext: "key", want: "key"},
+		"integer":      {ext: int32(42), want: "42"},
+		"empty string": {ext: "", want: "unchanged"},
+		"missing":      {want: "unchanged"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			e := newRegistryTestEvent()
+			if tc.ext != nil {
+				e.SetExtension(extensions.PartitionKeyExtensionKey, tc.ext)
+			}
+			key := "unchanged"
+			_, err := binding.ToEvent(context.Background(), bindingtest.MustCreateMockBinaryMessage(e), extensions.PartitionKeyTransformer(&key))
+			require.NoError(t, err)
+			require.Equal(t, tc.want, key)
+		})
+	}
+}
@@ -0,0 +1,15 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions
+
+import "time"
+
+const RecordedTimeExtensionKey = "recordedtime"
+
+// RecordedTime is the typed accessor for the recordedtime extension, as
+// defined in https://github.com/cloudevents/spec/blob/main/cloudevents/extensions/recordedtime.md
+var RecordedTime = Define[time.Time](RecordedTimeExtensionKey).
+	WithDescription("Timestamp of when the event was recorded, as opposed to when the occurrence happened.")
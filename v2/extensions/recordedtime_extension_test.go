@@ -0,0 +1,26 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/extensions"
+)
+
+func TestRecordedTime(t *testing.T) {
+	e := newRegistryTestEvent()
+
+	require.NoError(t, extensions.RecordedTime.Set(&e, testTimeValue))
+
+	require.Equal(t, map[string]interface{}{"recordedtime": testTimestamp}, e.Extensions())
+	require.NoError(t, extensions.Validate(e))
+	got, ok := extensions.RecordedTime.Get(e)
+	require.True(t, ok)
+	require.True(t, testTimeValue.Equal(got))
+}
@@ -0,0 +1,283 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// Value is the set of Go types a typed extension can hold, one for each type
+// of the CloudEvents type system.
+type Value interface {
+	bool | int32 | string | []byte | types.URI | types.URIRef | time.Time
+}
+
+// Descriptor describes a registered extension, independently of its type.
+type Descriptor interface {
+	// Name of the extension attribute.
+	Name() string
+	// Type is the CloudEvents type of the extension, e.g. "Integer".
+	Type() string
+	// Description is a human-readable description of the extension.
+	Description() string
+	// ValidateValue returns an error if v, as found in an event, is not a
+	// valid value for the extension.
+	ValidateValue(v interface{}) error
+}
+
+// Extension is a typed accessor for the extension attribute Name().
+// Use Define to create one.
+type Extension[T Value] struct {
+	name        string
+	description string
+	validator   func(T) error
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Descriptor{}
+)
+
+// Define registers an extension named name, holding values of type T, and
+// returns its typed accessor. Define is meant to be called when initializing
+// package variables, and panics if name is not a valid extension name or is
+// already registered.
+func Define[T Value](name string) *Extension[T] {
+	if !event.IsExtensionNameValid(name) {
+		panic(fmt.Errorf("invalid extension name %q", name))
+	}
+	x := &Extension[T]{name: name}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[name]; ok {
+		panic(fmt.Errorf("extension %q is already registered", name))
+	}
+	registry[name] = x
+	return x
+}
+
+// Lookup returns the registered extension named name, if any.
+func Lookup(name string) (Descriptor, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	d, ok := registry[name]
+	return d, ok
+}
+
+// Registered returns all registered extensions, sorted by name.
+func Registered() []Descriptor {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	out := make([]Descriptor, 0, len(registry))
+	for _, d := range registry {
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out
+}
+
+// Validate checks every registered extension set on the event. It returns an
+// event.ValidationError keyed by extension name, or nil.
+func Validate(e event.Event) error {
+	errs := event.ValidationError{}
+	for name, v := range e.Extensions() {
+		if d, ok := Lookup(name); ok {
+			if err := d.ValidateValue(v); err != nil {
+				errs[name] = err
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// WithDescription sets the description of the extension and returns it.
+func (x *Extension[T]) WithDescription(description string) *Extension[T] {
+	x.description = description
+	return x
+}
+
+// WithValidator sets a function to validate values of the extension, on top
+// of the type conversion, and returns it.
+func (x *Extension[T]) WithValidator(fn func(T) error) *Extension[T] {
+	x.validator = fn
+	return x
+}
+
+// Name implements Descriptor.Name
+func (x *Extension[T]) Name() string { return x.name }
+
+// Description implements Descriptor.Description
+func (x *Extension[T]) Description() string { return x.description }
+
+// Type implements Descriptor.Type
+func (x *Extension[T]) Type() string {
+	var zero T
+	switch any(zero).(type) {
+	case bool:
+		return "Boolean"
+	case int32:
+		return "Integer"
+	case string:
+		return "String"
+	case []byte:
+		return "Binary"
+	case types.URI:
+		return "URI"
+	case types.URIRef:
+		return "URI-reference"
+	default:
+		return "Timestamp"
+	}
+}
+
+// ValidateValue implements Descriptor.ValidateValue
+func (x *Extension[T]) ValidateValue(v interface{}) error {
+	_, err := x.convert(v)
+	return err
+}
+
+// Get returns the value of the extension and true, or false if the event
+// does not have the extension or its value cannot be converted to T.
+func (x *Extension[T]) Get(e event.Event) (T, bool) {
+	var zero T
+	v, ok := e.Extensions()[x.name]
+	if !ok {
+		return zero, false
+	}
+	t, err := x.convert(v)
+	if err != nil {
+		return zero, false
+	}
+	return t, true
+}
+
+// Set validates v and sets it as the value of the extension.
+func (x *Extension[T]) Set(e event.EventWriter, v T) error {
+	if err := x.validate(v); err != nil {
+		return err
+	}
+	vv, err := types.Validate(v)
+	if err != nil {
+		return err
+	}
+	e.SetExtension(x.name, vv)
+	return nil
+}
+
+// Delete removes the extension from the event.
+func (x *Extension[T]) Delete(e event.EventWriter) {
+	e.SetExtension(x.name, nil)
+}
+
+// ReadTransformer returns a transformer that reads the extension from a
+// message into dst. dst is left unchanged if the message does not have the
+// extension.
+func (x *Extension[T]) ReadTransformer(dst *T) binding.TransformerFunc {
+	return func(reader binding.MessageMetadataReader, writer binding.MessageMetadataWriter) error {
+		v := reader.GetExtension(x.name)
+		if v == nil {
+			return nil
+		}
+		if s, ok := v.(string); ok && s == "" {
+			return nil
+		}
+		t, err := x.convert(v)
+		if err != nil {
+			return err
+		}
+		*dst = t
+		return nil
+	}
+}
+
+// WriteTransformer returns a transformer that validates v and sets it as the
+// value of the extension on a message.
+func (x *Extension[T]) WriteTransformer(v T) binding.TransformerFunc {
+	return func(reader binding.MessageMetadataReader, writer binding.MessageMetadataWriter) error {
+		if err := x.validate(v); err != nil {
+			return err
+		}
+		vv, err := types.Validate(v)
+		if err != nil {
+			return err
+		}
+		return writer.SetExtension(x.name, vv)
+	}
+}
+
+func (x *Extension[T]) validate(v T) error {
+	if x.validator != nil {
+		if err := x.validator(v); err != nil {
+			return fmt.Errorf("invalid value for extension %q: %w", x.name, err)
+		}
+	}
+	return nil
+}
+
+// convert converts an extension value, as found in an event or a message, to
+// T and validates it.
+func (x *Extension[T]) convert(v interface{}) (T, error) {
+	var zero T
+	var out interface{}
+	var err error
+	switch any(zero).(type) {
+	case bool:
+		out, err = types.ToBool(v)
+	case int32:
+		out, err = types.ToInteger(v)
+	case string:
+		out, err = types.ToString(v)
+	case []byte:
+		out, err = types.ToBinary(v)
+	case types.URI:
+		var u *types.URI
+		if u, err = toURI(v); err == nil {
+			out = *u
+		}
+	case types.URIRef:
+		var u *types.URIRef
+		if u, err = toURIRef(v); err == nil {
+			out = *u
+		}
+	case time.Time:
+		out, err = types.ToTime(v)
+	}
+	if err != nil {
+		return zero, fmt.Errorf("invalid value for extension %q: %w", x.name, err)
+	}
+	t := out.(T)
+	return t, x.validate(t)
+}
+
+func toURI(v interface{}) (*types.URI, error) {
+	u, err := types.ToURL(v)
+	if err != nil {
+		return nil, err
+	}
+	if !u.IsAbs() {
+		return nil, fmt.Errorf("%q is not an absolute URI", u.String())
+	}
+	return &types.URI{URL: *u}, nil
+}
+
+func toURIRef(v interface{}) (*types.URIRef, error) {
+	u, err := types.ToURL(v)
+	if err != nil {
+		return nil, err
+	}
+	return &types.URIRef{URL: *u}, nil
+}
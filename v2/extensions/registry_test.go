@@ -0,0 +1,139 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	bindingtest "github.com/cloudevents/sdk-go/v2/binding/test"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/extensions"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+var (
+	testPositive = extensions.Define[int32]("testpositive").WithDescription("A positive integer.").WithValidator(positive)
+	testURI      = extensions.Define[types.URI]("testuri")
+	testTime     = extensions.Define[time.Time]("testtime")
+)
+
+func positive(v int32) error {
+	if v <= 0 {
+		return errors.New("must be positive")
+	}
+	return nil
+}
+
+func newRegistryTestEvent() event.Event {
+	e := event.New()
+	e.SetSource("http://example.com/source")
+	e.SetType("com.example.test")
+	e.SetID("ABC-123")
+	return e
+}
+
+func TestExtensionGetSet(t *testing.T) {
+	e := newRegistryTestEvent()
+
+	_, ok := testPositive.Get(e)
+	require.False(t, ok)
+
+	require.NoError(t, testPositive.Set(&e, 42))
+	v, ok := testPositive.Get(e)
+	require.True(t, ok)
+	require.Equal(t, int32(42), v)
+
+	require.ErrorContains(t, testPositive.Set(&e, -1), "must be positive")
+	v, _ = testPositive.Get(e)
+	require.Equal(t, int32(42), v)
+
+	testPositive.Delete(&e)
+	_, ok = testPositive.Get(e)
+	require.False(t, ok)
+
+	// Values set as strings, e.g. by a binary binding, are converted.
+	e.SetExtension("testpositive", "7")
+	v, ok = testPositive.Get(e)
+	require.True(t, ok)
+	require.Equal(t, int32(7), v)
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, testTime.Set(&e, now))
+	got, ok := testTime.Get(e)
+	require.True(t, ok)
+	require.True(t, now.Equal(got))
+
+	e.SetExtension("testuri", "relative/path")
+	_, ok = testURI.Get(e)
+	require.False(t, ok)
+}
+
+func TestExtensionTransformers(t *testing.T) {
+	e := newRegistryTestEvent()
+	u, _ := url.Parse("https://example.com/a")
+	msg := bindingtest.MustCreateMockBinaryMessage(e)
+
+	out, err := binding.ToEvent(context.Background(), msg, testURI.WriteTransformer(types.URI{URL: *u}))
+	require.NoError(t, err)
+	v, ok := testURI.Get(*out)
+	require.True(t, ok)
+	require.Equal(t, u.String(), v.String())
+
+	var read types.URI
+	_, err = binding.ToEvent(context.Background(), bindingtest.MustCreateMockBinaryMessage(*out), testURI.ReadTransformer(&read))
+	require.NoError(t, err)
+	require.Equal(t, u.String(), read.String())
+
+	_, err = binding.ToEvent(context.Background(), msg, testPositive.WriteTransformer(0))
+	require.ErrorContains(t, err, "must be positive")
+}
+
+func TestRegistry(t *testing.T) {
+	d, ok := extensions.Lookup("testpositive")
+	require.True(t, ok)
+	require.Equal(t, "testpositive", d.Name())
+	require.Equal(t, "Integer", d.Type())
+	require.Equal(t, "A positive integer.", d.Description())
+
+	d, ok = extensions.Lookup(extensions.ExpiryTimeExtensionKey)
+	require.True(t, ok)
+	require.Equal(t, "Timestamp", d.Type())
+
+	_, ok = extensions.Lookup("unknown")
+	require.False(t, ok)
+
+	names := []string{}
+	for _, d := range extensions.Registered() {
+		names = append(names, d.Name())
+	}
+	require.IsIncreasing(t, names)
+	require.Subset(t, names, []string{"dataref", "expirytime", "testpositive", "testtime", "testuri", "traceparent", "tracestate"})
+
+	require.Panics(t, func() { extensions.Define[string]("testpositive") })
+	require.Panics(t, func() { extensions.Define[string]("not-valid") })
+}
+
+func TestValidate(t *testing.T) {
+	e := newRegistryTestEvent()
+	e.SetExtension("unregistered", "anything")
+	require.NoError(t, extensions.Validate(e))
+
+	e.SetExtension("testpositive", int32(-3))
+	e.SetExtension("testuri", "relative/path")
+	err := extensions.Validate(e)
+	var verr event.ValidationError
+	require.ErrorAs(t, err, &verr)
+	require.Len(t, verr, 2)
+	require.Contains(t, verr, "testpositive")
+	require.Contains(t, verr, "testuri")
+}
@@ -0,0 +1,21 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions
+
+import "errors"
+
+const SampledRateExtensionKey = "sampledrate"
+
+// SampledRate is the typed accessor for the sampledrate extension, as defined
+// in https://github.com/cloudevents/spec/blob/main/cloudevents/extensions/sampled-rate.md
+var SampledRate = Define[int32](SampledRateExtensionKey).
+	WithDescription("Rate at which the event has been sampled, e.g. 10 when one in ten events is sent.").
+	WithValidator(func(v int32) error {
+		if v <= 0 {
+			return errors.New("MUST be an integer greater than 0")
+		}
+		return nil
+	})
@@ -0,0 +1,25 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/extensions"
+)
+
+func TestSampledRate(t *testing.T) {
+	e := newRegistryTestEvent()
+
+	require.NoError(t, extensions.SampledRate.Set(&e, 10))
+	require.Error(t, extensions.SampledRate.Set(&e, 0))
+	require.Error(t, extensions.SampledRate.Set(&e, -1))
+
+	require.Equal(t, map[string]interface{}{"sampledrate": int32(10)}, e.Extensions())
+	require.NoError(t, extensions.Validate(e))
+}
@@ -0,0 +1,37 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions
+
+import (
+	"strings"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+const SequenceExtensionKey = "sequence"
+
+// Sequence is the typed accessor for the sequence extension, as defined in
+// https://github.com/cloudevents/spec/blob/main/cloudevents/extensions/sequence.md
+var Sequence = Define[string](SequenceExtensionKey).
+	WithDescription("Relative order of the event, as a lexicographically-orderable string.").
+	WithValidator(nonEmpty)
+
+// CompareSequence compares the sequence of two events lexicographically, as
+// required by the spec. The result is 0 if a == b, -1 if a < b, and +1 if
+// a > b. The boolean is false if either event has no valid sequence.
+//
+// Producers are expected to pad numeric sequences, e.g. "0009" < "0010".
+func CompareSequence(a, b event.Event) (int, bool) {
+	sa, ok := Sequence.Get(a)
+	if !ok {
+		return 0, false
+	}
+	sb, ok := Sequence.Get(b)
+	if !ok {
+		return 0, false
+	}
+	return strings.Compare(sa, sb), true
+}
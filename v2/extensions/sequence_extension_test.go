@@ -0,0 +1,47 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/extensions"
+)
+
+func TestCompareSequence(t *testing.T) {
+	withSequence := func(s string) event.Event {
+		e := newRegistryTestEvent()
+		if s != "" {
+			require.NoError(t, extensions.Sequence.Set(&e, s))
+		}
+		return e
+	}
+
+	testCases := map[string]struct {
+		a, b   string
+		want   int
+		wantOK bool
+	}{
+		"equal":         {a: "0010", b: "0010", want: 0, wantOK: true},
+		"less":          {a: "0009", b: "0010", want: -1, wantOK: true},
+		"greater":       {a: "b", b: "a", want: 1, wantOK: true},
+		"lexicographic": {a: "9", b: "10", want: 1, wantOK: true},
+		"missing":       {a: "1", b: "", wantOK: false},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			got, ok := extensions.CompareSequence(withSequence(tc.a), withSequence(tc.b))
+			require.Equal(t, tc.wantOK, ok)
+			require.Equal(t, tc.want, got)
+		})
+	}
+
+	e := newRegistryTestEvent()
+	require.Error(t, extensions.Sequence.Set(&e, ""))
+}
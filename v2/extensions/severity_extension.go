@@ -0,0 +1,77 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions
+
+import (
+	"errors"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+const (
+	SeverityTextExtensionKey   = "severitytext"
+	SeverityNumberExtensionKey = "severitynumber"
+)
+
+var (
+	// SeverityText is the typed accessor for the severitytext extension.
+	SeverityText = Define[string](SeverityTextExtensionKey).WithDescription("Human-readable severity of the event, e.g. \"ERROR\".").WithValidator(nonEmpty)
+	// SeverityNumber is the typed accessor for the severitynumber extension.
+	SeverityNumber = Define[int32](SeverityNumberExtensionKey).WithDescription("Numerical severity of the event, larger is more severe.").WithValidator(nonNegative)
+)
+
+func nonNegative(v int32) error {
+	if v < 0 {
+		return errors.New("MUST be a non-negative integer")
+	}
+	return nil
+}
+
+// SeverityExtension represents the severity extension as defined in
+// https://github.com/cloudevents/spec/blob/main/cloudevents/extensions/severity.md
+type SeverityExtension struct {
+	SeverityText   string `json:"severitytext"`
+	SeverityNumber int32  `json:"severitynumber"`
+}
+
+// AddSeverity sets the severity extension on the event. SeverityText is only
+// set if not empty.
+func (s SeverityExtension) AddSeverity(e event.EventWriter) error {
+	if err := SeverityNumber.Set(e, s.SeverityNumber); err != nil {
+		return err
+	}
+	if s.SeverityText != "" {
+		return SeverityText.Set(e, s.SeverityText)
+	}
+	return nil
+}
+
+// GetSeverity retrieves the severity extension from an event. It returns false
+// if the event has no valid severitynumber.
+func GetSeverity(e event.Event) (SeverityExtension, bool) {
+	n, ok := SeverityNumber.Get(e)
+	if !ok {
+		return SeverityExtension{}, false
+	}
+	t, _ := SeverityText.Get(e)
+	return SeverityExtension{SeverityText: t, SeverityNumber: n}, true
+}
+
+func (s *SeverityExtension) ReadTransformer() binding.TransformerFunc {
+	return binding.Transformers{
+		SeverityNumber.ReadTransformer(&s.SeverityNumber),
+		SeverityText.ReadTransformer(&s.SeverityText),
+	}.Transform
+}
+
+func (s *SeverityExtension) WriteTransformer() binding.TransformerFunc {
+	t := binding.Transformers{SeverityNumber.WriteTransformer(s.SeverityNumber)}
+	if s.SeverityText != "" {
+		t = append(t, SeverityText.WriteTransformer(s.SeverityText))
+	}
+	return t.Transform
+}
@@ -0,0 +1,43 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	bindingtest "github.com/cloudevents/sdk-go/v2/binding/test"
+	"github.com/cloudevents/sdk-go/v2/extensions"
+)
+
+func TestSeverity(t *testing.T) {
+	e := newRegistryTestEvent()
+	_, ok := extensions.GetSeverity(e)
+	require.False(t, ok)
+
+	want := extensions.SeverityExtension{SeverityText: "ERROR", SeverityNumber: 17}
+	require.NoError(t, want.AddSeverity(&e))
+	got, ok := extensions.GetSeverity(e)
+	require.True(t, ok)
+	require.Equal(t, want, got)
+
+	require.Error(t, extensions.SeverityExtension{SeverityNumber: -1}.AddSeverity(&e))
+}
+
+func TestSeverityTransformers(t *testing.T) {
+	want := extensions.SeverityExtension{SeverityText: "WARN", SeverityNumber: 13}
+	e := newRegistryTestEvent()
+	out, err := binding.ToEvent(context.Background(), bindingtest.MustCreateMockBinaryMessage(e), want.WriteTransformer())
+	require.NoError(t, err)
+
+	got := extensions.SeverityExtension{}
+	_, err = binding.ToEvent(context.Background(), bindingtest.MustCreateMockBinaryMessage(*out), got.ReadTransformer())
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
@@ -0,0 +1,52 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package jsondata holds the helpers for JSON event data shared by the
+// binding/transformer and redact packages.
+package jsondata
+
+import (
+	"strings"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+// IsJSON reports whether mediaType denotes JSON data, an empty media type
+// denoting JSON as for the datacodecs.
+func IsJSON(mediaType string) bool {
+	return mediaType == "" || mediaType == event.ApplicationJSON || mediaType == event.TextJSON ||
+		strings.HasSuffix(mediaType, "+json")
+}
+
+// UpdateField replaces the field at path in the decoded JSON value v with
+// the result of fn, returning the updated v. path holds the names of the
+// nested fields. Arrays crossed by path have the field updated in each of
+// their elements. Missing fields are skipped.
+func UpdateField(v interface{}, path []string, fn func(interface{}) (interface{}, error)) (interface{}, error) {
+	if len(path) == 0 {
+		return fn(v)
+	}
+	switch t := v.(type) {
+	case map[string]interface{}:
+		child, ok := t[path[0]]
+		if !ok {
+			return v, nil
+		}
+		child, err := UpdateField(child, path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		t[path[0]] = child
+	case []interface{}:
+		for i := range t {
+			child, err := UpdateField(t[i], path, fn)
+			if err != nil {
+				return nil, err
+			}
+			t[i] = child
+		}
+	}
+	return v, nil
+}
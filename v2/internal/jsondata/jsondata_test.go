@@ -0,0 +1,72 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package jsondata
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateField(t *testing.T) {
+	tests := map[string]struct {
+		path    string
+		data    string
+		want    string
+		wantErr bool
+	}{
+		"field": {
+			path: "a",
+			data: `{"a":1,"b":2}`,
+			want: `{"a":"x","b":2}`,
+		},
+		"nested": {
+			path: "a.b",
+			data: `{"a":{"b":1,"c":2}}`,
+			want: `{"a":{"b":"x","c":2}}`,
+		},
+		"arrays": {
+			path: "a.b",
+			data: `{"a":[{"b":1},{"c":2},[{"b":3}]]}`,
+			want: `{"a":[{"b":"x"},{"c":2},[{"b":"x"}]]}`,
+		},
+		"missing fields": {
+			path: "a.b.c",
+			data: `{"a":{"c":1},"b":2}`,
+			want: `{"a":{"c":1},"b":2}`,
+		},
+		"error": {
+			path:    "a",
+			data:    `{"a":"fail"}`,
+			wantErr: true,
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			var v interface{}
+			require.NoError(t, json.Unmarshal([]byte(tc.data), &v))
+
+			got, err := UpdateField(v, strings.Split(tc.path, "."), func(v interface{}) (interface{}, error) {
+				if v == "fail" {
+					return nil, errors.New("failed")
+				}
+				return "x", nil
+			})
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			b, err := json.Marshal(got)
+			require.NoError(t, err)
+			require.JSONEq(t, tc.want, string(b))
+		})
+	}
+}
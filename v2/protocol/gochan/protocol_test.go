@@ -13,6 +13,8 @@ import (
 
 	"github.com/cloudevents/sdk-go/v2/binding"
 	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/test"
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
 )
@@ -215,3 +217,23 @@ func ReceiveTest(t *testing.T, p *SendReceiver, ctx context.Context, want bindin
 		t.Errorf("unexpected diff (-want, +got) = %v", diff)
 	}
 }
+
+func TestConformance(t *testing.T) {
+	test.RunConformance(t, test.Conformance{
+		NewSenderReceiver: func(t *testing.T) (protocol.Sender, protocol.Receiver) {
+			sr := New()
+			return sr, sr
+		},
+		NewRequesterResponder: func(t *testing.T) (protocol.Requester, protocol.Responder) {
+			in := make(chan binding.Message)
+			out := make(chan ChanResponderResponse)
+			requester := &Requester{Ch: in, Reply: func(binding.Message) (binding.Message, error) {
+				resp := <-out
+				return resp.Message, resp.Result
+			}}
+			return requester, &Responder{In: in, Out: out}
+		},
+		// Messages are finished when they are sent.
+		SkipNACK: true,
+	})
+}
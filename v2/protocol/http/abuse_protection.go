@@ -7,12 +7,15 @@ package http
 
 import (
 	"context"
-	cecontext "github.com/cloudevents/sdk-go/v2/context"
-	"go.uber.org/zap"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
+
+	"go.uber.org/zap"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
 )
 
 type WebhookConfig struct {
@@ -20,17 +23,38 @@ type WebhookConfig struct {
 	AllowedRate     *int
 	AutoACKCallback bool
 	AllowedOrigins  []string
+
+	// EnforceOrigin rejects delivery requests whose origin does not match
+	// AllowedOrigins with 403 Forbidden. The origin of a request is its Origin
+	// header, or else its WebHook-Request-Origin header, as set by senders
+	// using WithWebhookValidation.
+	//
+	// EnforceOrigin is not authentication: these headers are set by the
+	// sender, which can claim any origin. Use WithWebhookVerifier or
+	// WithRequestAuthenticator to authenticate senders.
+	EnforceOrigin bool
+	// EnforceRate throttles delivery requests per origin at AllowedRate
+	// requests per minute (DefaultAllowedRate if nil). Requests over the rate
+	// are rejected with 429 Too Many Requests and a Retry-After header.
+	EnforceRate bool
+	// ApproveCallback, if set, decides whether to approve a validation request
+	// carrying a WebHook-Request-Callback. It is called asynchronously, after
+	// the OPTIONS request has been answered, and the callback is acknowledged
+	// if it returns true. It takes precedence over AutoACKCallback.
+	ApproveCallback WebhookCallbackApprover
 }
 
+// WebhookCallbackApprover decides whether to approve the validation request
+// of origin, to be acknowledged on the callback URL.
+type WebhookCallbackApprover func(ctx context.Context, origin, callback string) (bool, error)
+
 const (
 	DefaultAllowedRate = 1000
 	DefaultTimeout     = time.Second * 600
 )
 
-// TODO: implement rate limiting.
-// Throttling is indicated by requests being rejected using HTTP status code 429 Too Many Requests.
-// TODO: use this if Webhook Request Origin has been turned on.
-// Inbound requests should be rejected if Allowed Origins is required by SDK.
+// maxTrackedOrigins bounds the number of per-origin rate limiters.
+const maxTrackedOrigins = 1024
 
 func (p *Protocol) OptionsHandler(rw http.ResponseWriter, req *http.Request) {
 	if req.Method != http.MethodOptions || p.WebhookConfig == nil {
@@ -69,31 +93,29 @@ func (p *Protocol) OptionsHandler(rw http.ResponseWriter, req *http.Request) {
 
 	cb := req.Header.Get("WebHook-Request-Callback")
 	if cb != "" {
-		if p.WebhookConfig.AutoACKCallback {
+		ctx := context.WithoutCancel(req.Context())
+		switch {
+		case p.WebhookConfig.ApproveCallback != nil:
+			approve := p.WebhookConfig.ApproveCallback
+			origin := req.Header.Get("WebHook-Request-Origin")
 			go func() {
-				reqAck, err := http.NewRequest(http.MethodPost, cb, nil)
+				ok, err := approve(ctx, origin, cb)
 				if err != nil {
-					cecontext.LoggerFrom(req.Context()).Errorw("OPTIONS handler failed to create http request attempting to ack callback.", zap.Error(err), zap.String("callback", cb))
+					cecontext.LoggerFrom(ctx).Errorw("OPTIONS handler failed to approve callback.", zap.Error(err), zap.String("callback", cb))
 					return
 				}
-
-				// Write out the headers.
-				for k := range headers {
-					reqAck.Header.Set(k, headers.Get(k))
-				}
-
-				_, err = http.DefaultClient.Do(reqAck)
-				if err != nil {
-					cecontext.LoggerFrom(req.Context()).Errorw("OPTIONS handler failed to ack callback.", zap.Error(err), zap.String("callback", cb))
+				if !ok {
+					cecontext.LoggerFrom(ctx).Infow("OPTIONS handler did not approve callback.", zap.String("origin", origin), zap.String("callback", cb))
 					return
 				}
+				ackCallback(ctx, cb, headers)
 			}()
-			return
-		} else {
-			cecontext.LoggerFrom(req.Context()).Infof("ACTION REQUIRED: Please validate web hook request callback: %q", cb)
-			// TODO: what to do pending https://github.com/cloudevents/spec/issues/617
-			return
+		case p.WebhookConfig.AutoACKCallback:
+			go ackCallback(ctx, cb, headers)
+		default:
+			cecontext.LoggerFrom(ctx).Infof("ACTION REQUIRED: Please validate web hook request callback: %q", cb)
 		}
+		return
 	}
 
 	// Write out the headers.
@@ -110,19 +132,124 @@ func (p *Protocol) ValidateOrigin(req *http.Request) (string, bool) {
 	return p.validateOrigin(req.Header.Get("Origin"))
 }
 
+// deliveryOrigin returns the origin of a delivery request: its Origin header,
+// or else its WebHook-Request-Origin header.
+func deliveryOrigin(req *http.Request) string {
+	if origin := req.Header.Get("Origin"); origin != "" {
+		return origin
+	}
+	return req.Header.Get("WebHook-Request-Origin")
+}
+
 func (p *Protocol) validateOrigin(ro string) (string, bool) {
-	cecontext.LoggerFrom(context.TODO()).Infow("Validating origin.", zap.String("origin", ro))
+	cecontext.LoggerFrom(context.TODO()).Debugw("Validating origin.", zap.String("origin", ro))
 
 	for _, ao := range p.WebhookConfig.AllowedOrigins {
 		if ao == "*" {
 			return ao, true
 		}
-		// TODO: it is not clear what the rules for allowed hosts are.
-		// Need to find docs for this. For now, test for prefix.
-		if strings.HasPrefix(ro, ao) {
-			return ao, true
+		if matchOrigin(ao, ro) {
+			return ro, true
 		}
 	}
 
 	return ro, false
 }
+
+// enforceWebhook applies the origin and rate checks of the WebhookConfig to a
+// delivery request. It writes the response and returns false if the request is
+// rejected.
+func (p *Protocol) enforceWebhook(rw http.ResponseWriter, req *http.Request) bool {
+	cfg := p.WebhookConfig
+	if cfg.EnforceOrigin {
+		if _, ok := p.validateOrigin(deliveryOrigin(req)); !ok {
+			http.Error(rw, "origin not allowed", http.StatusForbidden)
+			return false
+		}
+	}
+	if cfg.EnforceRate {
+		p.webhookLimiterOnce.Do(func() {
+			perMinute := DefaultAllowedRate
+			if cfg.AllowedRate != nil {
+				perMinute = *cfg.AllowedRate
+			}
+			p.webhookLimiter = newOriginRateLimiter(perMinute)
+		})
+		if ok, reset, _ := p.webhookLimiter.Allow(req.Context(), req); !ok {
+			if reset == 0 {
+				// No request is ever allowed at a rate of 0.
+				reset = 60
+			}
+			rw.Header().Set("Retry-After", strconv.FormatUint(reset, 10))
+			http.Error(rw, "limit exceeded", http.StatusTooManyRequests)
+			return false
+		}
+	}
+	return true
+}
+
+// newOriginRateLimiter returns a token bucket per origin, refilled at
+// perMinute tokens per minute.
+func newOriginRateLimiter(perMinute int) *KeyedRateLimiter {
+	return NewKeyedRateLimiter(KeyByOrigin, func() RateLimiter {
+		return NewTokenBucketLimiter(float64(perMinute)/60, perMinute)
+	}, 0, maxTrackedOrigins)
+}
+
+func ackCallback(ctx context.Context, cb string, headers http.Header) {
+	reqAck, err := http.NewRequestWithContext(ctx, http.MethodPost, cb, nil)
+	if err != nil {
+		cecontext.LoggerFrom(ctx).Errorw("OPTIONS handler failed to create http request attempting to ack callback.", zap.Error(err), zap.String("callback", cb))
+		return
+	}
+
+	// Write out the headers.
+	for k := range headers {
+		reqAck.Header.Set(k, headers.Get(k))
+	}
+
+	resp, err := http.DefaultClient.Do(reqAck)
+	if err != nil {
+		cecontext.LoggerFrom(ctx).Errorw("OPTIONS handler failed to ack callback.", zap.Error(err), zap.String("callback", cb))
+		return
+	}
+	_ = resp.Body.Close()
+}
+
+// matchOrigin reports whether origin matches the allowed origin pattern. Both
+// may be a host name ("example.com") or a serialized origin
+// ("https://example.com:8443"). A pattern matches origins with the same host
+// name, and with the same scheme and port if the pattern has them. A pattern
+// host starting with "*." matches the sub-domains of the rest of the pattern.
+func matchOrigin(pattern, origin string) bool {
+	pScheme, pHost, pPort := splitOrigin(pattern)
+	oScheme, oHost, oPort := splitOrigin(origin)
+	if oHost == "" {
+		return false
+	}
+	if pScheme != "" && pScheme != oScheme {
+		return false
+	}
+	if pPort != "" && pPort != oPort {
+		return false
+	}
+	if strings.HasPrefix(pHost, "*.") {
+		return len(oHost) > len(pHost)-1 && strings.HasSuffix(oHost, pHost[1:])
+	}
+	return oHost == pHost
+}
+
+func splitOrigin(s string) (scheme, host, port string) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if i := strings.Index(s, "://"); i >= 0 {
+		scheme, s = s[:i], s[i+3:]
+	}
+	if i := strings.IndexAny(s, "/?#"); i >= 0 {
+		s = s[:i]
+	}
+	host = s
+	if h, p, err := net.SplitHostPort(s); err == nil {
+		host, port = h, p
+	}
+	return scheme, host, port
+}
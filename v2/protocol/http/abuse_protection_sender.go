@@ -0,0 +1,230 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+)
+
+// DefaultWebhookValidationTTL is how long a successful validation handshake
+// is trusted before the target is validated again.
+const DefaultWebhookValidationTTL = time.Hour
+
+// ErrWebhookValidationFailed is returned when sending to a target that did not
+// pass the abuse protection handshake.
+var ErrWebhookValidationFailed = errors.New("webhook validation failed")
+
+// WebhookValidator performs the sending side of the abuse protection
+// handshake:
+// https://github.com/cloudevents/spec/blob/v1.0/http-webhook.md#4-abuse-protection
+//
+// Before the first delivery to a target, it sends an OPTIONS request with the
+// WebHook-Request-Origin and WebHook-Request-Rate headers, and checks that the
+// target allows the origin. The result is cached per target for TTL, and
+// deliveries to a target are throttled to the rate it allows.
+type WebhookValidator struct {
+	// Origin is sent as WebHook-Request-Origin. Required.
+	Origin string
+	// RequestRate, if set, is sent as WebHook-Request-Rate, in requests per
+	// minute.
+	RequestRate *int
+	// TTL is how long a successful handshake is trusted. If 0,
+	// DefaultWebhookValidationTTL is used.
+	TTL time.Duration
+
+	mu      sync.Mutex
+	targets map[string]*webhookTarget
+	now     func() time.Time
+}
+
+// WebhookValidation is the outcome of a successful handshake with a target.
+type WebhookValidation struct {
+	// AllowedOrigin is the WebHook-Allowed-Origin returned by the target.
+	AllowedOrigin string
+	// AllowedRate is the WebHook-Allowed-Rate returned by the target, in
+	// requests per minute, or nil if the target did not limit the rate.
+	// Deliveries with WithWebhookValidator wait to stay within it.
+	AllowedRate *int
+	// Expires is when the target has to be validated again.
+	Expires time.Time
+}
+
+type webhookTarget struct {
+	mu         sync.Mutex
+	validation *WebhookValidation
+	// limiter throttles deliveries to rate, the AllowedRate of validation,
+	// nil if the rate is not limited.
+	limiter *TokenBucketLimiter
+	rate    int
+}
+
+// NewWebhookValidator returns a validator sending origin as
+// WebHook-Request-Origin.
+func NewWebhookValidator(origin string) *WebhookValidator {
+	return &WebhookValidator{Origin: origin}
+}
+
+// Validation returns the cached validation of target, if it is still valid.
+func (v *WebhookValidator) Validation(target *url.URL) (WebhookValidation, bool) {
+	t := v.target(target)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.validation == nil || !v.clock().Before(t.validation.Expires) {
+		return WebhookValidation{}, false
+	}
+	return *t.validation, true
+}
+
+// Invalidate drops the cached validation of target, so that the next delivery
+// performs the handshake again.
+func (v *WebhookValidator) Invalidate(target *url.URL) {
+	t := v.target(target)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.validation = nil
+}
+
+// Validate performs the handshake with the target of req using client, unless
+// a previous handshake is still valid. req is the delivery request, it is not
+// sent. Validate returns an error wrapping ErrWebhookValidationFailed if the
+// target refused the origin or the method of req.
+func (v *WebhookValidator) Validate(ctx context.Context, client *http.Client, req *http.Request) (WebhookValidation, error) {
+	target := req.URL
+	t := v.target(target)
+	// Holding the target lock during the handshake makes concurrent deliveries
+	// to the same target wait for a single handshake.
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := v.clock()
+	if t.validation != nil && now.Before(t.validation.Expires) {
+		return *t.validation, nil
+	}
+	t.validation = nil
+
+	validation, err := v.handshake(ctx, client, target, req.Method)
+	if err != nil {
+		cecontext.LoggerFrom(ctx).Warnw("Webhook validation failed.", zap.Error(err), zap.String("target", target.String()))
+		return WebhookValidation{}, err
+	}
+	ttl := v.TTL
+	if ttl == 0 {
+		ttl = DefaultWebhookValidationTTL
+	}
+	validation.Expires = now.Add(ttl)
+	if validation.AllowedRate == nil {
+		t.limiter = nil
+	} else if t.limiter == nil || t.rate != *validation.AllowedRate {
+		// The bucket is kept across handshakes allowing the same rate.
+		t.rate = *validation.AllowedRate
+		t.limiter = NewTokenBucketLimiter(float64(t.rate)/60, t.rate)
+		t.limiter.now = v.clock
+	}
+	t.validation = &validation
+	return validation, nil
+}
+
+// throttle waits until a delivery to target is within the rate allowed by its
+// last successful handshake, or ctx is done.
+func (v *WebhookValidator) throttle(ctx context.Context, target *url.URL) error {
+	t := v.target(target)
+	t.mu.Lock()
+	limiter := t.limiter
+	t.mu.Unlock()
+	if limiter == nil {
+		return nil
+	}
+	if err := limiter.wait(ctx); err != nil {
+		return fmt.Errorf("webhook allowed rate of %s: %w", target, err)
+	}
+	return nil
+}
+
+func (v *WebhookValidator) handshake(ctx context.Context, client *http.Client, target *url.URL, method string) (WebhookValidation, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodOptions, target.String(), nil)
+	if err != nil {
+		return WebhookValidation{}, err
+	}
+	req.Header.Set("WebHook-Request-Origin", v.Origin)
+	if v.RequestRate != nil {
+		req.Header.Set("WebHook-Request-Rate", strconv.Itoa(*v.RequestRate))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return WebhookValidation{}, fmt.Errorf("%w: %s", ErrWebhookValidationFailed, err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return WebhookValidation{}, fmt.Errorf("%w: OPTIONS %s returned status %d", ErrWebhookValidationFailed, target, resp.StatusCode)
+	}
+
+	validation := WebhookValidation{
+		AllowedOrigin: resp.Header.Get("WebHook-Allowed-Origin"),
+	}
+	if validation.AllowedOrigin != "*" && validation.AllowedOrigin != v.Origin {
+		return WebhookValidation{}, fmt.Errorf("%w: origin %q is not allowed by %s", ErrWebhookValidationFailed, v.Origin, target)
+	}
+
+	if rate := resp.Header.Get("WebHook-Allowed-Rate"); rate != "" && rate != "*" {
+		r, err := strconv.Atoi(rate)
+		if err != nil {
+			return WebhookValidation{}, fmt.Errorf("%w: invalid WebHook-Allowed-Rate %q", ErrWebhookValidationFailed, rate)
+		}
+		validation.AllowedRate = &r
+	} else if rate == "" && v.RequestRate != nil {
+		return WebhookValidation{}, fmt.Errorf("%w: %s did not return WebHook-Allowed-Rate", ErrWebhookValidationFailed, target)
+	}
+
+	if allow := resp.Header.Get("Allow"); allow != "" && !allowsMethod(allow, method) {
+		return WebhookValidation{}, fmt.Errorf("%w: %s does not allow %s", ErrWebhookValidationFailed, target, method)
+	}
+	return validation, nil
+}
+
+func (v *WebhookValidator) target(target *url.URL) *webhookTarget {
+	// Query and fragment do not identify a different webhook.
+	key := target.Scheme + "://" + target.Host + target.EscapedPath()
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.targets == nil {
+		v.targets = make(map[string]*webhookTarget)
+	}
+	t, ok := v.targets[key]
+	if !ok {
+		t = &webhookTarget{}
+		v.targets[key] = t
+	}
+	return t
+}
+
+func (v *WebhookValidator) clock() time.Time {
+	if v.now != nil {
+		return v.now()
+	}
+	return time.Now()
+}
+
+func allowsMethod(allow, method string) bool {
+	for _, m := range strings.Split(allow, ",") {
+		if strings.EqualFold(strings.TrimSpace(m), method) {
+			return true
+		}
+	}
+	return false
+}
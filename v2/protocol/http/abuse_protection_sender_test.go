@@ -0,0 +1,202 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func newWebhookTestEvent() binding.Message {
+	e := event.New()
+	e.SetID("abc-123")
+	e.SetSource("/source")
+	e.SetType("webhook.test")
+	return binding.ToMessage(&e)
+}
+
+type webhookTestServer struct {
+	*httptest.Server
+	options  int32
+	requests int32
+}
+
+func newWebhookTestServer(t *testing.T, options http.HandlerFunc) *webhookTestServer {
+	s := &webhookTestServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodOptions {
+			atomic.AddInt32(&s.options, 1)
+			options(rw, req)
+			return
+		}
+		atomic.AddInt32(&s.requests, 1)
+		rw.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestWebhookValidation(t *testing.T) {
+	rate := 60
+	testCases := map[string]struct {
+		options     http.HandlerFunc
+		requestRate *int
+		wantErr     bool
+		wantRate    *int
+	}{
+		"allowed origin": {
+			options: func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("WebHook-Allowed-Origin", req.Header.Get("WebHook-Request-Origin"))
+			},
+		},
+		"any origin with rate": {
+			options: func(rw http.ResponseWriter, req *http.Request) {
+				require.Equal(t, "120", req.Header.Get("WebHook-Request-Rate"))
+				rw.Header().Set("WebHook-Allowed-Origin", "*")
+				rw.Header().Set("WebHook-Allowed-Rate", "60")
+			},
+			requestRate: func() *int { r := 120; return &r }(),
+			wantRate:    &rate,
+		},
+		"other origin": {
+			options: func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("WebHook-Allowed-Origin", "other.example.com")
+			},
+			wantErr: true,
+		},
+		"missing allowed rate": {
+			options: func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("WebHook-Allowed-Origin", "*")
+			},
+			requestRate: &rate,
+			wantErr:     true,
+		},
+		"method not allowed": {
+			options: func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("WebHook-Allowed-Origin", "*")
+				rw.Header().Set("Allow", "PUT")
+			},
+			wantErr: true,
+		},
+		"no options handler": {
+			options: func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusMethodNotAllowed)
+			},
+			wantErr: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			s := newWebhookTestServer(t, tc.options)
+			v := NewWebhookValidator("sender.example.com")
+			v.RequestRate = tc.requestRate
+			p, err := New(WithTarget(s.URL), WithWebhookValidator(v))
+			require.NoError(t, err)
+
+			for i := 0; i < 2; i++ {
+				err = p.Send(context.Background(), newWebhookTestEvent())
+				if tc.wantErr {
+					require.True(t, errors.Is(err, ErrWebhookValidationFailed), "got %v", err)
+				} else {
+					require.True(t, protocol.IsACK(err), "got %v", err)
+				}
+			}
+
+			target, _ := url.Parse(s.URL)
+			validation, ok := v.Validation(target)
+			if tc.wantErr {
+				require.False(t, ok)
+				require.EqualValues(t, 2, s.options)
+				require.EqualValues(t, 0, s.requests)
+				return
+			}
+			require.True(t, ok)
+			require.Equal(t, tc.wantRate, validation.AllowedRate)
+			require.EqualValues(t, 1, s.options)
+			require.EqualValues(t, 2, s.requests)
+		})
+	}
+}
+
+func TestWebhookValidationTTL(t *testing.T) {
+	s := newWebhookTestServer(t, func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("WebHook-Allowed-Origin", "*")
+	})
+	now := time.Now()
+	v := &WebhookValidator{Origin: "sender.example.com", TTL: time.Minute, now: func() time.Time { return now }}
+	p, err := New(WithTarget(s.URL), WithWebhookValidator(v))
+	require.NoError(t, err)
+
+	require.True(t, protocol.IsACK(p.Send(context.Background(), newWebhookTestEvent())))
+	now = now.Add(30 * time.Second)
+	require.True(t, protocol.IsACK(p.Send(context.Background(), newWebhookTestEvent())))
+	require.EqualValues(t, 1, s.options)
+
+	now = now.Add(time.Minute)
+	require.True(t, protocol.IsACK(p.Send(context.Background(), newWebhookTestEvent())))
+	require.EqualValues(t, 2, s.options)
+
+	target, _ := url.Parse(s.URL)
+	v.Invalidate(target)
+	require.True(t, protocol.IsACK(p.Send(context.Background(), newWebhookTestEvent())))
+	require.EqualValues(t, 3, s.options)
+}
+
+func TestWebhookValidationWithOptionsHandler(t *testing.T) {
+	receiver, err := New(WithDefaultOptionsHandlerFunc([]string{http.MethodPost}, 100, []string{"*.example.com"}, false))
+	require.NoError(t, err)
+	s := httptest.NewServer(receiver)
+	defer s.Close()
+
+	v := &WebhookValidator{Origin: "sender.example.com", RequestRate: &[]int{200}[0]}
+	target, _ := url.Parse(s.URL)
+	req := &http.Request{Method: http.MethodPost, URL: target}
+	validation, err := v.Validate(context.Background(), http.DefaultClient, req)
+	require.NoError(t, err)
+	require.Equal(t, "sender.example.com", validation.AllowedOrigin)
+	require.Equal(t, 100, *validation.AllowedRate)
+}
+
+func TestWebhookValidationAllowedRate(t *testing.T) {
+	s := newWebhookTestServer(t, func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("WebHook-Allowed-Origin", "*")
+		rw.Header().Set("WebHook-Allowed-Rate", "2")
+	})
+	clock := newTestClock()
+	v := &WebhookValidator{Origin: "sender.example.com", now: clock.Now}
+	p, err := New(WithTarget(s.URL), WithWebhookValidator(v))
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		require.True(t, protocol.IsACK(p.Send(context.Background(), newWebhookTestEvent())))
+	}
+
+	// The third delivery in the same minute exceeds the allowed rate, and
+	// waits until ctx is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = p.Send(ctx, newWebhookTestEvent())
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.EqualValues(t, 2, s.requests)
+
+	// A token is available again after half a minute.
+	clock.Add(30 * time.Second)
+	require.True(t, protocol.IsACK(p.Send(context.Background(), newWebhookTestEvent())))
+	require.EqualValues(t, 3, s.requests)
+	require.EqualValues(t, 1, s.options)
+}
@@ -0,0 +1,249 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func TestMatchOrigin(t *testing.T) {
+	testCases := map[string]struct {
+		pattern string
+		origin  string
+		want    bool
+	}{
+		"same host":                 {pattern: "example.com", origin: "example.com", want: true},
+		"host and origin":           {pattern: "example.com", origin: "https://example.com:8443", want: true},
+		"case insensitive":          {pattern: "Example.COM", origin: "https://example.com", want: true},
+		"prefix is not a match":     {pattern: "example.com", origin: "example.com.evil.io"},
+		"other host":                {pattern: "example.com", origin: "example.org"},
+		"scheme matches":            {pattern: "https://example.com", origin: "https://example.com", want: true},
+		"scheme differs":            {pattern: "https://example.com", origin: "http://example.com"},
+		"port matches":              {pattern: "https://example.com:8443", origin: "https://example.com:8443", want: true},
+		"port differs":              {pattern: "https://example.com:8443", origin: "https://example.com"},
+		"wildcard sub-domain":       {pattern: "*.example.com", origin: "https://a.b.example.com", want: true},
+		"wildcard excludes apex":    {pattern: "*.example.com", origin: "example.com"},
+		"wildcard suffix mismatch":  {pattern: "*.example.com", origin: "notexample.com"},
+		"empty origin":              {pattern: "example.com", origin: ""},
+		"trailing path is ignored":  {pattern: "example.com", origin: "https://example.com/", want: true},
+		"null origin never matches": {pattern: "example.com", origin: "null"},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			require.Equal(t, tc.want, matchOrigin(tc.pattern, tc.origin))
+		})
+	}
+}
+
+func TestOptionsHandler_AllowedOrigin(t *testing.T) {
+	testCases := map[string]struct {
+		allowed    []string
+		origin     string
+		wantStatus int
+		wantOrigin string
+	}{
+		"any": {
+			allowed:    []string{"*"},
+			origin:     "sender.example.com",
+			wantStatus: http.StatusOK,
+			wantOrigin: "*",
+		},
+		"matching": {
+			allowed:    []string{"other.example.org", "*.example.com"},
+			origin:     "sender.example.com",
+			wantStatus: http.StatusOK,
+			wantOrigin: "sender.example.com",
+		},
+		"not matching": {
+			allowed:    []string{"example.com"},
+			origin:     "example.com.evil.io",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			p, err := New(WithDefaultOptionsHandlerFunc(nil, 10, tc.allowed, false))
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodOptions, "http://unittest", nil)
+			req.Header.Set("WebHook-Request-Origin", tc.origin)
+			rec := httptest.NewRecorder()
+			p.ServeHTTP(rec, req)
+
+			require.Equal(t, tc.wantStatus, rec.Code)
+			require.Equal(t, tc.wantOrigin, rec.Header().Get("WebHook-Allowed-Origin"))
+		})
+	}
+}
+
+func TestServeHTTP_WebhookEnforcement(t *testing.T) {
+	p, err := New(
+		WithDefaultOptionsHandlerFunc(nil, 2, []string{"*.example.com"}, false),
+		WithWebhookEnforcement(),
+	)
+	require.NoError(t, err)
+
+	// Deliveries from allowed origins are received and acked.
+	go func() {
+		for {
+			m, fn, err := p.Respond(context.Background())
+			if err != nil {
+				return
+			}
+			_ = m.Finish(nil)
+			_ = fn(context.Background(), nil, nil)
+		}
+	}()
+
+	send := func(origin string) *httptest.ResponseRecorder {
+		req, err := NewHTTPRequestFromEvent(context.Background(), "http://unittest", newBatchTestEvents("a")[0])
+		require.NoError(t, err)
+		req.Header.Set("Origin", origin)
+		rec := httptest.NewRecorder()
+		p.ServeHTTP(rec, req)
+		return rec
+	}
+
+	require.Equal(t, http.StatusForbidden, send("https://evil.io").Code)
+	require.Equal(t, http.StatusForbidden, send("").Code)
+
+	// The rate is 2 per minute, per origin.
+	require.Equal(t, http.StatusOK, send("https://a.example.com").Code)
+	require.Equal(t, http.StatusOK, send("https://a.example.com").Code)
+	rec := send("https://a.example.com")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "30", rec.Header().Get("Retry-After"))
+	require.Equal(t, http.StatusOK, send("https://b.example.com").Code)
+}
+
+func TestWebhookEnforcementWithValidation(t *testing.T) {
+	receiver, err := New(
+		WithDefaultOptionsHandlerFunc(nil, 100, []string{"*.example.com"}, false),
+		WithWebhookEnforcement(),
+	)
+	require.NoError(t, err)
+	s := httptest.NewServer(receiver)
+	defer s.Close()
+	go func() {
+		for {
+			m, fn, err := receiver.Respond(context.Background())
+			if err != nil {
+				return
+			}
+			_ = m.Finish(nil)
+			_ = fn(context.Background(), nil, nil)
+		}
+	}()
+
+	// Senders performing the handshake deliver with the validated origin.
+	sender, err := New(WithTarget(s.URL), WithWebhookValidation("sender.example.com"))
+	require.NoError(t, err)
+	e := newBatchTestEvents("a")[0]
+	require.True(t, protocol.IsACK(sender.Send(context.Background(), binding.ToMessage(&e))))
+
+	// Other senders are rejected.
+	sender, err = New(WithTarget(s.URL))
+	require.NoError(t, err)
+	var result *Result
+	require.True(t, protocol.ResultAs(sender.Send(context.Background(), binding.ToMessage(&e)), &result))
+	require.Equal(t, http.StatusForbidden, result.StatusCode)
+}
+
+func TestServeHTTP_WebhookEnforcementZeroRate(t *testing.T) {
+	p, err := New(
+		WithDefaultOptionsHandlerFunc(nil, 0, []string{"*"}, false),
+		WithWebhookEnforcement(),
+	)
+	require.NoError(t, err)
+
+	req, err := NewHTTPRequestFromEvent(context.Background(), "http://unittest", newBatchTestEvents("a")[0])
+	require.NoError(t, err)
+	req.Header.Set("Origin", "https://example.com")
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "60", rec.Header().Get("Retry-After"))
+}
+
+func TestOriginRateLimiter(t *testing.T) {
+	clock := newTestClock()
+	l := newOriginRateLimiter(60)
+	l.now = clock.Now
+	newLimiter := l.newLimiter
+	l.newLimiter = func() RateLimiter {
+		lim := newLimiter().(*TokenBucketLimiter)
+		lim.now = clock.Now
+		return lim
+	}
+	req := httptest.NewRequest(http.MethodPost, "http://unittest", nil)
+	req.Header.Set("Origin", "https://example.com")
+
+	allowed, reset := allowN(t, l, req, 61)
+	require.Equal(t, 60, allowed)
+	require.Equal(t, uint64(1), reset)
+
+	clock.Add(time.Second)
+	allowed, _ = allowN(t, l, req, 1)
+	require.Equal(t, 1, allowed)
+}
+
+func TestWithWebhookEnforcement_noConfig(t *testing.T) {
+	_, err := New(WithWebhookEnforcement())
+	require.Error(t, err)
+}
+
+func TestOptionsHandler_ApproveCallback(t *testing.T) {
+	acked := make(chan http.Header, 1)
+	callback := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		acked <- req.Header
+	}))
+	defer callback.Close()
+
+	testCases := map[string]struct {
+		approve bool
+	}{
+		"approved":     {approve: true},
+		"not approved": {approve: false},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			approved := make(chan string, 1)
+			p, err := New(
+				WithDefaultOptionsHandlerFunc(nil, 10, []string{"*"}, false),
+				WithWebhookCallbackApprover(func(ctx context.Context, origin, cb string) (bool, error) {
+					require.Equal(t, callback.URL, cb)
+					approved <- origin
+					return tc.approve, nil
+				}),
+			)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodOptions, "http://unittest", nil)
+			req.Header.Set("WebHook-Request-Origin", "sender.example.com")
+			req.Header.Set("WebHook-Request-Callback", callback.URL)
+			rec := httptest.NewRecorder()
+			p.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code)
+			require.Equal(t, "sender.example.com", <-approved)
+
+			select {
+			case h := <-acked:
+				require.True(t, tc.approve, "callback acked without approval")
+				require.Equal(t, "*", h.Get("WebHook-Allowed-Origin"))
+			case <-time.After(200 * time.Millisecond):
+				require.False(t, tc.approve, "callback not acked")
+			}
+		})
+	}
+}
@@ -0,0 +1,329 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/extensions"
+)
+
+// DefaultTokenExpiryDelta is how long before its expiry a token obtained with
+// ClientCredentials is refreshed.
+const DefaultTokenExpiryDelta = 10 * time.Second
+
+// ErrUnauthenticated is wrapped by the errors of RequestAuthenticators
+// rejecting a request.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Authenticator adds credentials to outgoing requests.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// RequestAuthenticator authenticates incoming requests, returning the
+// principal that sent them. Requests are rejected with 401 Unauthorized when
+// an error is returned, unless it is a *Result with another status code.
+type RequestAuthenticator interface {
+	AuthenticateRequest(req *http.Request) (*Principal, error)
+}
+
+// RequestAuthenticatorFunc is a function implementing RequestAuthenticator,
+// such as the AuthenticateRequest method of a validator from the
+// github.com/cloudevents/sdk-go/auth/jwt/v2 module.
+type RequestAuthenticatorFunc func(req *http.Request) (*Principal, error)
+
+// AuthenticateRequest implements RequestAuthenticator.
+func (f RequestAuthenticatorFunc) AuthenticateRequest(req *http.Request) (*Principal, error) {
+	return f(req)
+}
+
+// Principal is the authenticated sender of a request, as returned by a
+// RequestAuthenticator. It is available to receivers with
+// PrincipalFromContext.
+type Principal struct {
+	// Type is the kind of principal, one of the authtype values of the
+	// authcontext extension, such as extensions.AuthTypeServiceAccount.
+	Type string
+	// ID identifies the principal, such as the subject of a token.
+	ID string
+	// Claims are the claims of the principal, such as the ones of a JWT.
+	Claims map[string]interface{}
+}
+
+// authContext returns the authcontext extension describing p.
+func (p *Principal) authContext() (extensions.AuthContextExtension, error) {
+	a := extensions.AuthContextExtension{AuthType: p.Type, AuthID: p.ID}
+	if a.AuthType == "" {
+		a.AuthType = extensions.AuthTypeUnknown
+	}
+	if len(p.Claims) > 0 {
+		claims, err := json.Marshal(p.Claims)
+		if err != nil {
+			return a, err
+		}
+		a.AuthClaims = string(claims)
+	}
+	return a, nil
+}
+
+// authContextKeys are the attributes of the authcontext extension.
+var authContextKeys = []string{extensions.AuthTypeExtensionKey, extensions.AuthIDExtensionKey, extensions.AuthClaimsExtensionKey}
+
+// setAuthContextHeaders replaces the authcontext extension headers of a
+// binary mode request, so that senders cannot forge them. They are removed
+// if p is nil.
+func (p *Principal) setAuthContextHeaders(h http.Header) error {
+	for _, key := range authContextKeys {
+		h.Del(prefix + key)
+	}
+	if p == nil {
+		return nil
+	}
+	a, err := p.authContext()
+	if err != nil {
+		return err
+	}
+	h.Set(prefix+extensions.AuthTypeExtensionKey, a.AuthType)
+	if a.AuthID != "" {
+		h.Set(prefix+extensions.AuthIDExtensionKey, a.AuthID)
+	}
+	if a.AuthClaims != "" {
+		h.Set(prefix+extensions.AuthClaimsExtensionKey, a.AuthClaims)
+	}
+	return nil
+}
+
+// setAuthContext replaces the authcontext extension of e, or removes it if p
+// is nil.
+func (p *Principal) setAuthContext(e *event.Event) error {
+	for _, key := range authContextKeys {
+		e.SetExtension(key, nil)
+	}
+	if p == nil {
+		return nil
+	}
+	a, err := p.authContext()
+	if err != nil {
+		return err
+	}
+	return a.AddAuthContext(e)
+}
+
+// BearerToken is a static bearer token. As an Authenticator it sets the
+// Authorization header of outgoing requests, and as a RequestAuthenticator it
+// accepts the incoming requests bearing it, with an api_key principal.
+type BearerToken string
+
+var (
+	_ Authenticator        = BearerToken("")
+	_ RequestAuthenticator = BearerToken("")
+	_ Authenticator        = (*ClientCredentials)(nil)
+)
+
+// Authenticate implements Authenticator.Authenticate
+func (t BearerToken) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+string(t))
+	return nil
+}
+
+// AuthenticateRequest implements RequestAuthenticator.AuthenticateRequest
+func (t BearerToken) AuthenticateRequest(req *http.Request) (*Principal, error) {
+	token, ok := bearerToken(req)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing bearer token", ErrUnauthenticated)
+	}
+	if t == "" || subtle.ConstantTimeCompare([]byte(token), []byte(t)) != 1 {
+		return nil, fmt.Errorf("%w: invalid bearer token", ErrUnauthenticated)
+	}
+	return &Principal{Type: extensions.AuthTypeAPIKey}, nil
+}
+
+// bearerToken returns the token of the Authorization header of req.
+func bearerToken(req *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// ClientCredentials is an Authenticator obtaining access tokens with the OAuth2
+// client credentials grant (RFC 6749, section 4.4). Tokens are cached until
+// they are about to expire.
+type ClientCredentials struct {
+	// TokenURL is the token endpoint of the authorization server.
+	TokenURL string
+	// ClientID and ClientSecret authenticate the client with HTTP Basic
+	// authentication.
+	ClientID     string
+	ClientSecret string
+	// Scopes are the optional requested scopes.
+	Scopes []string
+	// EndpointParams are additional parameters of token requests, such as
+	// "audience".
+	EndpointParams url.Values
+	// Client sends the token requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+	// ExpiryDelta is how long before its expiry a token is refreshed. If 0,
+	// DefaultTokenExpiryDelta is used.
+	ExpiryDelta time.Duration
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+	now    func() time.Time
+}
+
+// Authenticate implements Authenticator.Authenticate
+func (c *ClientCredentials) Authenticate(req *http.Request) error {
+	token, err := c.Token(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Token returns the cached access token, requesting a new one if there is
+// none or it is about to expire.
+func (c *ClientCredentials) Token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock()
+	delta := c.ExpiryDelta
+	if delta == 0 {
+		delta = DefaultTokenExpiryDelta
+	}
+	if c.token != "" && (c.expiry.IsZero() || now.Add(delta).Before(c.expiry)) {
+		return c.token, nil
+	}
+
+	token, expiresIn, err := c.requestToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.token = token
+	c.expiry = time.Time{}
+	if expiresIn > 0 {
+		c.expiry = now.Add(expiresIn)
+	}
+	return c.token, nil
+}
+
+// Invalidate drops the cached token, so that the next request gets a new one.
+// It is called when a request is rejected with 401 Unauthorized.
+func (c *ClientCredentials) Invalidate() {
+	c.mu.Lock()
+	c.token = ""
+	c.mu.Unlock()
+}
+
+func (c *ClientCredentials) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+func (c *ClientCredentials) requestToken(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(c.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	for k, v := range c.EndpointParams {
+		form[k] = v
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", 0, fmt.Errorf("token request failed: %w", err)
+	}
+
+	var tr struct {
+		AccessToken      string      `json:"access_token"`
+		TokenType        string      `json:"token_type"`
+		ExpiresIn        json.Number `json:"expires_in"`
+		Error            string      `json:"error"`
+		ErrorDescription string      `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &tr); err != nil && resp.StatusCode/100 == 2 {
+		return "", 0, fmt.Errorf("invalid token response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 || tr.Error != "" {
+		if tr.Error != "" {
+			return "", 0, fmt.Errorf("token request failed: %d %s %s", resp.StatusCode, tr.Error, tr.ErrorDescription)
+		}
+		return "", 0, fmt.Errorf("token request failed: %d %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if tr.AccessToken == "" {
+		return "", 0, errors.New("invalid token response: missing access_token")
+	}
+	if tr.TokenType != "" && !strings.EqualFold(tr.TokenType, "Bearer") {
+		return "", 0, fmt.Errorf("invalid token response: unsupported token_type %q", tr.TokenType)
+	}
+	var expiresIn time.Duration
+	if tr.ExpiresIn != "" {
+		secs, err := tr.ExpiresIn.Int64()
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid token response: expires_in: %w", err)
+		}
+		expiresIn = time.Duration(secs) * time.Second
+	}
+	return tr.AccessToken, expiresIn, nil
+}
+
+// authenticateRequest runs the RequestAuthenticator of p, writing the
+// response and returning nil if req is rejected. Otherwise it returns req with
+// the principal in its context.
+func (p *Protocol) authenticateRequest(rw http.ResponseWriter, req *http.Request) *http.Request {
+	principal, err := p.requestAuthenticator.AuthenticateRequest(req)
+	if err != nil {
+		status := http.StatusUnauthorized
+		var result *Result
+		if errors.As(err, &result) && result.StatusCode >= 400 && result.StatusCode < 600 {
+			status = result.StatusCode
+		}
+		if status == http.StatusUnauthorized {
+			rw.Header().Set("WWW-Authenticate", "Bearer")
+		}
+		http.Error(rw, err.Error(), status)
+		return nil
+	}
+	if principal == nil {
+		return req
+	}
+	return req.WithContext(withPrincipal(req.Context(), principal))
+}
@@ -0,0 +1,280 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/extensions"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func TestBearerToken(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "http://unittest", nil)
+	_, err := BearerToken("secret").AuthenticateRequest(req)
+	require.True(t, errors.Is(err, ErrUnauthenticated))
+
+	require.NoError(t, BearerToken("secret").Authenticate(req))
+	require.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+
+	principal, err := BearerToken("secret").AuthenticateRequest(req)
+	require.NoError(t, err)
+	require.Equal(t, extensions.AuthTypeAPIKey, principal.Type)
+
+	_, err = BearerToken("other").AuthenticateRequest(req)
+	require.True(t, errors.Is(err, ErrUnauthenticated))
+	_, err = BearerToken("").AuthenticateRequest(req)
+	require.True(t, errors.Is(err, ErrUnauthenticated))
+}
+
+type tokenServer struct {
+	*httptest.Server
+	requests int32
+}
+
+func newTokenServer(t *testing.T, expiresIn int) *tokenServer {
+	s := &tokenServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		id, secret, _ := req.BasicAuth()
+		if id != "client" || secret != "secret" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			_, _ = rw.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+		require.NoError(t, req.ParseForm())
+		require.Equal(t, "client_credentials", req.PostForm.Get("grant_type"))
+		require.Equal(t, "events.write events.read", req.PostForm.Get("scope"))
+		require.Equal(t, "https://events", req.PostForm.Get("audience"))
+
+		n := atomic.AddInt32(&s.requests, 1)
+		rw.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(rw, `{"access_token":"token-%d","token_type":"bearer","expires_in":%d}`, n, expiresIn)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestClientCredentials(t *testing.T) {
+	s := newTokenServer(t, 60)
+	clock := newTestClock()
+	c := &ClientCredentials{
+		TokenURL:       s.URL,
+		ClientID:       "client",
+		ClientSecret:   "secret",
+		Scopes:         []string{"events.write", "events.read"},
+		EndpointParams: map[string][]string{"audience": {"https://events"}},
+		now:            clock.Now,
+	}
+
+	token, err := c.Token(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "token-1", token)
+
+	// The token is cached until shortly before it expires.
+	clock.Add(45 * time.Second)
+	token, err = c.Token(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "token-1", token)
+
+	clock.Add(10 * time.Second)
+	token, err = c.Token(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "token-2", token)
+
+	c.Invalidate()
+	req := httptest.NewRequest(http.MethodPost, "http://unittest", nil)
+	require.NoError(t, c.Authenticate(req))
+	require.Equal(t, "Bearer token-3", req.Header.Get("Authorization"))
+
+	c = &ClientCredentials{TokenURL: s.URL, ClientID: "client", ClientSecret: "wrong"}
+	_, err = c.Token(context.Background())
+	require.ErrorContains(t, err, "invalid_client")
+}
+
+// authTestReceiver starts a receiver forwarding the events it gets, with the ID
+// of their principal in the testprincipal extension.
+func authTestReceiver(t *testing.T, opts ...Option) (*Protocol, chan *event.Event) {
+	p, err := New(opts...)
+	require.NoError(t, err)
+	received := make(chan *event.Event, 10)
+	go func() {
+		for {
+			m, fn, err := p.Respond(context.Background())
+			if err != nil {
+				return
+			}
+			e, err := binding.ToEvent(context.Background(), m)
+			require.NoError(t, err)
+			if mctx, ok := m.(binding.MessageContext); ok {
+				if principal := PrincipalFromContext(mctx.Context()); principal != nil {
+					e.SetExtension("testprincipal", principal.ID)
+				}
+			}
+			received <- e
+			_ = m.Finish(nil)
+			_ = fn(context.Background(), nil, nil)
+		}
+	}()
+	return p, received
+}
+
+func TestServeHTTP_RequestAuthenticator(t *testing.T) {
+	p, received := authTestReceiver(t,
+		WithRequestAuthenticator(RequestAuthenticatorFunc(func(req *http.Request) (*Principal, error) {
+			switch req.Header.Get("Authorization") {
+			case "Bearer anonymous":
+				return nil, nil
+			case "Bearer user":
+				return &Principal{Type: extensions.AuthTypeUser, ID: "user-1", Claims: map[string]interface{}{"role": "admin"}}, nil
+			case "Bearer banned":
+				return nil, NewResult(http.StatusForbidden, "banned")
+			}
+			return nil, ErrUnauthenticated
+		})),
+		WithAuthContextExtension(),
+	)
+
+	newEvent := func(id string) event.Event {
+		e := newBatchTestEvents(id)[0]
+		// Senders cannot forge the authcontext extension.
+		e.SetExtension(extensions.AuthTypeExtensionKey, extensions.AuthTypeSystem)
+		e.SetExtension(extensions.AuthIDExtensionKey, "root")
+		return e
+	}
+	requests := map[string]func() *http.Request{
+		"binary": func() *http.Request {
+			req, err := NewHTTPRequestFromEvent(context.Background(), "http://unittest", newEvent("binary"))
+			require.NoError(t, err)
+			return req
+		},
+		"structured": func() *http.Request {
+			e := newEvent("structured")
+			b, err := json.Marshal(e)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "http://unittest", bytes.NewReader(b))
+			req.Header.Set(ContentType, event.ApplicationCloudEventsJSON)
+			return req
+		},
+		"batch": func() *http.Request {
+			req, err := NewHTTPRequestFromEvents(context.Background(), "http://unittest", []event.Event{newEvent("batch")})
+			require.NoError(t, err)
+			return req
+		},
+	}
+	for n, newRequest := range requests {
+		t.Run(n, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			p.ServeHTTP(rec, newRequest())
+			require.Equal(t, http.StatusUnauthorized, rec.Code)
+			require.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+
+			req := newRequest()
+			req.Header.Set("Authorization", "Bearer banned")
+			rec = httptest.NewRecorder()
+			p.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusForbidden, rec.Code)
+
+			req = newRequest()
+			req.Header.Set("Authorization", "Bearer user")
+			rec = httptest.NewRecorder()
+			p.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			e := <-received
+			require.Equal(t, n, e.ID())
+			a, ok := extensions.GetAuthContext(*e)
+			require.True(t, ok)
+			require.Equal(t, extensions.AuthContextExtension{
+				AuthType:   extensions.AuthTypeUser,
+				AuthID:     "user-1",
+				AuthClaims: `{"role":"admin"}`,
+			}, a)
+			require.Equal(t, "user-1", e.Extensions()["testprincipal"])
+
+			req = newRequest()
+			req.Header.Set("Authorization", "Bearer anonymous")
+			rec = httptest.NewRecorder()
+			p.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			e = <-received
+			require.Equal(t, n, e.ID())
+			_, ok = extensions.GetAuthContext(*e)
+			require.False(t, ok)
+		})
+	}
+}
+
+func TestSendWithAuthenticator(t *testing.T) {
+	tokens := newTokenServer(t, 3600)
+	receiver, received := authTestReceiver(t, WithRequestAuthenticator(RequestAuthenticatorFunc(func(req *http.Request) (*Principal, error) {
+		// Only the second token is valid.
+		if req.Header.Get("Authorization") != "Bearer token-2" {
+			return nil, ErrUnauthenticated
+		}
+		return &Principal{ID: "client"}, nil
+	})))
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	p, err := New(WithTarget(server.URL), WithAuthenticator(&ClientCredentials{
+		TokenURL:       tokens.URL,
+		ClientID:       "client",
+		ClientSecret:   "secret",
+		Scopes:         []string{"events.write", "events.read"},
+		EndpointParams: map[string][]string{"audience": {"https://events"}},
+	}))
+	require.NoError(t, err)
+
+	// The rejected token is dropped, and a new one is used by the next request.
+	err = p.Send(context.Background(), newWebhookTestEvent())
+	var result *Result
+	require.True(t, protocol.ResultAs(err, &result), "got %v", err)
+	require.Equal(t, http.StatusUnauthorized, result.StatusCode)
+
+	err = p.Send(context.Background(), newWebhookTestEvent())
+	require.True(t, protocol.IsACK(err), "got %v", err)
+	require.Equal(t, "client", (<-received).Extensions()["testprincipal"])
+	require.EqualValues(t, 2, tokens.requests)
+}
+
+func TestServeHTTP_RequestAuthenticatorNack(t *testing.T) {
+	p, err := New(
+		WithRequestAuthenticator(BearerToken("secret")),
+		WithAuthContextExtension(),
+	)
+	require.NoError(t, err)
+	go func() {
+		m, fn, err := p.Respond(context.Background())
+		if err != nil {
+			return
+		}
+		_ = m.Finish(errors.New("nack"))
+		_ = fn(context.Background(), nil, nil)
+	}()
+
+	b, err := json.Marshal(newBatchTestEvents("structured")[0])
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "http://unittest", bytes.NewReader(b))
+	req.Header.Set(ContentType, event.ApplicationCloudEventsJSON)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+}
@@ -0,0 +1,224 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cloudevents/sdk-go/v2/binding/compression"
+)
+
+const (
+	ContentEncoding = "Content-Encoding"
+	AcceptEncoding  = "Accept-Encoding"
+)
+
+const identity = "identity"
+
+// DefaultMaxDecompressedBytes is the limit of the size of decompressed bodies,
+// for responses and for requests received without WithMaxBodyBytes.
+const DefaultMaxDecompressedBytes = 32 << 20
+
+// maxDecompressedBytes is DefaultMaxDecompressedBytes, lowered by tests.
+var maxDecompressedBytes int64 = DefaultMaxDecompressedBytes
+
+// compressRequest compresses the body of req with the codec of the Protocol,
+// if it is at least the threshold, and lets the response be compressed with
+// any registered codec.
+func (p *Protocol) compressRequest(req *http.Request) error {
+	if p.compression == nil {
+		return nil
+	}
+	if req.Header.Get(AcceptEncoding) == "" {
+		req.Header.Set(AcceptEncoding, strings.Join(compression.Encodings(), ", "))
+	}
+	if req.Body == nil || req.Body == http.NoBody || req.Header.Get(ContentEncoding) != "" ||
+		(req.ContentLength >= 0 && req.ContentLength < int64(p.compressionThreshold)) {
+		return nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	_ = req.Body.Close()
+	if len(body) >= p.compressionThreshold {
+		if body, err = compression.Compress(p.compression, body); err != nil {
+			return err
+		}
+		req.Header.Set(ContentEncoding, p.compression.Encoding())
+	}
+	req.ContentLength = int64(len(body))
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return nil
+}
+
+// contentCodec returns the codec of the Content-Encoding header, nil for
+// uncompressed content, or false if the encoding is not supported.
+func contentCodec(header http.Header) (compression.Codec, bool) {
+	encoding := strings.TrimSpace(header.Get(ContentEncoding))
+	if encoding == "" || strings.EqualFold(encoding, identity) {
+		return nil, true
+	}
+	c := compression.Lookup(encoding)
+	return c, c != nil
+}
+
+// decodeRequest replaces the body of req with its decompressed content,
+// limited to maxBytes if positive, or else to DefaultMaxDecompressedBytes, or
+// rejects req with 415 Unsupported Media Type, listing the supported
+// encodings, and returns false.
+func decodeRequest(rw http.ResponseWriter, req *http.Request, maxBytes int64) bool {
+	c, ok := contentCodec(req.Header)
+	if !ok {
+		supported := strings.Join(compression.Encodings(), ", ")
+		rw.Header().Set(AcceptEncoding, supported)
+		http.Error(rw, fmt.Sprintf("Unsupported content encoding %q, supported encodings: %s", req.Header.Get(ContentEncoding), supported), http.StatusUnsupportedMediaType)
+		return false
+	}
+	if c == nil {
+		return true
+	}
+	if maxBytes <= 0 {
+		maxBytes = maxDecompressedBytes
+	}
+	req.Header = req.Header.Clone()
+	req.Header.Del(ContentEncoding)
+	req.ContentLength = -1
+	req.Body = http.MaxBytesReader(rw, &decodingReader{codec: c, body: req.Body}, maxBytes)
+	return true
+}
+
+// decodeResponse replaces the body of resp with its decompressed content,
+// reading more than DefaultMaxDecompressedBytes failing with
+// compression.ErrTooLarge. Responses with an unsupported encoding are not
+// modified.
+func decodeResponse(resp *http.Response) {
+	c, _ := contentCodec(resp.Header)
+	if c == nil {
+		return
+	}
+	resp.Header = resp.Header.Clone()
+	resp.Header.Del(ContentEncoding)
+	resp.ContentLength = -1
+	r := &decodingReader{codec: c, body: resp.Body}
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{compression.LimitReader(r, maxDecompressedBytes), r}
+}
+
+// decodingReader decompresses body with codec, once first read, so that
+// creating it does not block on reading the body.
+type decodingReader struct {
+	codec  compression.Codec
+	body   io.ReadCloser
+	closer io.Closer
+	reader io.Reader
+	err    error
+}
+
+func (r *decodingReader) Read(p []byte) (int, error) {
+	if r.reader == nil && r.err == nil {
+		var reader io.ReadCloser
+		if reader, r.err = r.codec.NewReader(r.body); r.err == nil {
+			r.closer = reader
+			r.reader = reader
+		}
+	}
+	if r.err != nil {
+		return 0, r.err
+	}
+	return r.reader.Read(p)
+}
+
+func (r *decodingReader) Close() error {
+	if r.closer != nil {
+		_ = r.closer.Close()
+	}
+	return r.body.Close()
+}
+
+// acceptsEncoding returns whether the Accept-Encoding header accepts
+// encoding, explicitly or with "*".
+func acceptsEncoding(acceptEncoding, encoding string) bool {
+	accepted := false
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		token, params, _ := strings.Cut(part, ";")
+		token = strings.TrimSpace(token)
+		q := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			var err error
+			if q, err = strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil {
+				continue
+			}
+		}
+		switch {
+		case strings.EqualFold(token, encoding):
+			// An explicit quality overrides "*".
+			return q > 0
+		case token == "*":
+			accepted = q > 0
+		}
+	}
+	return accepted
+}
+
+// bufferedResponseWriter buffers a response, to compress it once its size is
+// known.
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) Header() http.Header { return w.header }
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// writeCompressed writes the buffered response to rw, compressing its body
+// with c if it is at least threshold bytes.
+func (w *bufferedResponseWriter) writeCompressed(rw http.ResponseWriter, c compression.Codec, threshold int) error {
+	body := w.body.Bytes()
+	w.header.Add("Vary", AcceptEncoding)
+	if len(body) > 0 && len(body) >= threshold && w.header.Get(ContentEncoding) == "" {
+		compressed, err := compression.Compress(c, body)
+		if err != nil {
+			return err
+		}
+		body = compressed
+		w.header.Set(ContentEncoding, c.Encoding())
+		w.header.Del(ContentLength)
+		w.header.Set(ContentLength, strconv.Itoa(len(body)))
+	}
+	for k, v := range w.header {
+		rw.Header()[k] = v
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	rw.WriteHeader(w.status)
+	_, err := rw.Write(body)
+	return err
+}
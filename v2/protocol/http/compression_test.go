@@ -0,0 +1,270 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/compression"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+func TestCompression(t *testing.T) {
+	receiver, err := New(WithCompression(compression.Gzip, 500))
+	require.NoError(t, err)
+	requests := make(chan *http.Request, 1)
+	responses := make(chan http.Header, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests <- req.Clone(req.Context())
+		receiver.ServeHTTP(rw, req)
+		responses <- rw.Header().Clone()
+	}))
+	defer server.Close()
+	go func() {
+		for {
+			m, fn, err := receiver.Respond(context.Background())
+			if err != nil {
+				return
+			}
+			// Reply with the event received.
+			e, err := binding.ToEvent(context.Background(), m)
+			_ = m.Finish(err)
+			if err != nil {
+				_ = fn(context.Background(), nil, err)
+				continue
+			}
+			_ = fn(context.Background(), binding.ToMessage(e), nil)
+		}
+	}()
+
+	sender, err := New(WithTarget(server.URL), WithCompression(compression.Gzip, 500))
+	require.NoError(t, err)
+
+	large := test.MinEvent()
+	require.NoError(t, large.SetData(event.ApplicationJSON, map[string]string{"text": strings.Repeat("a", 1000)}))
+	small := test.MinEvent()
+	require.NoError(t, small.SetData(event.ApplicationJSON, "small"))
+
+	tests := map[string]struct {
+		ctx      context.Context
+		e        event.Event
+		encoding string
+	}{
+		"binary":           {binding.WithForceBinary(context.Background()), large, "gzip"},
+		"structured":       {binding.WithForceStructured(context.Background()), large, "gzip"},
+		"below threshold":  {binding.WithForceBinary(context.Background()), small, ""},
+		"structured small": {binding.WithForceStructured(context.Background()), small, ""},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			resp, err := sender.Request(tc.ctx, binding.ToMessage(&tc.e))
+			req := <-requests
+			require.True(t, protocol.IsACK(err))
+			require.Equal(t, tc.encoding, req.Header.Get(ContentEncoding))
+			require.Equal(t, "gzip", req.Header.Get(AcceptEncoding))
+
+			// The response is compressed as accepted by the request, and
+			// decompressed by the sender.
+			require.Equal(t, tc.encoding, (<-responses).Get(ContentEncoding))
+			m := resp.(*Message)
+			require.Empty(t, m.Header.Get(ContentEncoding))
+			got := test.MustToEvent(t, context.Background(), m)
+			test.AssertEventEquals(t, tc.e, got)
+		})
+	}
+}
+
+// setMaxDecompressedBytes lowers the default limit of decompressed bodies for
+// the duration of the test.
+func setMaxDecompressedBytes(t *testing.T, n int64) {
+	old := maxDecompressedBytes
+	maxDecompressedBytes = n
+	t.Cleanup(func() { maxDecompressedBytes = old })
+}
+
+func TestMaxDecompressedBytes(t *testing.T) {
+	require.Equal(t, int64(DefaultMaxDecompressedBytes), maxDecompressedBytes)
+}
+
+func TestServeCompressed(t *testing.T) {
+	setMaxDecompressedBytes(t, 4<<10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p, err := New(WithCompression(compression.Gzip, 0), WithMaxBodyBytes(512))
+	require.NoError(t, err)
+	errs := make(chan error, 1)
+	events := make(chan *event.Event, 1)
+	go func() {
+		for {
+			m, fn, err := p.Respond(ctx)
+			if err != nil {
+				return
+			}
+			e, err := binding.ToEvent(context.Background(), m)
+			errs <- err
+			events <- e
+			_ = fn(context.Background(), nil, err)
+			_ = m.Finish(err)
+		}
+	}()
+
+	newRequest := func(encoding string, n int) *http.Request {
+		e := test.MinEvent()
+		require.NoError(t, e.SetData(event.TextPlain, strings.Repeat("x", n)))
+		req, err := NewHTTPRequestFromEvent(context.Background(), "http://unittest", e)
+		require.NoError(t, err)
+		if c := compression.Lookup(encoding); c != nil {
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			body, err = compression.Compress(c, body)
+			require.NoError(t, err)
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			req.ContentLength = int64(len(body))
+		}
+		req.Header.Set(ContentEncoding, encoding)
+		return req
+	}
+
+	for _, encoding := range []string{"gzip", "identity"} {
+		rec := httptest.NewRecorder()
+		p.ServeHTTP(rec, newRequest(encoding, 256))
+		require.Equal(t, http.StatusOK, rec.Code, encoding)
+		require.NoError(t, <-errs)
+		require.Equal(t, strings.Repeat("x", 256), string((<-events).Data()))
+	}
+
+	// The decompressed size is limited too.
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, newRequest("gzip", 1024))
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	var maxBytesErr *http.MaxBytesError
+	require.ErrorAs(t, <-errs, &maxBytesErr)
+	<-events
+
+	// Without WithMaxBodyBytes, the decompressed size is limited by default.
+	p2, err := New(WithCompression(compression.Gzip, 0))
+	require.NoError(t, err)
+	go func() {
+		m, fn, err := p2.Respond(ctx)
+		if err != nil {
+			return
+		}
+		_, err = binding.ToEvent(context.Background(), m)
+		errs <- err
+		_ = fn(context.Background(), nil, err)
+		_ = m.Finish(err)
+	}()
+	rec = httptest.NewRecorder()
+	p2.ServeHTTP(rec, newRequest("gzip", int(maxDecompressedBytes)+1))
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	require.ErrorAs(t, <-errs, &maxBytesErr)
+
+	rec = httptest.NewRecorder()
+	p2.ServeHTTP(rec, newRequest("br", 16))
+	require.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	require.Equal(t, "gzip", rec.Header().Get(AcceptEncoding))
+}
+
+func TestServeCompressed_withoutCompression(t *testing.T) {
+	p, err := New()
+	require.NoError(t, err)
+	bodies := make(chan []byte, 1)
+	go func() {
+		for {
+			m, fn, err := p.Respond(context.Background())
+			if err != nil {
+				return
+			}
+			e, err := binding.ToEvent(context.Background(), m)
+			if err == nil {
+				bodies <- e.Data()
+			}
+			_ = fn(context.Background(), nil, err)
+			_ = m.Finish(err)
+		}
+	}()
+
+	// The body of requests is passed through, whatever their encoding.
+	for _, encoding := range []string{"gzip", "br"} {
+		e := test.MinEvent()
+		require.NoError(t, e.SetData(event.TextPlain, "compressed"))
+		req, err := NewHTTPRequestFromEvent(context.Background(), "http://unittest", e)
+		require.NoError(t, err)
+		req.Header.Set(ContentEncoding, encoding)
+		rec := httptest.NewRecorder()
+		p.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, encoding)
+		require.Equal(t, "compressed", string(<-bodies))
+	}
+}
+
+func TestDecodeResponse(t *testing.T) {
+	setMaxDecompressedBytes(t, 4<<10)
+	newResponse := func(encoding string, body []byte) *http.Response {
+		header := http.Header{}
+		header.Set(ContentEncoding, encoding)
+		return &http.Response{Header: header, Body: io.NopCloser(bytes.NewReader(body)), ContentLength: int64(len(body))}
+	}
+
+	body, err := compression.Compress(compression.Gzip, []byte("hello"))
+	require.NoError(t, err)
+	resp := newResponse("gzip", body)
+	decodeResponse(resp)
+	require.Empty(t, resp.Header.Get(ContentEncoding))
+	require.Equal(t, int64(-1), resp.ContentLength)
+	got, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(got))
+	require.NoError(t, resp.Body.Close())
+
+	// Unsupported encodings are not modified.
+	resp = newResponse("br", []byte("hello"))
+	decodeResponse(resp)
+	require.Equal(t, "br", resp.Header.Get(ContentEncoding))
+	got, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(got))
+
+	// The decompressed size is limited.
+	body, err = compression.Compress(compression.Gzip, make([]byte, maxDecompressedBytes+1))
+	require.NoError(t, err)
+	resp = newResponse("gzip", body)
+	decodeResponse(resp)
+	_, err = io.ReadAll(resp.Body)
+	require.ErrorIs(t, err, compression.ErrTooLarge)
+}
+
+func TestAcceptsEncoding(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"gzip", true},
+		{"GZIP", true},
+		{"br, gzip;q=0.5", true},
+		{"gzip;q=0", false},
+		{"*", true},
+		{"*;q=0, gzip", true},
+		{"*, gzip;q=0", false},
+		{"br", false},
+		{"", false},
+	}
+	for _, tc := range tests {
+		require.Equal(t, tc.want, acceptsEncoding(tc.accept, "gzip"), tc.accept)
+	}
+}
@@ -0,0 +1,116 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+)
+
+// unsupportedFormat returns the media type of req if it is a structured
+// CloudEvents format that is not registered in the format package.
+func unsupportedFormat(req *http.Request) (string, bool) {
+	if req.Header.Get(prefix+"Specversion") != "" {
+		// Binary mode, the content type is the one of the data.
+		return "", false
+	}
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get(ContentType))
+	if err != nil || !format.IsFormat(mediaType) {
+		return "", false
+	}
+	return mediaType, format.Lookup(mediaType) == nil
+}
+
+// writeUnsupportedFormat rejects a request with an unsupported structured
+// format with 415 Unsupported Media Type, listing the supported ones.
+func writeUnsupportedFormat(rw http.ResponseWriter, mediaType string) {
+	supported := strings.Join(format.MediaTypes(), ", ")
+	rw.Header().Set("Accept", supported)
+	http.Error(rw, fmt.Sprintf("Unsupported event format %q, supported formats: %s", mediaType, supported), http.StatusUnsupportedMediaType)
+}
+
+// isMaxBytesError returns whether err was caused by reading more than the
+// limit set with WithMaxBodyBytes.
+func isMaxBytesError(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+type acceptedMediaType struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept returns the media types of an Accept header with a non zero
+// quality, by decreasing quality.
+func parseAccept(accept string) []acceptedMediaType {
+	var accepted []acceptedMediaType
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil || q <= 0 {
+				continue
+			}
+		}
+		accepted = append(accepted, acceptedMediaType{mediaType: mediaType, q: q})
+	}
+	sort.SliceStable(accepted, func(i, j int) bool { return accepted[i].q > accepted[j].q })
+	return accepted
+}
+
+// negotiateEncoding picks the encoding of a response from the Accept header
+// of the request:
+//   - structured, in the preferred registered format, if a CloudEvents format
+//     is preferred;
+//   - binary, if a concrete media type is preferred, as the body of a binary
+//     response is the data of the event;
+//   - EncodingUnknown if there is no preference, such as with "*/*".
+//
+// Unsupported CloudEvents formats are ignored.
+func negotiateEncoding(accept string) (binding.Encoding, format.Format) {
+	for _, a := range parseAccept(accept) {
+		switch {
+		case format.IsFormat(a.mediaType):
+			if f := format.Lookup(a.mediaType); f != nil && f != format.JSONBatch {
+				return binding.EncodingStructured, f
+			}
+		case strings.HasSuffix(a.mediaType, "/*"):
+			return binding.EncodingUnknown, nil
+		default:
+			return binding.EncodingBinary, nil
+		}
+	}
+	return binding.EncodingUnknown, nil
+}
+
+// withNegotiatedEncoding configures ctx to write a response message in the
+// encoding negotiated with accept. Without a preference, the encoding of the
+// message is kept.
+func withNegotiatedEncoding(ctx context.Context, accept string) context.Context {
+	enc, f := negotiateEncoding(accept)
+	switch enc {
+	case binding.EncodingStructured:
+		// A structured message might be in another format.
+		ctx = binding.WithSkipDirectStructuredEncoding(binding.WithForceStructured(ctx), true)
+		return binding.UseFormatForEvent(ctx, f)
+	case binding.EncodingBinary:
+		return binding.WithForceBinary(ctx)
+	}
+	return ctx
+}
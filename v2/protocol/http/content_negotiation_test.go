@@ -0,0 +1,160 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+const testFormatMediaType = "application/cloudevents+test"
+
+// testFormat is JSON with a prefix.
+type testFormat struct{}
+
+func (testFormat) MediaType() string { return testFormatMediaType }
+
+func (testFormat) Marshal(e *event.Event) ([]byte, error) {
+	b, err := json.Marshal(e)
+	return append([]byte("test:"), b...), err
+}
+
+func (testFormat) Unmarshal(b []byte, e *event.Event) error {
+	return json.Unmarshal(bytes.TrimPrefix(b, []byte("test:")), e)
+}
+
+func init() {
+	format.Add(testFormat{})
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	testCases := map[string]struct {
+		accept     string
+		wantEnc    binding.Encoding
+		wantFormat format.Format
+	}{
+		"none":                {accept: "", wantEnc: binding.EncodingUnknown},
+		"any":                 {accept: "*/*", wantEnc: binding.EncodingUnknown},
+		"any application":     {accept: "application/*", wantEnc: binding.EncodingUnknown},
+		"json format":         {accept: "application/cloudevents+json", wantEnc: binding.EncodingStructured, wantFormat: format.JSON},
+		"case insensitive":    {accept: "Application/CloudEvents+JSON; charset=utf-8", wantEnc: binding.EncodingStructured, wantFormat: format.JSON},
+		"registered format":   {accept: testFormatMediaType, wantEnc: binding.EncodingStructured, wantFormat: testFormat{}},
+		"data":                {accept: "application/json", wantEnc: binding.EncodingBinary},
+		"preferred format":    {accept: "application/json;q=0.5, application/cloudevents+json", wantEnc: binding.EncodingStructured, wantFormat: format.JSON},
+		"preferred data":      {accept: "application/cloudevents+json;q=0.2, application/json;q=0.8", wantEnc: binding.EncodingBinary},
+		"unsupported format":  {accept: "application/cloudevents+avro, application/json;q=0.5", wantEnc: binding.EncodingBinary},
+		"not acceptable":      {accept: "application/cloudevents+json;q=0", wantEnc: binding.EncodingUnknown},
+		"batch":               {accept: "application/cloudevents-batch+json", wantEnc: binding.EncodingUnknown},
+		"invalid media types": {accept: "/, ;", wantEnc: binding.EncodingUnknown},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			enc, f := negotiateEncoding(tc.accept)
+			require.Equal(t, tc.wantEnc, enc)
+			require.Equal(t, tc.wantFormat, f)
+		})
+	}
+}
+
+func TestServeHTTP_UnsupportedFormat(t *testing.T) {
+	p, err := New()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "http://unittest", strings.NewReader(`{}`))
+	req.Header.Set(ContentType, "application/cloudevents+avro")
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	require.Contains(t, rec.Header().Get("Accept"), event.ApplicationCloudEventsJSON)
+	require.Contains(t, rec.Body.String(), `"application/cloudevents+avro"`)
+	require.Contains(t, rec.Body.String(), testFormatMediaType)
+}
+
+func TestServeHTTP_ResponseNegotiation(t *testing.T) {
+	reply := event.New()
+	reply.SetID("reply")
+	reply.SetSource("/responder")
+	reply.SetType("reply")
+	require.NoError(t, reply.SetData(event.ApplicationJSON, map[string]string{"hello": "world"}))
+	replyJSON, err := json.Marshal(reply)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		accept string
+		// structuredReply replies with a structured JSON message instead of
+		// an event.
+		structuredReply bool
+		wantContentType string
+		wantBody        string
+	}{
+		"no preference": {
+			wantContentType: event.ApplicationJSON,
+			wantBody:        `{"hello":"world"}`,
+		},
+		"no preference keeps structured": {
+			structuredReply: true,
+			wantContentType: event.ApplicationCloudEventsJSON,
+			wantBody:        string(replyJSON),
+		},
+		"structured": {
+			accept:          event.ApplicationCloudEventsJSON,
+			wantContentType: event.ApplicationCloudEventsJSON,
+			wantBody:        string(replyJSON),
+		},
+		"structured in another format": {
+			accept:          testFormatMediaType,
+			structuredReply: true,
+			wantContentType: testFormatMediaType,
+			wantBody:        "test:" + string(replyJSON),
+		},
+		"binary": {
+			accept:          "application/json",
+			structuredReply: true,
+			wantContentType: event.ApplicationJSON,
+			wantBody:        `{"hello":"world"}`,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			p, err := New()
+			require.NoError(t, err)
+			go func() {
+				m, fn, err := p.Respond(context.Background())
+				require.NoError(t, err)
+				var respMsg binding.Message = binding.ToMessage(&reply)
+				if tc.structuredReply {
+					respMsg = NewMessage(http.Header{ContentType: {event.ApplicationCloudEventsJSON}}, io.NopCloser(bytes.NewReader(replyJSON)))
+				}
+				_ = fn(context.Background(), respMsg, nil)
+				_ = m.Finish(nil)
+			}()
+
+			req, err := NewHTTPRequestFromEvent(context.Background(), "http://unittest", newBatchTestEvents("a")[0])
+			require.NoError(t, err)
+			req.Header.Set("Accept", tc.accept)
+			rec := httptest.NewRecorder()
+			p.ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusOK, rec.Code)
+			require.Equal(t, tc.wantContentType, rec.Header().Get(ContentType))
+			require.JSONEq(t, strings.TrimPrefix(tc.wantBody, "test:"), strings.TrimPrefix(rec.Body.String(), "test:"))
+			require.Equal(t, strings.HasPrefix(tc.wantBody, "test:"), strings.HasPrefix(rec.Body.String(), "test:"))
+		})
+	}
+}
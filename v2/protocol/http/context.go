@@ -7,6 +7,7 @@ package http
 
 import (
 	"context"
+	"crypto/x509"
 
 	nethttp "net/http"
 	"net/url"
@@ -14,6 +15,10 @@ import (
 
 type requestKey struct{}
 
+type principalKey struct{}
+
+type clientCertificateKey struct{}
+
 // RequestData holds the http.Request information subset that can be
 // used to retrieve HTTP information for an incoming CloudEvent.
 type RequestData struct {
@@ -46,3 +51,34 @@ func RequestDataFromContext(ctx context.Context) *RequestData {
 	}
 	return nil
 }
+
+// withPrincipal adds the authenticated Principal of a request to the Context.
+func withPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext retrieves the Principal authenticated by the
+// RequestAuthenticator of the Protocol from the Context. If not set nil is
+// returned.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	if p, ok := ctx.Value(principalKey{}).(*Principal); ok {
+		return p
+	}
+	return nil
+}
+
+// withClientCertificate adds the verified TLS client certificate of a request
+// to the Context.
+func withClientCertificate(ctx context.Context, cert *x509.Certificate) context.Context {
+	return context.WithValue(ctx, clientCertificateKey{}, cert)
+}
+
+// ClientCertificateFromContext retrieves the TLS client certificate of the
+// request, as verified with the CAs of WithMutualTLS, from the Context. If not
+// set nil is returned.
+func ClientCertificateFromContext(ctx context.Context) *x509.Certificate {
+	if cert, ok := ctx.Value(clientCertificateKey{}).(*x509.Certificate); ok {
+		return cert
+	}
+	return nil
+}
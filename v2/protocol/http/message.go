@@ -87,6 +87,28 @@ func NewMessageFromHttpResponse(resp *nethttp.Response) *Message {
 	return msg
 }
 
+// MessageHeader returns a copy of the HTTP headers of m, or of the message it
+// wraps, or else of the request in its context, or nil. It can be used as the
+// metadata function of the record package.
+func MessageHeader(m binding.Message) map[string][]string {
+	for m != nil {
+		if mt, ok := m.(*Message); ok {
+			return mt.Header.Clone()
+		}
+		if mctx, ok := m.(binding.MessageContext); ok {
+			if req := RequestDataFromContext(mctx.Context()); req != nil {
+				return req.Header.Clone()
+			}
+		}
+		w, ok := m.(binding.MessageWrapper)
+		if !ok {
+			return nil
+		}
+		m = w.GetWrappedMessage()
+	}
+	return nil
+}
+
 func (m *Message) ReadEncoding() binding.Encoding {
 	if m.version != nil {
 		return binding.EncodingBinary
@@ -117,7 +139,7 @@ func (m *Message) ReadBinary(ctx context.Context, encoder binding.BinaryWriter)
 		attr := m.version.Attribute(k)
 		if attr != nil {
 			err = encoder.SetAttribute(attr, v[0])
-		} else if strings.HasPrefix(k, prefix) {
+		} else if strings.HasPrefix(k, prefix) && len(k) > len(prefix) {
 			// Trim Prefix + To lower
 			var b strings.Builder
 			b.Grow(len(k) - len(prefix))
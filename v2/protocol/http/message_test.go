@@ -6,6 +6,7 @@
 package http
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"errors"
@@ -194,3 +195,45 @@ func TestNewMessageFromHttpResponse(t *testing.T) {
 		})
 	}
 }
+
+// FuzzNewMessageFromHttpRequest verifies that parsing any request does not
+// panic, and that the valid events it parses round trip once normalized by a
+// first write.
+func FuzzNewMessageFromHttpRequest(f *testing.F) {
+	for i, e := range test.GenerateEvents(1, 50) {
+		ctx := binding.WithForceBinary(context.Background())
+		if i%2 == 0 {
+			ctx = binding.WithForceStructured(context.Background())
+		}
+		req := httptest.NewRequest("POST", "http://localhost", nil)
+		require.NoError(f, WriteRequest(ctx, binding.ToMessage(&e), req))
+		var b bytes.Buffer
+		require.NoError(f, req.Write(&b))
+		f.Add(b.Bytes())
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(b)))
+		if err != nil {
+			return
+		}
+		e, err := binding.ToEvent(context.Background(), NewMessageFromHttpRequest(req))
+		if err != nil || e.Validate() != nil {
+			return
+		}
+		test.AssertRoundTrip(t, *e, true, func(e event.Event) event.Event {
+			req := httptest.NewRequest("POST", "http://localhost", nil)
+			require.NoError(t, WriteRequest(context.Background(), binding.ToMessage(&e), req))
+			return test.MustToEvent(t, context.Background(), NewMessageFromHttpRequest(req))
+		})
+	})
+}
+
+func TestMessageHeader(t *testing.T) {
+	header := http.Header{"X-Trace": {"1"}}
+	m := NewMessage(header, nil)
+	require.Equal(t, map[string][]string{"X-Trace": {"1"}}, MessageHeader(m))
+	require.Equal(t, map[string][]string{"X-Trace": {"1"}}, MessageHeader(binding.WithFinish(m, nil)))
+
+	e := test.MinEvent()
+	require.Nil(t, MessageHeader(binding.ToMessage(&e)))
+}
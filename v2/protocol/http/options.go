@@ -6,12 +6,16 @@
 package http
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net"
 	nethttp "net/http"
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding/compression"
 )
 
 // Option is the function signature required to be considered an http.Option.
@@ -143,6 +147,147 @@ func checkListen(p *Protocol, prefix string) error {
 	return nil
 }
 
+// WithH2C enables HTTP/2 without TLS (h2c) on the http server, next to
+// HTTP/1, for environments where TLS is terminated by a proxy such as a
+// service mesh sidecar. Only prior knowledge h2c is supported, not the upgrade
+// from HTTP/1.1.
+func WithH2C() Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http h2c option can not set nil protocol")
+		}
+		p.h2c = true
+		return nil
+	}
+}
+
+// WithTLSConfig serves TLS with config. HTTP/2 is negotiated with ALPN unless
+// config.NextProtos says otherwise. The Protocol being an http.Handler, it can
+// also be served over HTTP/3 by a QUIC server sharing config. It replaces the
+// TLS configuration of the options before it, such as WithMutualTLS.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http TLS config option can not set nil protocol")
+		}
+		if config == nil {
+			return fmt.Errorf("http TLS config option was given a nil config")
+		}
+		p.tlsConfig = config.Clone()
+		return nil
+	}
+}
+
+// WithTLSCertificateFiles serves TLS with the PEM encoded certificate and key
+// at certFile and keyFile, reloading them when they change, at most every
+// DefaultCertificateCheckInterval. Use WithTLSCertificateReloader to check
+// them at another interval.
+func WithTLSCertificateFiles(certFile, keyFile string) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http TLS certificate option can not set nil protocol")
+		}
+		r, err := NewCertificateReloader(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+		p.tlsConfigOrNew().GetCertificate = r.GetCertificate
+		return nil
+	}
+}
+
+// WithTLSCertificateReloader serves TLS with the certificate of r.
+func WithTLSCertificateReloader(r *CertificateReloader) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http TLS certificate option can not set nil protocol")
+		}
+		if r == nil {
+			return fmt.Errorf("http TLS certificate option was given a nil reloader")
+		}
+		p.tlsConfigOrNew().GetCertificate = r.GetCertificate
+		return nil
+	}
+}
+
+// WithMutualTLS requires clients to present a TLS certificate signed by one
+// of clientCAs. The certificate of the client is available to receivers with
+// ClientCertificateFromContext. It must be used with WithTLSConfig or
+// WithTLSCertificateFiles.
+func WithMutualTLS(clientCAs *x509.CertPool) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http mutual TLS option can not set nil protocol")
+		}
+		if clientCAs == nil {
+			return fmt.Errorf("http mutual TLS option was given nil client CAs")
+		}
+		config := p.tlsConfigOrNew()
+		config.ClientCAs = clientCAs
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		return nil
+	}
+}
+
+// WithMaxHeaderBytes limits the size of the request headers read by the http
+// server, see http.Server.MaxHeaderBytes.
+func WithMaxHeaderBytes(n int) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http max header bytes option can not set nil protocol")
+		}
+		if n <= 0 {
+			return fmt.Errorf("http max header bytes option was given an invalid size: %d", n)
+		}
+		p.maxHeaderBytes = n
+		return nil
+	}
+}
+
+// WithMaxBodyBytes limits the size of the request bodies read by the
+// Protocol. Requests with a larger Content-Length are rejected with 413
+// Request Entity Too Large before their body is read. For the others, reading
+// more fails and the response is also 413, see http.MaxBytesReader. With
+// WithCompression, the limit applies to compressed bodies both before and
+// after decompression, which is otherwise limited to
+// DefaultMaxDecompressedBytes.
+func WithMaxBodyBytes(n int64) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http max body bytes option can not set nil protocol")
+		}
+		if n <= 0 {
+			return fmt.Errorf("http max body bytes option was given an invalid size: %d", n)
+		}
+		p.maxBodyBytes = n
+		return nil
+	}
+}
+
+// WithCompression compresses, with c, the bodies of at least threshold bytes
+// of the requests sent, and of the responses to the requests accepting c in
+// their Accept-Encoding header. The requests sent accept all the encodings
+// registered in the binding/compression package for their responses.
+// Compressed requests and responses are decompressed, up to
+// DefaultMaxDecompressedBytes, see WithMaxBodyBytes. Without this option, the
+// bodies received are not decompressed.
+func WithCompression(c compression.Codec, threshold int) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http compression option can not set nil protocol")
+		}
+		if c == nil {
+			return fmt.Errorf("http compression option was given a nil codec")
+		}
+		if threshold < 0 {
+			return fmt.Errorf("http compression option was given an invalid threshold: %d", threshold)
+		}
+		p.compression = c
+		p.compressionThreshold = threshold
+		return nil
+	}
+}
+
 // WithPort sets the listening port for StartReceiver.
 // Only one of WithListener or WithPort is allowed.
 func WithPort(port int) Option {
@@ -310,6 +455,148 @@ func WithDefaultOptionsHandlerFunc(methods []string, rate int, origins []string,
 	}
 }
 
+// WithBatchHandlerFunc sets the handler func of batched requests. If not set,
+// the events of a batch are received one by one.
+func WithBatchHandlerFunc(fn BatchHandlerFunc) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http batch handler func can not set nil protocol")
+		}
+		p.BatchHandlerFn = fn
+		return nil
+	}
+}
+
+// WithWebhookValidation performs the abuse protection handshake with each
+// target before the first delivery to it, sending origin as
+// WebHook-Request-Origin, which is also set on the deliveries. Deliveries to a
+// target that refuses the handshake fail with ErrWebhookValidationFailed. Deliveries to a target that returned a
+// WebHook-Allowed-Rate wait to stay within it. Successful handshakes are trusted for
+// DefaultWebhookValidationTTL, use WithWebhookValidator for more control.
+func WithWebhookValidation(origin string) Option {
+	return WithWebhookValidator(NewWebhookValidator(origin))
+}
+
+// WithWebhookValidator sets the validator performing the abuse protection
+// handshake with each target before delivering to it.
+func WithWebhookValidator(v *WebhookValidator) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http webhook validator can not set nil protocol")
+		}
+		if v == nil || v.Origin == "" {
+			return fmt.Errorf("http webhook validator requires an origin")
+		}
+		p.WebhookValidator = v
+		return nil
+	}
+}
+
+// WithWebhookEnforcement enforces the origins and rate advertised in the
+// OPTIONS response on delivery requests, see WebhookConfig.EnforceOrigin and
+// WebhookConfig.EnforceRate. It must be used after WithDefaultOptionsHandlerFunc.
+// The origins are claimed by the senders: this is not authentication, see
+// WithWebhookVerifier.
+func WithWebhookEnforcement() Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http webhook enforcement can not set nil protocol")
+		}
+		if p.WebhookConfig == nil {
+			return fmt.Errorf("http webhook enforcement requires a webhook config, use WithDefaultOptionsHandlerFunc first")
+		}
+		p.WebhookConfig.EnforceOrigin = true
+		p.WebhookConfig.EnforceRate = true
+		return nil
+	}
+}
+
+// WithWebhookCallbackApprover sets the hook approving validation requests that
+// carry a WebHook-Request-Callback. It must be used after
+// WithDefaultOptionsHandlerFunc.
+func WithWebhookCallbackApprover(fn WebhookCallbackApprover) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http webhook callback approver can not set nil protocol")
+		}
+		if p.WebhookConfig == nil {
+			return fmt.Errorf("http webhook callback approver requires a webhook config, use WithDefaultOptionsHandlerFunc first")
+		}
+		p.WebhookConfig.ApproveCallback = fn
+		return nil
+	}
+}
+
+// WithWebhookSigner signs outgoing requests with the Standard Webhooks
+// signature scheme. The webhook-id is a hash of the source and id of the event
+// when they can be read from the message, as event ids are only unique per
+// source. Retries are signed again, with a new webhook-timestamp.
+func WithWebhookSigner(s *WebhookSigner) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http webhook signer can not set nil protocol")
+		}
+		p.webhookSigner = s
+		return nil
+	}
+}
+
+// WithWebhookVerifier verifies the Standard Webhooks signature of incoming
+// requests, rejecting the invalid ones with 401 Unauthorized.
+func WithWebhookVerifier(v *WebhookVerifier) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http webhook verifier can not set nil protocol")
+		}
+		p.webhookVerifier = v
+		return nil
+	}
+}
+
+// WithAuthenticator adds credentials to outgoing requests with a, such as a
+// BearerToken or ClientCredentials.
+func WithAuthenticator(a Authenticator) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http authenticator can not set nil protocol")
+		}
+		p.authenticator = a
+		return nil
+	}
+}
+
+// WithBearerToken sets a static bearer token on outgoing requests.
+func WithBearerToken(token string) Option {
+	return WithAuthenticator(BearerToken(token))
+}
+
+// WithRequestAuthenticator authenticates incoming requests with a, such as the
+// JWT validator of the github.com/cloudevents/sdk-go/auth/jwt/v2 module.
+// Unauthenticated requests are rejected, and the principal of
+// the others is available with PrincipalFromContext.
+func WithRequestAuthenticator(a RequestAuthenticator) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http request authenticator can not set nil protocol")
+		}
+		p.requestAuthenticator = a
+		return nil
+	}
+}
+
+// WithAuthContextExtension sets the authcontext extension of incoming events
+// to the principal authenticated by the RequestAuthenticator, replacing the
+// one set by the sender, if any.
+func WithAuthContextExtension() Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http authcontext extension can not set nil protocol")
+		}
+		p.authContextExtension = true
+		return nil
+	}
+}
+
 // IsRetriable is a custom function that can be used to override the
 // default retriable status codes.
 type IsRetriable func(statusCode int) bool
@@ -329,6 +616,64 @@ func WithIsRetriableFunc(isRetriable IsRetriable) Option {
 	}
 }
 
+// WithIdempotencyPolicy sets which requests are retried after a transport
+// error, such as a connection reset. If not set, RetryAllRequests is used.
+func WithIdempotencyPolicy(policy IdempotencyPolicy) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http idempotency policy can not set nil protocol")
+		}
+		switch policy {
+		case RetryAllRequests, RetryIdempotentRequests, RetryUnsentRequests:
+		default:
+			return fmt.Errorf("unknown idempotency policy %d", policy)
+		}
+		p.idempotencyPolicy = policy
+		return nil
+	}
+}
+
+// WithTargetPool sets the pool of targets the sender balances its requests
+// between. It takes precedence over WithTarget; a target set on the context
+// with cecontext.WithTarget takes precedence over it. Requests failing on a
+// target are sent to the next one only if they could be retried, as set by
+// WithIsRetriableFunc and WithIdempotencyPolicy.
+func WithTargetPool(pool *TargetPool) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http target pool option can not set nil protocol")
+		}
+		if pool == nil {
+			return fmt.Errorf("http target pool can not be nil")
+		}
+		p.targetPool = pool
+		return nil
+	}
+}
+
+// WithTargets balances the requests of the sender between targets with
+// strategy. See TargetPool.
+func WithTargets(strategy LoadBalancingStrategy, targets ...string) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http targets option can not set nil protocol")
+		}
+		poolTargets := make([]PoolTarget, 0, len(targets))
+		for _, t := range targets {
+			poolTargets = append(poolTargets, PoolTarget{URL: t})
+		}
+		pool, err := NewTargetPool(strategy, poolTargets...)
+		if err != nil {
+			return err
+		}
+		p.targetPool = pool
+		return nil
+	}
+}
+
+// WithRateLimiter sets the rate limiter applied to every inbound request.
+// See TokenBucketLimiter, SlidingWindowLimiter and KeyedRateLimiter for the
+// built-in ones.
 func WithRateLimiter(rl RateLimiter) Option {
 	return func(p *Protocol) error {
 		if p == nil {
@@ -8,6 +8,7 @@ package http
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
@@ -19,6 +20,7 @@ import (
 	"time"
 
 	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/compression"
 	cecontext "github.com/cloudevents/sdk-go/v2/context"
 	"github.com/cloudevents/sdk-go/v2/event"
 	"github.com/cloudevents/sdk-go/v2/protocol"
@@ -30,7 +32,7 @@ const (
 )
 
 type msgErr struct {
-	msg    *Message
+	msg    binding.Message
 	respFn protocol.ResponseFn
 	err    error
 }
@@ -60,10 +62,17 @@ type Protocol struct {
 	// https://github.com/cloudevents/spec/blob/v1.0/http-webhook.md#4-abuse-protection
 	OptionsHandlerFn http.HandlerFunc
 	WebhookConfig    *WebhookConfig
+	// WebhookValidator, if set, performs the abuse protection handshake with
+	// each target before delivering to it.
+	WebhookValidator *WebhookValidator
 
 	GetHandlerFn    http.HandlerFunc
 	DeleteHandlerFn http.HandlerFunc
 
+	// BatchHandlerFn handles batched requests as a whole. If nil, each event
+	// of a batch is received as its own message, see ServeHTTP.
+	BatchHandlerFn BatchHandlerFunc
+
 	// To support Opener:
 
 	// ShutdownTimeout defines the timeout given to the http.Server when calling Shutdown.
@@ -100,8 +109,30 @@ type Protocol struct {
 	handlerRegistered bool
 	middleware        []Middleware
 	limiter           RateLimiter
+	webhookSigner     *WebhookSigner
+	webhookVerifier   *WebhookVerifier
+	authenticator     Authenticator
+
+	// webhookLimiter throttles delivery requests per origin when the
+	// WebhookConfig enforces the rate. It is created on first use.
+	webhookLimiterOnce sync.Once
+	webhookLimiter     RateLimiter
+
+	requestAuthenticator RequestAuthenticator
+	authContextExtension bool
+
+	h2c            bool
+	tlsConfig      *tls.Config
+	maxHeaderBytes int
+	maxBodyBytes   int64
+
+	compression          compression.Codec
+	compressionThreshold int
 
-	isRetriableFunc IsRetriable
+	isRetriableFunc   IsRetriable
+	idempotencyPolicy IdempotencyPolicy
+
+	targetPool *TargetPool
 }
 
 func New(opts ...Option) (*Protocol, error) {
@@ -209,16 +240,67 @@ func (p *Protocol) Request(ctx context.Context, m binding.Message, transformers
 	defer func() { _ = m.Finish(err) }()
 
 	req := p.makeRequest(ctx)
+	usePool := p.targetPool != nil && cecontext.TargetFrom(ctx) == nil
 
-	if p.Client == nil || req == nil || req.URL == nil {
+	if p.Client == nil || req == nil || (req.URL == nil && !usePool) {
 		return nil, fmt.Errorf("not initialized: %#v", p)
 	}
 
 	if err = WriteRequest(ctx, m, req, transformers...); err != nil {
 		return nil, err
 	}
+	if err = p.compressRequest(req); err != nil {
+		return nil, err
+	}
 
-	return p.do(ctx, req)
+	if usePool {
+		return p.requestPool(ctx, m, req)
+	}
+	if err = p.prepareRequest(ctx, m, req); err != nil {
+		return nil, err
+	}
+	return p.doRequest(ctx, req)
+}
+
+// prepareRequest validates the target of req and waits for its allowed rate,
+// then authenticates req and sets its webhook-id.
+func (p *Protocol) prepareRequest(ctx context.Context, m binding.Message, req *http.Request) error {
+	if p.WebhookValidator != nil {
+		if _, err := p.WebhookValidator.Validate(ctx, p.Client, req); err != nil {
+			return err
+		}
+		if err := p.WebhookValidator.throttle(ctx, req.URL); err != nil {
+			return err
+		}
+		// Let the target enforce the validated origin.
+		if req.Header.Get("WebHook-Request-Origin") == "" {
+			req.Header.Set("WebHook-Request-Origin", p.WebhookValidator.Origin)
+		}
+	}
+
+	if p.authenticator != nil {
+		if err := p.authenticator.Authenticate(req); err != nil {
+			return err
+		}
+	}
+
+	if p.webhookSigner != nil {
+		// Each attempt is signed just before being sent, with this id.
+		req.Header.Set(WebhookIDHeader, webhookID(m))
+	}
+	return nil
+}
+
+func (p *Protocol) doRequest(ctx context.Context, req *http.Request) (binding.Message, error) {
+	resp, res := p.do(ctx, req)
+	var result *Result
+	if protocol.ResultAs(res, &result) && result.StatusCode == http.StatusUnauthorized {
+		// Let authenticators caching credentials get new ones.
+		if i, ok := p.authenticator.(interface{ Invalidate() }); ok {
+			i.Invalidate()
+		}
+	}
+	return resp, res
 }
 
 func (p *Protocol) makeRequest(ctx context.Context) *http.Request {
@@ -315,6 +397,33 @@ func (p *Protocol) Respond(ctx context.Context) (binding.Message, protocol.Respo
 
 // ServeHTTP implements http.Handler.
 // Blocks until ResponseFn is invoked.
+//
+// Requests in a structured format that is not registered in the format
+// package are rejected with 415 Unsupported Media Type. With WithCompression,
+// so are the requests with a Content-Encoding not registered in the
+// binding/compression package, and the body of compressed requests is
+// decompressed, WithMaxBodyBytes limiting both its compressed and
+// decompressed sizes. Responses of a
+// Responder are encoded as negotiated with the Accept header of the request:
+// structured when a registered CloudEvents format is preferred, binary when a
+// concrete media type is, and as the response message otherwise.
+//
+// Batched requests ("application/cloudevents-batch+json") are given to the
+// BatchHandlerFn if set. Otherwise each event of the batch is delivered as its
+// own message through Receive or Respond, and the request completes once every
+// event has been responded to. The per-event results are then aggregated into
+// a single HTTP response:
+//
+//   - If every event succeeded, the status is the one shared by all the
+//     results, or 200 if they differ. Reply events, if any, are written back
+//     as a batch.
+//   - If any event failed (status 400 or above), the status is the highest
+//     failure status, and the body lists the failed events, one per line.
+//     Reply events are dropped.
+//
+// A sender cannot tell which events of a failed batch were handled, so it may
+// send the whole batch again: receivers of batches should be idempotent, for
+// example by de-duplicating events on source and id.
 func (p *Protocol) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	// always apply limiter first using req context
 	ok, reset, err := p.limiter.Allow(req.Context(), req)
@@ -330,6 +439,18 @@ func (p *Protocol) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if p.maxBodyBytes > 0 {
+		// Reject what is known to be too large before reading anything.
+		if req.ContentLength > p.maxBodyBytes {
+			http.Error(rw, fmt.Sprintf("request body larger than %d bytes", p.maxBodyBytes), http.StatusRequestEntityTooLarge)
+			return
+		}
+		req.Body = http.MaxBytesReader(rw, req.Body, p.maxBodyBytes)
+	}
+	if req.TLS != nil && len(req.TLS.VerifiedChains) > 0 {
+		req = req.WithContext(withClientCertificate(req.Context(), req.TLS.VerifiedChains[0][0]))
+	}
+
 	// Filter the GET style methods:
 	switch req.Method {
 	case http.MethodOptions:
@@ -357,6 +478,47 @@ func (p *Protocol) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if p.WebhookConfig != nil && !p.enforceWebhook(rw, req) {
+		return
+	}
+
+	if p.webhookVerifier != nil {
+		if res := p.webhookVerifier.VerifyRequest(req); res != nil {
+			status, errMsg := statusForResult(res)
+			http.Error(rw, errMsg, status)
+			return
+		}
+	}
+
+	if p.requestAuthenticator != nil {
+		if req = p.authenticateRequest(rw, req); req == nil {
+			return
+		}
+	}
+
+	if p.authContextExtension {
+		// Strip the authcontext extension headers set by the sender, even
+		// when no principal was authenticated.
+		if err := PrincipalFromContext(req.Context()).setAuthContextHeaders(req.Header); err != nil {
+			http.Error(rw, fmt.Sprintf("Cannot map principal to authcontext: %s", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if p.compression != nil && !decodeRequest(rw, req, p.maxBodyBytes) {
+		return
+	}
+
+	if IsHTTPBatch(req.Header) {
+		p.serveBatch(rw, req)
+		return
+	}
+
+	if mediaType, ok := unsupportedFormat(req); ok {
+		writeUnsupportedFormat(rw, mediaType)
+		return
+	}
+
 	m := NewMessageFromHttpRequest(req)
 	if m == nil {
 		// Should never get here unless ServeHTTP is called directly.
@@ -371,6 +533,22 @@ func (p *Protocol) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return nil
 	}
 
+	var msg binding.Message = m
+	if p.authContextExtension && m.ReadEncoding() == binding.EncodingStructured {
+		// Binary mode requests get the authcontext extension from headers.
+		e, err := binding.ToEvent(req.Context(), m)
+		if err == nil {
+			err = PrincipalFromContext(req.Context()).setAuthContext(e)
+		}
+		if err != nil {
+			http.Error(rw, fmt.Sprintf("Cannot decode CloudEvent: %s", err), http.StatusBadRequest)
+			return
+		}
+		msg = &eventMessage{EventMessage: (*binding.EventMessage)(e), ctx: req.Context(), onFinish: func(err error) {
+			finishErr = err
+		}}
+	}
+
 	wg := sync.WaitGroup{}
 	wg.Add(1)
 	var fn protocol.ResponseFn = func(ctx context.Context, respMsg binding.Message, res protocol.Result, transformers ...binding.Transformer) error {
@@ -379,39 +557,30 @@ func (p *Protocol) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			wg.Done()
 		}()
 
-		if finishErr != nil {
+		if !protocol.IsACK(finishErr) {
 			http.Error(rw, fmt.Sprintf("Cannot forward CloudEvent: %s", finishErr), http.StatusInternalServerError)
 			return finishErr
 		}
 
-		status := http.StatusOK
-		var errMsg string
-		if res != nil {
-			var result *Result
-			switch {
-			case protocol.ResultAs(res, &result):
-				if result.StatusCode > 100 && result.StatusCode < 600 {
-					status = result.StatusCode
-				}
-				errMsg = fmt.Errorf(result.Format, result.Args...).Error()
-			case !protocol.IsACK(res):
-				// Map client errors to http status code
-				validationError := event.ValidationError{}
-				if errors.As(res, &validationError) {
-					status = http.StatusBadRequest
-					rw.Header().Set("content-type", "text/plain")
-					rw.WriteHeader(status)
-					_, _ = rw.Write([]byte(validationError.Error()))
-					return validationError
-				} else if errors.Is(res, binding.ErrUnknownEncoding) {
-					status = http.StatusUnsupportedMediaType
-				} else {
-					status = http.StatusInternalServerError
-				}
-			}
+		validationError := event.ValidationError{}
+		if !protocol.ResultAs(res, new(*Result)) && errors.As(res, &validationError) {
+			rw.Header().Set("content-type", "text/plain")
+			rw.WriteHeader(http.StatusBadRequest)
+			_, _ = rw.Write([]byte(validationError.Error()))
+			return validationError
 		}
+		status, errMsg := statusForResult(res)
 
 		if respMsg != nil {
+			ctx = withNegotiatedEncoding(ctx, req.Header.Get("Accept"))
+			if p.compression != nil && acceptsEncoding(req.Header.Get(AcceptEncoding), p.compression.Encoding()) {
+				w := &bufferedResponseWriter{header: rw.Header().Clone()}
+				err := WriteResponseWriter(ctx, respMsg, status, w, transformers...)
+				if err == nil {
+					err = w.writeCompressed(rw, p.compression, p.compressionThreshold)
+				}
+				return respMsg.Finish(err)
+			}
 			err := WriteResponseWriter(ctx, respMsg, status, rw, transformers...)
 			return respMsg.Finish(err)
 		}
@@ -423,7 +592,7 @@ func (p *Protocol) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return nil
 	}
 
-	p.incoming <- msgErr{msg: m, respFn: fn} // Send to Request
+	p.incoming <- msgErr{msg: msg, respFn: fn} // Send to Request
 	// Block until ResponseFn is invoked
 	wg.Wait()
 }
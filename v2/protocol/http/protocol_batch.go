@@ -0,0 +1,197 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// BatchHandlerFunc handles all the events of a batched request
+// ("application/cloudevents-batch+json") at once. The returned result is
+// mapped to the HTTP status of the response the same way as the result of a
+// single event.
+type BatchHandlerFunc func(ctx context.Context, events []event.Event) protocol.Result
+
+// eventMessage is an event decoded from a request, carrying the request
+// context. onFinish, if set, is called with the error the message is finished
+// with.
+type eventMessage struct {
+	*binding.EventMessage
+	ctx      context.Context
+	onFinish func(error)
+}
+
+var _ binding.MessageContext = (*eventMessage)(nil)
+var _ binding.MessageWrapper = (*eventMessage)(nil)
+
+func (m *eventMessage) Context() context.Context {
+	return m.ctx
+}
+
+func (m *eventMessage) GetWrappedMessage() binding.Message {
+	return m.EventMessage
+}
+
+func (m *eventMessage) Finish(err error) error {
+	if m.onFinish != nil {
+		m.onFinish(err)
+	}
+	return m.EventMessage.Finish(err)
+}
+
+type batchResult struct {
+	id     string
+	status int
+	errMsg string
+	reply  *event.Event
+}
+
+// serveBatch handles a batched request, either with the BatchHandlerFn or by
+// delivering each event on its own and aggregating the results.
+func (p *Protocol) serveBatch(rw http.ResponseWriter, req *http.Request) {
+	events, err := NewEventsFromHTTPRequest(req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if isMaxBytesError(err) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(rw, fmt.Sprintf("Cannot decode CloudEvents batch: %s", err), status)
+		return
+	}
+
+	if p.authContextExtension {
+		principal := PrincipalFromContext(req.Context())
+		for i := range events {
+			if err := principal.setAuthContext(&events[i]); err != nil {
+				http.Error(rw, fmt.Sprintf("Cannot map principal to authcontext: %s", err), http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	if p.BatchHandlerFn != nil {
+		status, errMsg := statusForResult(p.BatchHandlerFn(req.Context(), events))
+		rw.WriteHeader(status)
+		_, _ = rw.Write([]byte(errMsg))
+		return
+	}
+
+	results := make([]batchResult, len(events))
+	wg := sync.WaitGroup{}
+	for i := range events {
+		r := &results[i]
+		r.id = events[i].ID()
+		var finishErr error
+		m := &eventMessage{EventMessage: (*binding.EventMessage)(&events[i]), ctx: req.Context()}
+		m.onFinish = func(err error) {
+			finishErr = err
+		}
+
+		wg.Add(1)
+		var fn protocol.ResponseFn = func(ctx context.Context, respMsg binding.Message, res protocol.Result, transformers ...binding.Transformer) error {
+			defer wg.Done()
+
+			if !protocol.IsACK(finishErr) {
+				r.status, r.errMsg = http.StatusInternalServerError, fmt.Sprintf("Cannot forward CloudEvent: %s", finishErr)
+				return finishErr
+			}
+			r.status, r.errMsg = statusForResult(res)
+			if respMsg != nil {
+				e, err := binding.ToEvent(ctx, respMsg, transformers...)
+				if err = respMsg.Finish(err); err != nil {
+					return err
+				}
+				r.reply = e
+			}
+			return nil
+		}
+		p.incoming <- msgErr{msg: m, respFn: fn}
+	}
+	wg.Wait()
+
+	writeBatchResults(rw, results)
+}
+
+func writeBatchResults(rw http.ResponseWriter, results []batchResult) {
+	status := 0
+	var failed []string
+	var replies []*event.Event
+	for _, r := range results {
+		if r.status >= http.StatusBadRequest {
+			if len(failed) == 0 || r.status > status {
+				status = r.status
+			}
+			failed = append(failed, fmt.Sprintf("%s: %d %s", r.id, r.status, r.errMsg))
+			continue
+		}
+		if len(failed) == 0 {
+			if status == 0 {
+				status = r.status
+			} else if status != r.status {
+				status = http.StatusOK
+			}
+		}
+		if r.reply != nil {
+			replies = append(replies, r.reply)
+		}
+	}
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	if len(failed) > 0 {
+		rw.Header().Set(ContentType, "text/plain")
+		rw.WriteHeader(status)
+		_, _ = rw.Write([]byte(strings.Join(failed, "\n")))
+		return
+	}
+	if len(replies) > 0 {
+		rw.Header().Set(ContentType, event.ApplicationCloudEventsBatchJSON)
+		rw.WriteHeader(status)
+		_ = json.NewEncoder(rw).Encode(replies)
+		return
+	}
+	rw.WriteHeader(status)
+}
+
+// statusForResult maps the result of handling an event to an HTTP status and
+// error message, as ServeHTTP does for a single event. The message of errors
+// which are not validation errors or Results is not exposed.
+func statusForResult(res protocol.Result) (int, string) {
+	if res == nil {
+		return http.StatusOK, ""
+	}
+	var result *Result
+	switch {
+	case protocol.ResultAs(res, &result):
+		status := http.StatusOK
+		if result.StatusCode > 100 && result.StatusCode < 600 {
+			status = result.StatusCode
+		}
+		return status, fmt.Errorf(result.Format, result.Args...).Error()
+	case !protocol.IsACK(res):
+		validationError := event.ValidationError{}
+		if errors.As(res, &validationError) {
+			return http.StatusBadRequest, validationError.Error()
+		} else if errors.Is(res, binding.ErrUnknownEncoding) {
+			return http.StatusUnsupportedMediaType, ""
+		} else if isMaxBytesError(res) {
+			return http.StatusRequestEntityTooLarge, ""
+		}
+		return http.StatusInternalServerError, ""
+	}
+	return http.StatusOK, ""
+}
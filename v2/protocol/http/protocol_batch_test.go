@@ -0,0 +1,180 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func newBatchTestEvents(ids ...string) []event.Event {
+	events := make([]event.Event, 0, len(ids))
+	for _, id := range ids {
+		e := event.New()
+		e.SetID(id)
+		e.SetSource("/source")
+		e.SetType("batch.test")
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestServeHTTP_Batch(t *testing.T) {
+	testCases := map[string]struct {
+		results     map[string]protocol.Result
+		reply       bool
+		wantStatus  int
+		wantBody    []string
+		wantReplies int
+	}{
+		"all acked": {
+			wantStatus: http.StatusOK,
+		},
+		"all accepted": {
+			results: map[string]protocol.Result{
+				"a": NewResult(http.StatusAccepted, ""),
+				"b": NewResult(http.StatusAccepted, ""),
+				"c": NewResult(http.StatusAccepted, ""),
+			},
+			wantStatus: http.StatusAccepted,
+		},
+		"mixed successes": {
+			results: map[string]protocol.Result{
+				"b": NewResult(http.StatusAccepted, ""),
+			},
+			wantStatus: http.StatusOK,
+		},
+		"partial failure": {
+			results: map[string]protocol.Result{
+				"a": NewResult(http.StatusBadRequest, "bad"),
+				"c": NewResult(http.StatusServiceUnavailable, "unavailable"),
+			},
+			reply:      true,
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   []string{"a: 400 bad", "c: 503 unavailable"},
+		},
+		"replies": {
+			reply:       true,
+			wantStatus:  http.StatusOK,
+			wantReplies: 3,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			p, err := New()
+			require.NoError(t, err)
+
+			req, err := NewHTTPRequestFromEvents(context.Background(), "http://unittest", newBatchTestEvents("a", "b", "c"))
+			require.NoError(t, err)
+			rec := httptest.NewRecorder()
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				p.ServeHTTP(rec, req)
+			}()
+
+			for i := 0; i < 3; i++ {
+				m, fn, err := p.Respond(context.Background())
+				require.NoError(t, err)
+				e, err := binding.ToEvent(context.Background(), m)
+				require.NoError(t, err)
+
+				var reply binding.Message
+				if tc.reply {
+					r := e.Clone()
+					r.SetType("batch.reply")
+					reply = binding.ToMessage(&r)
+				}
+				require.NoError(t, fn(context.Background(), reply, tc.results[e.ID()]))
+			}
+			<-done
+
+			require.Equal(t, tc.wantStatus, rec.Code)
+			for _, line := range tc.wantBody {
+				require.Contains(t, rec.Body.String(), line)
+			}
+			if tc.wantReplies > 0 {
+				require.True(t, IsHTTPBatch(rec.Header()))
+				replies, err := NewEventsFromHTTPResponse(rec.Result())
+				require.NoError(t, err)
+				require.Len(t, replies, tc.wantReplies)
+				require.Equal(t, "batch.reply", replies[0].Type())
+			}
+		})
+	}
+}
+
+func TestServeHTTP_BatchReceive(t *testing.T) {
+	p, err := New()
+	require.NoError(t, err)
+
+	req, err := NewHTTPRequestFromEvents(context.Background(), "http://unittest", newBatchTestEvents("a", "b"))
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.ServeHTTP(rec, req)
+	}()
+
+	// The events NACKed by receivers fail the batch.
+	for i := 0; i < 2; i++ {
+		m, err := p.Receive(context.Background())
+		require.NoError(t, err)
+		e, err := binding.ToEvent(context.Background(), m)
+		require.NoError(t, err)
+		if e.ID() == "b" {
+			require.NoError(t, m.Finish(protocol.ResultNACK))
+		} else {
+			require.NoError(t, m.Finish(nil))
+		}
+	}
+	<-done
+
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+	require.Contains(t, rec.Body.String(), "b: 500")
+	require.NotContains(t, rec.Body.String(), "a: ")
+}
+
+func TestServeHTTP_BatchHandler(t *testing.T) {
+	var got []event.Event
+	p, err := New(WithBatchHandlerFunc(func(ctx context.Context, events []event.Event) protocol.Result {
+		got = events
+		return NewResult(http.StatusAccepted, "")
+	}))
+	require.NoError(t, err)
+
+	req, err := NewHTTPRequestFromEvents(context.Background(), "http://unittest", newBatchTestEvents("a", "b"))
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	require.Len(t, got, 2)
+	require.Equal(t, "b", got[1].ID())
+}
+
+func TestServeHTTP_BatchMalformed(t *testing.T) {
+	p, err := New()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "http://unittest", strings.NewReader(`{"not": "a batch"}`))
+	req.Header.Set(ContentType, event.ApplicationCloudEventsBatchJSON)
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
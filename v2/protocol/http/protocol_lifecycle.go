@@ -38,11 +38,10 @@ func (p *Protocol) OpenInbound(ctx context.Context) error {
 	}
 
 	p.server = &http.Server{
-		Addr:         listener.Addr().String(),
-		Handler:      attachMiddleware(p.Handler, p.middleware),
-		ReadTimeout:  *p.readTimeout,
-		WriteTimeout: *p.writeTimeout,
+		Addr:    listener.Addr().String(),
+		Handler: attachMiddleware(p.Handler, p.middleware),
 	}
+	p.ConfigureServer(p.server)
 
 	// Shutdown
 	defer func() {
@@ -52,6 +51,10 @@ func (p *Protocol) OpenInbound(ctx context.Context) error {
 
 	errChan := make(chan error)
 	go func() {
+		if p.server.TLSConfig != nil {
+			errChan <- p.server.ServeTLS(listener, "", "")
+			return
+		}
 		errChan <- p.server.Serve(listener)
 	}()
 
@@ -0,0 +1,400 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultRateLimiterIdleTimeout is how long a KeyedRateLimiter keeps the
+// limiter of a key that is not used anymore.
+const DefaultRateLimiterIdleTimeout = 10 * time.Minute
+
+// DefaultRateLimiterMaxKeys is how many keys a KeyedRateLimiter keeps a
+// limiter for.
+const DefaultRateLimiterMaxKeys = 10000
+
+// RateLimiterStats are counters exposed by the built-in rate limiters.
+type RateLimiterStats struct {
+	// Allowed is the number of requests allowed.
+	Allowed uint64
+	// Rejected is the number of requests rejected.
+	Rejected uint64
+	// Keys is the number of keys tracked, for a KeyedRateLimiter.
+	Keys int
+}
+
+// RateLimiterMetrics is implemented by the rate limiters exposing stats.
+type RateLimiterMetrics interface {
+	Stats() RateLimiterStats
+}
+
+var (
+	_ RateLimiter        = (*TokenBucketLimiter)(nil)
+	_ RateLimiterMetrics = (*TokenBucketLimiter)(nil)
+	_ RateLimiter        = (*SlidingWindowLimiter)(nil)
+	_ RateLimiterMetrics = (*SlidingWindowLimiter)(nil)
+	_ RateLimiter        = (*KeyedRateLimiter)(nil)
+	_ RateLimiterMetrics = (*KeyedRateLimiter)(nil)
+)
+
+// limiterStats counts the outcome of Allow, and implements Close.
+type limiterStats struct {
+	allowed  atomic.Uint64
+	rejected atomic.Uint64
+	closed   atomic.Bool
+}
+
+func (s *limiterStats) record(ok bool) {
+	if ok {
+		s.allowed.Add(1)
+	} else {
+		s.rejected.Add(1)
+	}
+}
+
+func (s *limiterStats) stats() RateLimiterStats {
+	return RateLimiterStats{Allowed: s.allowed.Load(), Rejected: s.rejected.Load()}
+}
+
+// resetSeconds rounds up a delay to the seconds expected by RateLimiter.Allow.
+func resetSeconds(d time.Duration) uint64 {
+	return uint64(math.Ceil(d.Seconds()))
+}
+
+// TokenBucketLimiter is a RateLimiter allowing perSecond requests per second
+// on average, and bursts of up to burst requests.
+type TokenBucketLimiter struct {
+	limiterStats
+	limiter *rate.Limiter
+	now     func() time.Time
+}
+
+// NewTokenBucketLimiter returns a token bucket refilled with perSecond tokens
+// per second, holding up to burst tokens. A request takes one token.
+func NewTokenBucketLimiter(perSecond float64, burst int) *TokenBucketLimiter {
+	return &TokenBucketLimiter{
+		limiter: rate.NewLimiter(rate.Limit(perSecond), burst),
+		now:     time.Now,
+	}
+}
+
+// Allow implements RateLimiter.Allow
+func (l *TokenBucketLimiter) Allow(_ context.Context, _ *http.Request) (bool, uint64, error) {
+	if l.closed.Load() {
+		return false, 0, nil
+	}
+	now := l.now()
+	r := l.limiter.ReserveN(now, 1)
+	if !r.OK() {
+		// The burst is 0: no request can ever be allowed.
+		l.record(false)
+		return false, 0, nil
+	}
+	if d := r.DelayFrom(now); d > 0 {
+		r.CancelAt(now)
+		l.record(false)
+		return false, resetSeconds(d), nil
+	}
+	l.record(true)
+	return true, 0, nil
+}
+
+// wait takes a token, waiting for it to be available unless ctx is done
+// first.
+func (l *TokenBucketLimiter) wait(ctx context.Context) error {
+	now := l.now()
+	r := l.limiter.ReserveN(now, 1)
+	if !r.OK() {
+		l.record(false)
+		return errors.New("no request is allowed")
+	}
+	if d := r.DelayFrom(now); d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			r.CancelAt(l.now())
+			l.record(false)
+			return ctx.Err()
+		}
+	}
+	l.record(true)
+	return nil
+}
+
+// Close implements RateLimiter.Close
+func (l *TokenBucketLimiter) Close(_ context.Context) error {
+	l.closed.Store(true)
+	return nil
+}
+
+// Stats implements RateLimiterMetrics.Stats
+func (l *TokenBucketLimiter) Stats() RateLimiterStats {
+	return l.stats()
+}
+
+// SlidingWindowLimiter is a RateLimiter allowing up to limit requests in any
+// window, estimated with a sliding window counter: the count of the previous
+// fixed window is weighted by how much it overlaps the sliding window.
+type SlidingWindowLimiter struct {
+	limiterStats
+	mu    sync.Mutex
+	long  slidingWindow
+	short *slidingWindow
+	now   func() time.Time
+}
+
+type slidingWindow struct {
+	limit    int
+	size     time.Duration
+	start    time.Time
+	current  int
+	previous int
+}
+
+// NewSlidingWindowLimiter returns a limiter allowing up to limit requests in
+// any window. If burst is positive and lower than limit, requests are also
+// limited to burst in any window*burst/limit, so that the whole limit cannot
+// be used at once.
+func NewSlidingWindowLimiter(limit int, window time.Duration, burst int) *SlidingWindowLimiter {
+	l := &SlidingWindowLimiter{
+		long: slidingWindow{limit: limit, size: window},
+		now:  time.Now,
+	}
+	if burst > 0 && burst < limit {
+		l.short = &slidingWindow{limit: burst, size: window * time.Duration(burst) / time.Duration(limit)}
+	}
+	return l
+}
+
+// Allow implements RateLimiter.Allow
+func (l *SlidingWindowLimiter) Allow(_ context.Context, _ *http.Request) (bool, uint64, error) {
+	if l.closed.Load() {
+		return false, 0, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+
+	ok, reset := l.long.allow(now)
+	if ok && l.short != nil {
+		ok, reset = l.short.allow(now)
+	}
+	if ok {
+		l.long.current++
+		if l.short != nil {
+			l.short.current++
+		}
+	}
+	l.record(ok)
+	return ok, resetSeconds(reset), nil
+}
+
+// allow returns whether a request fits in the window at now and, if not, the
+// time until it would.
+func (w *slidingWindow) allow(now time.Time) (bool, time.Duration) {
+	if w.limit <= 0 || w.size <= 0 {
+		return false, w.size
+	}
+	w.advance(now)
+	elapsed := now.Sub(w.start)
+	weight := 1 - float64(elapsed)/float64(w.size)
+	if float64(w.previous)*weight+float64(w.current) < float64(w.limit) {
+		return true, 0
+	}
+	if w.current >= w.limit {
+		// Only the next window can make room.
+		return false, w.size - elapsed
+	}
+	// Room is made as the previous window slides out.
+	excess := float64(w.previous)*weight + float64(w.current) - float64(w.limit) + 1
+	return false, time.Duration(excess / float64(w.previous) * float64(w.size))
+}
+
+func (w *slidingWindow) advance(now time.Time) {
+	if w.start.IsZero() {
+		w.start = now.Truncate(w.size)
+	}
+	switch n := now.Sub(w.start) / w.size; {
+	case n == 1:
+		w.previous, w.current = w.current, 0
+		w.start = w.start.Add(w.size)
+	case n > 1:
+		w.previous, w.current = 0, 0
+		w.start = now.Truncate(w.size)
+	}
+}
+
+// Close implements RateLimiter.Close
+func (l *SlidingWindowLimiter) Close(_ context.Context) error {
+	l.closed.Store(true)
+	return nil
+}
+
+// Stats implements RateLimiterMetrics.Stats
+func (l *SlidingWindowLimiter) Stats() RateLimiterStats {
+	return l.stats()
+}
+
+// KeyFunc extracts the key a KeyedRateLimiter limits a request by.
+type KeyFunc func(r *http.Request) string
+
+// KeyByClientIP keys requests by the IP address of the client. Proxies are not
+// taken into account, use a custom KeyFunc to trust X-Forwarded-For.
+func KeyByClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// KeyByOrigin keys requests by the host of their Origin header, or else of
+// their WebHook-Request-Origin header.
+func KeyByOrigin(r *http.Request) string {
+	_, host, _ := splitOrigin(deliveryOrigin(r))
+	return host
+}
+
+// KeyByCESource keys requests by the ce-source header. Only binary mode
+// requests have it: structured and batched requests share the "" key.
+func KeyByCESource(r *http.Request) string {
+	return r.Header.Get(prefix + "Source")
+}
+
+// KeyedRateLimiter applies a separate RateLimiter to each key, as extracted
+// from requests by a KeyFunc. Limiters of keys unused for the idle timeout are
+// closed and dropped. Once the maximum number of keys is tracked, the requests
+// of new keys share a single limiter, so that keys chosen by clients cannot
+// exhaust the memory.
+type KeyedRateLimiter struct {
+	limiterStats
+	key         KeyFunc
+	newLimiter  func() RateLimiter
+	idleTimeout time.Duration
+	maxKeys     int
+	now         func() time.Time
+
+	mu        sync.Mutex
+	limiters  map[string]*keyedLimiter
+	overflow  RateLimiter
+	lastSweep time.Time
+}
+
+type keyedLimiter struct {
+	RateLimiter
+	lastUsed time.Time
+}
+
+// NewKeyedRateLimiter returns a limiter creating a RateLimiter with
+// newLimiter for each key returned by key, for up to maxKeys keys. If
+// idleTimeout is 0, DefaultRateLimiterIdleTimeout is used. If maxKeys is 0,
+// DefaultRateLimiterMaxKeys is used.
+func NewKeyedRateLimiter(key KeyFunc, newLimiter func() RateLimiter, idleTimeout time.Duration, maxKeys int) *KeyedRateLimiter {
+	if idleTimeout == 0 {
+		idleTimeout = DefaultRateLimiterIdleTimeout
+	}
+	if maxKeys == 0 {
+		maxKeys = DefaultRateLimiterMaxKeys
+	}
+	return &KeyedRateLimiter{
+		key:         key,
+		newLimiter:  newLimiter,
+		idleTimeout: idleTimeout,
+		maxKeys:     maxKeys,
+		now:         time.Now,
+		limiters:    make(map[string]*keyedLimiter),
+	}
+}
+
+// Allow implements RateLimiter.Allow
+func (l *KeyedRateLimiter) Allow(ctx context.Context, r *http.Request) (bool, uint64, error) {
+	if l.closed.Load() {
+		return false, 0, nil
+	}
+	k := l.key(r)
+
+	l.mu.Lock()
+	now := l.now()
+	if now.Sub(l.lastSweep) >= l.idleTimeout {
+		l.sweep(ctx, now)
+	}
+	var lim RateLimiter
+	if kl, ok := l.limiters[k]; ok {
+		kl.lastUsed = now
+		lim = kl
+	} else if len(l.limiters) < l.maxKeys {
+		l.limiters[k] = &keyedLimiter{RateLimiter: l.newLimiter(), lastUsed: now}
+		lim = l.limiters[k]
+	} else {
+		if l.overflow == nil {
+			l.overflow = l.newLimiter()
+		}
+		lim = l.overflow
+	}
+	l.mu.Unlock()
+
+	allowed, reset, err := lim.Allow(ctx, r)
+	if err != nil {
+		return false, 0, err
+	}
+	l.record(allowed)
+	return allowed, reset, nil
+}
+
+// sweep drops the limiters idle since idleTimeout. It must be called with mu
+// held.
+func (l *KeyedRateLimiter) sweep(ctx context.Context, now time.Time) {
+	for k, lim := range l.limiters {
+		if now.Sub(lim.lastUsed) >= l.idleTimeout {
+			_ = lim.Close(ctx)
+			delete(l.limiters, k)
+		}
+	}
+	l.lastSweep = now
+}
+
+// Close implements RateLimiter.Close, closing the limiter of every key.
+func (l *KeyedRateLimiter) Close(ctx context.Context) error {
+	l.closed.Store(true)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var err error
+	for k, lim := range l.limiters {
+		if cerr := lim.Close(ctx); cerr != nil && err == nil {
+			err = cerr
+		}
+		delete(l.limiters, k)
+	}
+	if l.overflow != nil {
+		if cerr := l.overflow.Close(ctx); cerr != nil && err == nil {
+			err = cerr
+		}
+		l.overflow = nil
+	}
+	return err
+}
+
+// Stats implements RateLimiterMetrics.Stats
+func (l *KeyedRateLimiter) Stats() RateLimiterStats {
+	s := l.stats()
+	l.mu.Lock()
+	s.Keys = len(l.limiters)
+	l.mu.Unlock()
+	return s
+}
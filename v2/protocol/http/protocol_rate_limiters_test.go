@@ -0,0 +1,200 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Add(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestClock() *testClock {
+	return &testClock{now: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+// allowN calls Allow n times and returns the number of allowed requests and
+// the reset of the last rejected one.
+func allowN(t *testing.T, l RateLimiter, req *http.Request, n int) (int, uint64) {
+	allowed := 0
+	var reset uint64
+	for i := 0; i < n; i++ {
+		ok, r, err := l.Allow(context.Background(), req)
+		require.NoError(t, err)
+		if ok {
+			allowed++
+		} else {
+			reset = r
+		}
+	}
+	return allowed, reset
+}
+
+func TestTokenBucketLimiter(t *testing.T) {
+	clock := newTestClock()
+	l := NewTokenBucketLimiter(0.5, 3)
+	l.now = clock.Now
+	req := httptest.NewRequest(http.MethodPost, "http://unittest", nil)
+
+	allowed, reset := allowN(t, l, req, 5)
+	require.Equal(t, 3, allowed)
+	require.Equal(t, uint64(2), reset)
+
+	clock.Add(2 * time.Second)
+	allowed, _ = allowN(t, l, req, 2)
+	require.Equal(t, 1, allowed)
+
+	require.Equal(t, RateLimiterStats{Allowed: 4, Rejected: 3}, l.Stats())
+
+	require.NoError(t, l.Close(context.Background()))
+	clock.Add(time.Hour)
+	ok, reset, err := l.Allow(context.Background(), req)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Zero(t, reset)
+}
+
+func TestSlidingWindowLimiter(t *testing.T) {
+	clock := newTestClock()
+	l := NewSlidingWindowLimiter(10, time.Minute, 0)
+	l.now = clock.Now
+	req := httptest.NewRequest(http.MethodPost, "http://unittest", nil)
+
+	allowed, reset := allowN(t, l, req, 12)
+	require.Equal(t, 10, allowed)
+	require.Equal(t, uint64(60), reset)
+
+	// Half way through the next window, half of the previous one still counts.
+	clock.Add(90 * time.Second)
+	allowed, reset = allowN(t, l, req, 6)
+	require.Equal(t, 5, allowed)
+	require.Equal(t, uint64(6), reset)
+
+	clock.Add(10 * time.Minute)
+	allowed, _ = allowN(t, l, req, 10)
+	require.Equal(t, 10, allowed)
+
+	require.Equal(t, RateLimiterStats{Allowed: 25, Rejected: 3}, l.Stats())
+}
+
+func TestSlidingWindowLimiterBurst(t *testing.T) {
+	clock := newTestClock()
+	l := NewSlidingWindowLimiter(10, time.Minute, 2)
+	l.now = clock.Now
+	req := httptest.NewRequest(http.MethodPost, "http://unittest", nil)
+
+	// At most 2 requests in any 12s.
+	allowed, _ := allowN(t, l, req, 5)
+	require.Equal(t, 2, allowed)
+
+	clock.Add(6 * time.Second)
+	allowed, _ = allowN(t, l, req, 2)
+	require.Equal(t, 0, allowed)
+
+	// Half of the previous 12s window still counts.
+	clock.Add(12 * time.Second)
+	allowed, _ = allowN(t, l, req, 2)
+	require.Equal(t, 1, allowed)
+
+	clock.Add(18 * time.Second)
+	allowed, _ = allowN(t, l, req, 3)
+	require.Equal(t, 2, allowed)
+}
+
+func TestKeyedRateLimiter(t *testing.T) {
+	clock := newTestClock()
+	l := NewKeyedRateLimiter(KeyByCESource, func() RateLimiter {
+		return NewTokenBucketLimiter(1, 1)
+	}, time.Minute, 0)
+	l.now = clock.Now
+
+	reqA := httptest.NewRequest(http.MethodPost, "http://unittest", nil)
+	reqA.Header.Set("Ce-Source", "/a")
+	reqB := httptest.NewRequest(http.MethodPost, "http://unittest", nil)
+	reqB.Header.Set("Ce-Source", "/b")
+
+	allowed, _ := allowN(t, l, reqA, 2)
+	require.Equal(t, 1, allowed)
+	allowed, _ = allowN(t, l, reqB, 2)
+	require.Equal(t, 1, allowed)
+	require.Equal(t, RateLimiterStats{Allowed: 2, Rejected: 2, Keys: 2}, l.Stats())
+
+	// Idle keys are dropped.
+	clock.Add(2 * time.Minute)
+	allowed, _ = allowN(t, l, reqA, 1)
+	require.Equal(t, 1, allowed)
+	require.Equal(t, 1, l.Stats().Keys)
+
+	require.NoError(t, l.Close(context.Background()))
+	require.Equal(t, 0, l.Stats().Keys)
+	allowed, _ = allowN(t, l, reqB, 1)
+	require.Equal(t, 0, allowed)
+}
+
+func TestKeyedRateLimiterMaxKeys(t *testing.T) {
+	l := NewKeyedRateLimiter(KeyByCESource, func() RateLimiter {
+		return NewTokenBucketLimiter(1, 1)
+	}, time.Minute, 2)
+
+	allowed := 0
+	for _, source := range []string{"/a", "/b", "/c", "/d"} {
+		req := httptest.NewRequest(http.MethodPost, "http://unittest", nil)
+		req.Header.Set("Ce-Source", source)
+		n, _ := allowN(t, l, req, 1)
+		allowed += n
+	}
+	// The keys over the maximum share a limiter.
+	require.Equal(t, 3, allowed)
+	require.Equal(t, 2, l.Stats().Keys)
+}
+
+func TestKeyFuncs(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "http://unittest", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("Origin", "https://Sender.example.com:8443")
+	req.Header.Set("Ce-Source", "/source")
+
+	require.Equal(t, "192.0.2.1", KeyByClientIP(req))
+	require.Equal(t, "sender.example.com", KeyByOrigin(req))
+	require.Equal(t, "/source", KeyByCESource(req))
+
+	req.Header.Del("Origin")
+	req.Header.Set("WebHook-Request-Origin", "other.example.com")
+	require.Equal(t, "other.example.com", KeyByOrigin(req))
+}
+
+func TestServeHTTP_KeyedRateLimiter(t *testing.T) {
+	p, err := New(WithRateLimiter(NewKeyedRateLimiter(KeyByClientIP, func() RateLimiter {
+		return NewTokenBucketLimiter(0.1, 1)
+	}, 0, 0)))
+	require.NoError(t, err)
+
+	codes := []int{}
+	for _, addr := range []string{"192.0.2.1:1", "192.0.2.1:2", "192.0.2.2:1"} {
+		// GET gives 405 when not rate limited.
+		req := httptest.NewRequest(http.MethodGet, "http://unittest", nil)
+		req.RemoteAddr = addr
+		rec := httptest.NewRecorder()
+		p.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+	}
+	require.Equal(t, []int{http.StatusMethodNotAllowed, http.StatusTooManyRequests, http.StatusMethodNotAllowed}, codes)
+}
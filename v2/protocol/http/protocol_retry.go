@@ -10,7 +10,9 @@ import (
 	"context"
 	"errors"
 	"io"
+	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"go.uber.org/zap"
@@ -34,6 +36,14 @@ func (p *Protocol) do(ctx context.Context, req *http.Request) (binding.Message,
 }
 
 func (p *Protocol) doOnce(req *http.Request) (binding.Message, protocol.Result) {
+	if p.webhookSigner != nil {
+		// Sign every attempt, for the webhook-timestamp of retries to be
+		// within the tolerance of the receiver.
+		if err := p.webhookSigner.SignRequest(req, req.Header.Get(WebhookIDHeader)); err != nil {
+			return nil, err
+		}
+	}
+
 	resp, err := p.Client.Do(req)
 	if err != nil {
 		return nil, protocol.NewReceipt(false, "%w", err)
@@ -46,6 +56,9 @@ func (p *Protocol) doOnce(req *http.Request) (binding.Message, protocol.Result)
 		result = protocol.ResultNACK
 	}
 
+	if p.compression != nil {
+		decodeResponse(resp)
+	}
 	return NewMessage(resp.Header, resp.Body), NewResult(resp.StatusCode, "%w", result)
 }
 
@@ -55,8 +68,9 @@ func (p *Protocol) doWithRetry(ctx context.Context, params *cecontext.RetryParam
 	results := make([]protocol.Result, 0)
 
 	var (
-		body []byte
-		err  error
+		body  []byte
+		err   error
+		delay time.Duration
 	)
 
 	if req != nil && req.Body != nil {
@@ -89,10 +103,24 @@ func (p *Protocol) doWithRetry(ctx context.Context, params *cecontext.RetryParam
 					zap.Int("statusCode", sc))
 				return msg, NewRetriesResult(result, retry, start, results)
 			}
+		} else if !p.isRetriableTransportError(req, result) {
+			cecontext.LoggerFrom(ctx).Debugw("transport error not retryable with the idempotency policy, will not try again",
+				zap.Error(result))
+			return msg, NewRetriesResult(result, retry, start, results)
 		}
 
 		// total tries = retry + 1
-		if err = params.Backoff(ctx, retry+1); err != nil {
+		delay = params.JitteredBackoffFor(retry+1, delay)
+		wait := delay
+		if retryAfter, ok := retryAfterFrom(msg, time.Now()); ok {
+			// The delay requested by the server is bounded like the backoff,
+			// but does not feed the jitter of the next one.
+			wait = retryAfter
+			if params.MaxPeriod > 0 && wait > params.MaxPeriod {
+				wait = params.MaxPeriod
+			}
+		}
+		if err = params.Wait(ctx, retry+1, wait); err != nil {
 			// do not try again.
 			cecontext.LoggerFrom(ctx).Debugw("backoff error, will not try again", zap.Error(err))
 			return msg, NewRetriesResult(result, retry, start, results)
@@ -108,6 +136,88 @@ func (p *Protocol) doWithRetry(ctx context.Context, params *cecontext.RetryParam
 	}
 }
 
+// IdempotencyPolicy sets which requests are retried after a transport error,
+// such as a connection reset, as the receiver might have received the request
+// before the error.
+type IdempotencyPolicy int
+
+const (
+	// RetryAllRequests retries all requests. Receivers identify duplicates of
+	// an event by its source and id.
+	RetryAllRequests IdempotencyPolicy = iota
+	// RetryIdempotentRequests retries requests with an idempotent method or
+	// an Idempotency-Key header, and the requests which were not sent.
+	RetryIdempotentRequests
+	// RetryUnsentRequests only retries requests which were not sent, because
+	// the connection could not be established.
+	RetryUnsentRequests
+)
+
+// isRetriableTransportError returns whether a request failing with the
+// transport error err can be retried with the idempotency policy.
+func (p *Protocol) isRetriableTransportError(req *http.Request, err error) bool {
+	if req.Context().Err() != nil {
+		// The caller gave up.
+		return false
+	}
+	switch p.idempotencyPolicy {
+	case RetryIdempotentRequests:
+		return isIdempotent(req) || isUnsent(err)
+	case RetryUnsentRequests:
+		return isUnsent(err)
+	default:
+		return true
+	}
+}
+
+// isIdempotent returns whether req is idempotent, following the same rules
+// as http.Transport.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	_, ok := req.Header["Idempotency-Key"]
+	if !ok {
+		_, ok = req.Header["X-Idempotency-Key"]
+	}
+	return ok
+}
+
+// isUnsent returns whether err happened before a request could be sent.
+func isUnsent(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// retryAfterFrom returns the delay of the Retry-After header of a response,
+// either in seconds or as an HTTP date.
+func retryAfterFrom(msg binding.Message, now time.Time) (time.Duration, bool) {
+	m, ok := msg.(*Message)
+	if !ok || m.Header == nil {
+		return 0, false
+	}
+	v := m.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseUint(v, 10, 32); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	if d := date.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
 // reset body to allow it to be read multiple times, e.g. when retrying http
 // requests
 func resetBody(req *http.Request, body []byte) {
@@ -7,8 +7,11 @@ package http
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -215,6 +218,175 @@ func TestRequestWithRetries_linear(t *testing.T) {
 	}
 }
 
+func TestRetryAfterFrom(t *testing.T) {
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	testCases := map[string]struct {
+		retryAfter string
+		want       time.Duration
+		wantOK     bool
+	}{
+		"none":         {},
+		"seconds":      {retryAfter: "3", want: 3 * time.Second, wantOK: true},
+		"date":         {retryAfter: "Sat, 01 May 2021 12:00:10 GMT", want: 10 * time.Second, wantOK: true},
+		"past date":    {retryAfter: "Sat, 01 May 2021 11:00:00 GMT", want: 0, wantOK: true},
+		"negative":     {retryAfter: "-1"},
+		"invalid date": {retryAfter: "tomorrow"},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			h := http.Header{}
+			if tc.retryAfter != "" {
+				h.Set("Retry-After", tc.retryAfter)
+			}
+			got, ok := retryAfterFrom(NewMessage(h, nil), now)
+			require.Equal(t, tc.wantOK, ok)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRequestWithRetries_retryAfter(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		times []time.Time
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		times = append(times, time.Now())
+		if len(times) == 1 {
+			rw.Header().Set("Retry-After", "1")
+			rw.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer srv.Close()
+
+	p, err := New(WithTarget(srv.URL))
+	require.NoError(t, err)
+	e := newEvent(t, "", nil)
+
+	ctx := cecontext.WithRetriesConstantBackoff(context.Background(), time.Nanosecond, 3)
+	_, result := p.Request(ctx, binding.ToMessage(&e))
+	require.True(t, protocol.IsACK(result), "got %v", result)
+	require.Len(t, times, 2)
+	require.GreaterOrEqual(t, times[1].Sub(times[0]), time.Second)
+
+	// The delay requested by the server exceeds the deadline of the caller.
+	mu.Lock()
+	times = nil
+	mu.Unlock()
+	ctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+	_, result = p.Request(ctx, binding.ToMessage(&e))
+	var retriesResult *RetriesResult
+	require.True(t, protocol.ResultAs(result, &retriesResult), "got %v", result)
+	require.Equal(t, 0, retriesResult.Retries)
+	var httpResult *Result
+	require.True(t, protocol.ResultAs(retriesResult.Result, &httpResult), "got %v", result)
+	require.Equal(t, http.StatusTooManyRequests, httpResult.StatusCode)
+	require.Len(t, times, 1)
+}
+
+func TestRequestWithRetries_retryAfterBounded(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		times []time.Time
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		times = append(times, time.Now())
+		switch len(times) {
+		case 1:
+			rw.Header().Set("Retry-After", "10")
+			rw.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	p, err := New(WithTarget(srv.URL))
+	require.NoError(t, err)
+	e := newEvent(t, "", nil)
+
+	// The delay requested by the server is bounded by MaxPeriod, which fits in
+	// the deadline of the caller, and the backoff after it is not.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = cecontext.WithRetryParams(ctx, &cecontext.RetryParams{
+		Strategy:  cecontext.BackoffStrategyConstant,
+		MaxTries:  3,
+		Period:    time.Millisecond,
+		MaxPeriod: 200 * time.Millisecond,
+		Jitter:    cecontext.JitterStrategyDecorrelated,
+	})
+	_, result := p.Request(ctx, binding.ToMessage(&e))
+	require.True(t, protocol.IsACK(result), "got %v", result)
+	require.Len(t, times, 3)
+	require.GreaterOrEqual(t, times[1].Sub(times[0]), 200*time.Millisecond)
+	require.Less(t, times[1].Sub(times[0]), time.Second)
+	require.Less(t, times[2].Sub(times[1]), 100*time.Millisecond)
+}
+
+func TestRequestWithRetries_idempotencyPolicy(t *testing.T) {
+	// resetServer resets the connection of the first request after reading it.
+	var requests int32
+	resetServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			conn, _, err := rw.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			_ = conn.(*net.TCPConn).SetLinger(0)
+			_ = conn.Close()
+		}
+	}))
+	defer resetServer.Close()
+
+	// closed is the address of a closed listener, which refuses connections.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closed := "http://" + ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	testCases := map[string]struct {
+		policy         IdempotencyPolicy
+		target         string
+		idempotencyKey bool
+		wantRetry      bool
+	}{
+		"all, reset":                     {policy: RetryAllRequests, target: resetServer.URL, wantRetry: true},
+		"idempotent, reset":              {policy: RetryIdempotentRequests, target: resetServer.URL},
+		"idempotent, reset with key":     {policy: RetryIdempotentRequests, target: resetServer.URL, idempotencyKey: true, wantRetry: true},
+		"idempotent, connection refused": {policy: RetryIdempotentRequests, target: closed, wantRetry: true},
+		"unsent, reset with key":         {policy: RetryUnsentRequests, target: resetServer.URL, idempotencyKey: true},
+		"unsent, connection refused":     {policy: RetryUnsentRequests, target: closed, wantRetry: true},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			atomic.StoreInt32(&requests, 0)
+			p, err := New(WithTarget(tc.target), WithIdempotencyPolicy(tc.policy))
+			require.NoError(t, err)
+			if tc.idempotencyKey {
+				require.NoError(t, p.applyOptions(WithHeader("Idempotency-Key", "key")))
+			}
+			e := newEvent(t, "", nil)
+
+			ctx := cecontext.WithRetriesConstantBackoff(context.Background(), time.Nanosecond, 1)
+			_, result := p.Request(ctx, binding.ToMessage(&e))
+			var retriesResult *RetriesResult
+			require.True(t, protocol.ResultAs(result, &retriesResult), "got %v", result)
+			if tc.wantRetry {
+				require.Equal(t, 1, retriesResult.Retries)
+			} else {
+				require.Equal(t, 0, retriesResult.Retries)
+			}
+		})
+	}
+
+	_, err = New(WithIdempotencyPolicy(IdempotencyPolicy(42)))
+	require.Error(t, err)
+}
+
 func newEvent(t *testing.T, encoding string, body interface{}) event.Event {
 	e := event.New()
 	if body != nil {
@@ -0,0 +1,136 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// ConfigureServer applies the server options of the Protocol (timeouts, h2c,
+// TLS and header size limit) to s. OpenInbound uses it for the server it
+// creates; it also allows serving the Protocol with another server, such as
+// one from httptest.NewUnstartedServer. Note that httptest serves TLS with
+// its TLS field rather than s.TLSConfig.
+func (p *Protocol) ConfigureServer(s *http.Server) {
+	if p.readTimeout != nil {
+		s.ReadTimeout = *p.readTimeout
+	}
+	if p.writeTimeout != nil {
+		s.WriteTimeout = *p.writeTimeout
+	}
+	if p.maxHeaderBytes > 0 {
+		s.MaxHeaderBytes = p.maxHeaderBytes
+	}
+	if p.h2c {
+		s.Protocols = new(http.Protocols)
+		s.Protocols.SetHTTP1(true)
+		s.Protocols.SetHTTP2(true)
+		s.Protocols.SetUnencryptedHTTP2(true)
+	}
+	if p.tlsConfig != nil {
+		s.TLSConfig = p.tlsConfig.Clone()
+	}
+}
+
+// tlsConfigOrNew returns the TLS configuration of p, creating it if needed.
+func (p *Protocol) tlsConfigOrNew() *tls.Config {
+	if p.tlsConfig == nil {
+		p.tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return p.tlsConfig
+}
+
+// DefaultCertificateCheckInterval is the default minimum time between two
+// checks of the files of a CertificateReloader.
+const DefaultCertificateCheckInterval = time.Minute
+
+// CertificateReloader loads a TLS certificate and key from PEM files, and
+// reloads them when the files change, so that certificates can be renewed
+// without restarting the server.
+type CertificateReloader struct {
+	certFile string
+	keyFile  string
+	// CheckInterval is the minimum time between two checks of the files,
+	// DefaultCertificateCheckInterval by default. If 0, the files are checked
+	// on every TLS handshake.
+	CheckInterval time.Duration
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	certMod   time.Time
+	keyMod    time.Time
+	lastCheck time.Time
+	now       func() time.Time
+}
+
+// NewCertificateReloader loads the certificate and key at certFile and keyFile.
+func NewCertificateReloader(certFile, keyFile string) (*CertificateReloader, error) {
+	r := &CertificateReloader{
+		certFile:      certFile,
+		keyFile:       keyFile,
+		CheckInterval: DefaultCertificateCheckInterval,
+		now:           time.Now,
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload loads the certificate and key files again.
+func (r *CertificateReloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reload()
+}
+
+func (r *CertificateReloader) reload() error {
+	certMod, keyMod, err := r.modTimes()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS certificate: %w", err)
+	}
+	r.cert, r.certMod, r.keyMod = &cert, certMod, keyMod
+	return nil
+}
+
+func (r *CertificateReloader) modTimes() (time.Time, time.Time, error) {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("loading TLS certificate: %w", err)
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("loading TLS key: %w", err)
+	}
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+}
+
+// GetCertificate returns the current certificate, reloading it first if the
+// files were modified. If they cannot be loaded, for instance because only
+// one of them was written yet, the previous certificate keeps being used. It
+// is meant to be set as tls.Config.GetCertificate.
+func (r *CertificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if now.Sub(r.lastCheck) >= r.CheckInterval {
+		r.lastCheck = now
+		if certMod, keyMod, err := r.modTimes(); err == nil && (!certMod.Equal(r.certMod) || !keyMod.Equal(r.keyMod)) {
+			_ = r.reload()
+		}
+	}
+	return r.cert, nil
+}
@@ -0,0 +1,310 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+// issue returns a PEM encoded certificate and key for a server on 127.0.0.1
+// and localhost, or a client.
+func (ca *testCA) issue(t *testing.T, serial int64, commonName string, client bool) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		DNSNames:     []string{"localhost"},
+	}
+	if client {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+		template.IPAddresses = nil
+		template.DNSNames = nil
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeCertificateFiles writes the certificate and key to the files of dir,
+// with a modification time of mod.
+func writeCertificateFiles(t *testing.T, dir string, certPEM, keyPEM []byte, mod time.Time) (string, string) {
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+	require.NoError(t, os.Chtimes(certFile, mod, mod))
+	require.NoError(t, os.Chtimes(keyFile, mod, mod))
+	return certFile, keyFile
+}
+
+// newConfiguredTestServer serves p with an httptest server configured by
+// p.ConfigureServer. As httptest adds its own certificate to the TLS config,
+// clients must use the "localhost" server name for GetCertificate to be used.
+func newConfiguredTestServer(t *testing.T, p *Protocol) *httptest.Server {
+	s := httptest.NewUnstartedServer(p)
+	p.ConfigureServer(s.Config)
+	if s.Config.TLSConfig != nil {
+		s.TLS = s.Config.TLSConfig
+		s.StartTLS()
+	} else {
+		s.Start()
+	}
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestConfigureServer(t *testing.T) {
+	p, err := New(WithReadTimeout(time.Second), WithMaxHeaderBytes(1024), WithH2C())
+	require.NoError(t, err)
+	s := &http.Server{}
+	p.ConfigureServer(s)
+	require.Equal(t, time.Second, s.ReadTimeout)
+	require.Equal(t, 1024, s.MaxHeaderBytes)
+	require.True(t, s.Protocols.HTTP1())
+	require.True(t, s.Protocols.UnencryptedHTTP2())
+	require.Nil(t, s.TLSConfig)
+
+	_, err = New(WithMaxHeaderBytes(0))
+	require.Error(t, err)
+	_, err = New(WithMaxBodyBytes(-1))
+	require.Error(t, err)
+	_, err = New(WithTLSCertificateFiles("missing.crt", "missing.key"))
+	require.Error(t, err)
+}
+
+func TestServeH2C(t *testing.T) {
+	p, err := New(WithH2C())
+	require.NoError(t, err)
+	s := newConfiguredTestServer(t, p)
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+	resp, err := client.Get(s.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 2, resp.ProtoMajor)
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestServeTLSCertificateReload(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certPEM, keyPEM := ca.issue(t, 10, "server", false)
+	mod := time.Now().Add(-time.Minute)
+	certFile, keyFile := writeCertificateFiles(t, dir, certPEM, keyPEM, mod)
+
+	r, err := NewCertificateReloader(certFile, keyFile)
+	require.NoError(t, err)
+	require.Equal(t, DefaultCertificateCheckInterval, r.CheckInterval)
+	// expire makes the next handshake check the files.
+	expire := func() {
+		r.mu.Lock()
+		r.lastCheck = r.lastCheck.Add(-r.CheckInterval)
+		r.mu.Unlock()
+	}
+	p, err := New(WithTLSCertificateReloader(r))
+	require.NoError(t, err)
+	s := newConfiguredTestServer(t, p)
+
+	serial := func() int64 {
+		// Each call makes a new connection.
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: ca.pool, ServerName: "localhost"},
+		}}
+		defer client.CloseIdleConnections()
+		resp, err := client.Get(s.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		return resp.TLS.PeerCertificates[0].SerialNumber.Int64()
+	}
+	require.EqualValues(t, 10, serial())
+
+	certPEM, keyPEM = ca.issue(t, 11, "server", false)
+	writeCertificateFiles(t, dir, certPEM, keyPEM, mod.Add(time.Second))
+	// The files are not checked again before CheckInterval.
+	require.EqualValues(t, 10, serial())
+	expire()
+	require.EqualValues(t, 11, serial())
+
+	// A broken pair keeps the previous certificate in use.
+	require.NoError(t, os.WriteFile(keyFile, []byte("partially written"), 0o600))
+	expire()
+	require.EqualValues(t, 11, serial())
+}
+
+func TestServeMutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	certPEM, keyPEM := ca.issue(t, 10, "server", false)
+	certFile, keyFile := writeCertificateFiles(t, t.TempDir(), certPEM, keyPEM, time.Now())
+
+	p, err := New(WithTLSCertificateFiles(certFile, keyFile), WithMutualTLS(ca.pool))
+	require.NoError(t, err)
+	s := newConfiguredTestServer(t, p)
+
+	clients := make(chan string, 1)
+	go func() {
+		for {
+			m, fn, err := p.Respond(context.Background())
+			if err != nil {
+				return
+			}
+			if cert := ClientCertificateFromContext(m.(binding.MessageContext).Context()); cert != nil {
+				clients <- cert.Subject.CommonName
+			}
+			_ = m.Finish(nil)
+			_ = fn(context.Background(), nil, nil)
+		}
+	}()
+
+	send := func(certs ...tls.Certificate) error {
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: ca.pool, ServerName: "localhost", Certificates: certs},
+		}}
+		sender, err := New(WithTarget(s.URL), WithClient(*client))
+		require.NoError(t, err)
+		return sender.Send(context.Background(), newWebhookTestEvent())
+	}
+
+	require.Error(t, send())
+
+	clientCertPEM, clientKeyPEM := ca.issue(t, 20, "client-1", true)
+	clientCert, err := tls.X509KeyPair(clientCertPEM, clientKeyPEM)
+	require.NoError(t, err)
+	err = send(clientCert)
+	require.True(t, protocol.IsACK(err), "got %v", err)
+	require.Equal(t, "client-1", <-clients)
+}
+
+func TestServeTLS_OpenInbound(t *testing.T) {
+	ca := newTestCA(t)
+	certPEM, keyPEM := ca.issue(t, 10, "server", false)
+	certFile, keyFile := writeCertificateFiles(t, t.TempDir(), certPEM, keyPEM, time.Now())
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	p, err := New(WithListener(ln), WithTLSCertificateFiles(certFile, keyFile))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- p.OpenInbound(ctx) }()
+	defer func() {
+		cancel()
+		require.NoError(t, <-done)
+	}()
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: ca.pool},
+		ForceAttemptHTTP2: true,
+	}}
+	defer client.CloseIdleConnections()
+	resp, err := client.Get("https://" + ln.Addr().String())
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, 2, resp.ProtoMajor)
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestServeMaxBodyBytes(t *testing.T) {
+	p, err := New(WithMaxBodyBytes(16))
+	require.NoError(t, err)
+	errs := make(chan error, 1)
+	go func() {
+		for {
+			m, fn, err := p.Respond(context.Background())
+			if err != nil {
+				return
+			}
+			_, err = binding.ToEvent(context.Background(), m)
+			errs <- err
+			_ = fn(context.Background(), nil, err)
+			_ = m.Finish(err)
+		}
+	}()
+
+	newRequest := func() *http.Request {
+		e := event.New()
+		e.SetID("1")
+		e.SetSource("/source")
+		e.SetType("max.body")
+		require.NoError(t, e.SetData(event.ApplicationJSON, strings.Repeat("x", 32)))
+		req, err := NewHTTPRequestFromEvent(context.Background(), "http://unittest", e)
+		require.NoError(t, err)
+		return req
+	}
+
+	// Requests with a known length are rejected before reading the body.
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, newRequest())
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	// Others are when the limit is reached.
+	req := newRequest()
+	req.ContentLength = -1
+	rec = httptest.NewRecorder()
+	p.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	var maxBytesErr *http.MaxBytesError
+	require.ErrorAs(t, <-errs, &maxBytesErr)
+}
@@ -0,0 +1,299 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+const (
+	// DefaultMaxTargetFailures is the number of consecutive failures after
+	// which a target of a TargetPool is ejected.
+	DefaultMaxTargetFailures = 3
+	// DefaultTargetEjectionDuration is the time during which an ejected
+	// target of a TargetPool is not used.
+	DefaultTargetEjectionDuration = 30 * time.Second
+)
+
+// LoadBalancingStrategy selects the target of a TargetPool a request is sent
+// to first.
+type LoadBalancingStrategy int
+
+const (
+	// RoundRobin uses each target in turn.
+	RoundRobin LoadBalancingStrategy = iota
+	// WeightedRoundRobin uses each target in turn, proportionally to its
+	// weight.
+	WeightedRoundRobin
+	// LeastOutstanding uses the target with the fewest requests in flight.
+	LeastOutstanding
+)
+
+// PoolTarget is a target of a TargetPool.
+type PoolTarget struct {
+	// URL is the URL of the target.
+	URL string
+	// Weight is the weight of the target with WeightedRoundRobin. If 0, 1 is
+	// used.
+	Weight int
+}
+
+type poolTarget struct {
+	url    *url.URL
+	weight int
+
+	// current is the current weight of smooth weighted round-robin.
+	current      int
+	outstanding  int
+	failures     int
+	ejectedUntil time.Time
+}
+
+// TargetPool balances the requests of a sender between several targets.
+//
+// Targets are passively health checked: a target is ejected for
+// EjectionDuration after MaxFailures consecutive failures, a failure being a
+// transport error or a 5xx status code. After a failure, the request is sent
+// to the next target. If every target is ejected, they are all used.
+type TargetPool struct {
+	// MaxFailures is the number of consecutive failures after which a target
+	// is ejected. If 0, DefaultMaxTargetFailures is used.
+	MaxFailures int
+	// EjectionDuration is the time during which an ejected target is not
+	// used. If 0, DefaultTargetEjectionDuration is used.
+	EjectionDuration time.Duration
+
+	strategy LoadBalancingStrategy
+
+	mu      sync.Mutex
+	targets []*poolTarget
+	next    int
+	now     func() time.Time
+}
+
+// NewTargetPool returns a pool of targets balanced with strategy.
+func NewTargetPool(strategy LoadBalancingStrategy, targets ...PoolTarget) (*TargetPool, error) {
+	switch strategy {
+	case RoundRobin, WeightedRoundRobin, LeastOutstanding:
+	default:
+		return nil, fmt.Errorf("unknown load balancing strategy %d", strategy)
+	}
+	if len(targets) == 0 {
+		return nil, errors.New("target pool requires at least one target")
+	}
+	tp := &TargetPool{strategy: strategy, now: time.Now}
+	for _, t := range targets {
+		u, err := url.Parse(strings.TrimSpace(t.URL))
+		if err != nil {
+			return nil, fmt.Errorf("target pool failed to parse target url: %w", err)
+		}
+		if t.Weight < 0 {
+			return nil, fmt.Errorf("target pool weight of %s can not be negative", t.URL)
+		}
+		weight := t.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		tp.targets = append(tp.targets, &poolTarget{url: u, weight: weight})
+	}
+	return tp, nil
+}
+
+// Healthy returns the URLs of the targets which are not ejected.
+func (tp *TargetPool) Healthy() []*url.URL {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	var urls []*url.URL
+	for _, t := range tp.healthy(tp.now()) {
+		urls = append(urls, t.url)
+	}
+	return urls
+}
+
+func (tp *TargetPool) healthy(now time.Time) []*poolTarget {
+	var healthy []*poolTarget
+	for _, t := range tp.targets {
+		if !now.Before(t.ejectedUntil) {
+			healthy = append(healthy, t)
+		}
+	}
+	return healthy
+}
+
+// pick returns the targets to try in order: the one selected by the strategy,
+// then the others for failover.
+func (tp *TargetPool) pick() []*poolTarget {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	candidates := tp.healthy(tp.now())
+	if len(candidates) == 0 {
+		candidates = tp.targets
+	}
+
+	first := 0
+	switch tp.strategy {
+	case WeightedRoundRobin:
+		// Smooth weighted round-robin, as in nginx.
+		total := 0
+		for i, t := range candidates {
+			t.current += t.weight
+			total += t.weight
+			if t.current > candidates[first].current {
+				first = i
+			}
+		}
+		candidates[first].current -= total
+	case LeastOutstanding:
+		// Ties are broken by round-robin.
+		start := tp.next % len(candidates)
+		tp.next++
+		first = start
+		for i := range candidates {
+			j := (start + i) % len(candidates)
+			if candidates[j].outstanding < candidates[first].outstanding {
+				first = j
+			}
+		}
+	default:
+		first = tp.next % len(candidates)
+		tp.next++
+	}
+
+	order := make([]*poolTarget, 0, len(candidates))
+	for i := range candidates {
+		order = append(order, candidates[(first+i)%len(candidates)])
+	}
+	return order
+}
+
+func (tp *TargetPool) start(t *poolTarget) {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	t.outstanding++
+}
+
+// done records the outcome of a request to t, ejecting it after too many
+// consecutive failures.
+func (tp *TargetPool) done(t *poolTarget, failed bool) {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	t.outstanding--
+	if !failed {
+		t.failures = 0
+		return
+	}
+	t.failures++
+	maxFailures := tp.MaxFailures
+	if maxFailures <= 0 {
+		maxFailures = DefaultMaxTargetFailures
+	}
+	if t.failures >= maxFailures {
+		ejection := tp.EjectionDuration
+		if ejection <= 0 {
+			ejection = DefaultTargetEjectionDuration
+		}
+		t.ejectedUntil = tp.now().Add(ejection)
+	}
+}
+
+// isTargetFailure returns whether res shows that the target is unhealthy,
+// rather than the request being invalid.
+func isTargetFailure(res protocol.Result) bool {
+	if protocol.IsACK(res) {
+		return false
+	}
+	var retries *RetriesResult
+	if errors.As(res, &retries) {
+		res = retries.Result
+	}
+	var result *Result
+	if !protocol.ResultAs(res, &result) {
+		// Transport error.
+		return true
+	}
+	return result.StatusCode/100 == 5
+}
+
+// canFailOver returns whether req, which failed on a target with res, can be
+// sent to another one. As the failed target might have received it, this
+// follows the same rules as retries: the status code must be retriable, and
+// transport errors are subject to the IdempotencyPolicy.
+func (p *Protocol) canFailOver(req *http.Request, res protocol.Result) bool {
+	var retries *RetriesResult
+	if errors.As(res, &retries) {
+		res = retries.Result
+	}
+	var result *Result
+	if protocol.ResultAs(res, &result) {
+		return p.isRetriableFunc(result.StatusCode)
+	}
+	return p.isRetriableTransportError(req, res)
+}
+
+// requestPool sends req to the targets of the pool, failing over to the next
+// target while they fail and the request can be sent again.
+func (p *Protocol) requestPool(ctx context.Context, m binding.Message, req *http.Request) (binding.Message, protocol.Result) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	targets := p.targetPool.pick()
+	var (
+		msg binding.Message
+		res protocol.Result
+	)
+	for i, t := range targets {
+		r := req.Clone(ctx)
+		r.URL = t.url
+		// The Host of the request template is not the one of the target.
+		r.Host = ""
+		r.GetBody = nil
+		resetBody(r, body)
+
+		// Failing to authenticate or sign the request does not make the
+		// target unhealthy.
+		if err := p.prepareRequest(ctx, m, r); err != nil {
+			return nil, err
+		}
+		p.targetPool.start(t)
+		msg, res = p.doRequest(ctx, r)
+		failed := isTargetFailure(res)
+		p.targetPool.done(t, failed)
+		if !failed || i == len(targets)-1 || ctx.Err() != nil || !p.canFailOver(r, res) {
+			break
+		}
+
+		cecontext.LoggerFrom(ctx).Debugw("target failed, failing over to the next one",
+			zap.String("target", t.url.String()),
+			zap.Error(res))
+		if msg != nil {
+			// avoid leak, forget message, ignore error
+			_ = msg.Finish(nil)
+		}
+	}
+	return msg, res
+}
@@ -0,0 +1,269 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func newTestTargetPool(t *testing.T, strategy LoadBalancingStrategy, targets ...PoolTarget) *TargetPool {
+	tp, err := NewTargetPool(strategy, targets...)
+	require.NoError(t, err)
+	return tp
+}
+
+// firstHosts returns the host of the first target picked by n requests.
+func firstHosts(tp *TargetPool, n int) []string {
+	var hosts []string
+	for i := 0; i < n; i++ {
+		hosts = append(hosts, tp.pick()[0].url.Host)
+	}
+	return hosts
+}
+
+func TestTargetPool_pick(t *testing.T) {
+	testCases := map[string]struct {
+		strategy LoadBalancingStrategy
+		targets  []PoolTarget
+		want     []string
+	}{
+		"round robin": {
+			strategy: RoundRobin,
+			targets:  []PoolTarget{{URL: "http://a"}, {URL: "http://b"}, {URL: "http://c"}},
+			want:     []string{"a", "b", "c", "a"},
+		},
+		"weighted": {
+			strategy: WeightedRoundRobin,
+			targets:  []PoolTarget{{URL: "http://a", Weight: 3}, {URL: "http://b"}},
+			want:     []string{"a", "a", "b", "a", "a", "a", "b", "a"},
+		},
+		"least outstanding without load": {
+			strategy: LeastOutstanding,
+			targets:  []PoolTarget{{URL: "http://a"}, {URL: "http://b"}},
+			want:     []string{"a", "b", "a"},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			tp := newTestTargetPool(t, tc.strategy, tc.targets...)
+			require.Equal(t, tc.want, firstHosts(tp, len(tc.want)))
+			require.Len(t, tp.pick(), len(tc.targets))
+		})
+	}
+
+	_, err := NewTargetPool(RoundRobin)
+	require.Error(t, err)
+	_, err = NewTargetPool(LoadBalancingStrategy(42), PoolTarget{URL: "http://a"})
+	require.Error(t, err)
+	_, err = NewTargetPool(WeightedRoundRobin, PoolTarget{URL: "http://a", Weight: -1})
+	require.Error(t, err)
+}
+
+func TestTargetPool_leastOutstanding(t *testing.T) {
+	tp := newTestTargetPool(t, LeastOutstanding, PoolTarget{URL: "http://a"}, PoolTarget{URL: "http://b"})
+	a := tp.pick()[0]
+	tp.start(a)
+	require.Equal(t, []string{"b", "b"}, firstHosts(tp, 2))
+
+	// Ties are broken by round-robin.
+	tp.done(a, false)
+	require.ElementsMatch(t, []string{"a", "b"}, firstHosts(tp, 2))
+}
+
+func TestTargetPool_ejection(t *testing.T) {
+	clock := newTestClock()
+	tp := newTestTargetPool(t, RoundRobin, PoolTarget{URL: "http://a"}, PoolTarget{URL: "http://b"})
+	tp.MaxFailures = 2
+	tp.EjectionDuration = time.Minute
+	tp.now = clock.Now
+	a := tp.targets[0]
+	hosts := func() []string {
+		var hosts []string
+		for _, u := range tp.Healthy() {
+			hosts = append(hosts, u.Host)
+		}
+		return hosts
+	}
+
+	// Failures must be consecutive.
+	for _, failed := range []bool{true, false, true} {
+		tp.start(a)
+		tp.done(a, failed)
+	}
+	require.Equal(t, []string{"a", "b"}, hosts())
+
+	tp.start(a)
+	tp.done(a, true)
+	require.Equal(t, []string{"b"}, hosts())
+	require.Equal(t, []string{"b", "b"}, firstHosts(tp, 2))
+
+	clock.Add(time.Minute)
+	require.Equal(t, []string{"a", "b"}, hosts())
+
+	// Every target is used when all are ejected.
+	tp.MaxFailures = 1
+	for _, target := range tp.targets {
+		tp.start(target)
+		tp.done(target, true)
+	}
+	require.Empty(t, hosts())
+	require.Len(t, tp.pick(), 2)
+}
+
+func TestSendTargetPool(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests = map[string]int{}
+	)
+	newServer := func(name string, status int) *httptest.Server {
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			mu.Lock()
+			requests[name]++
+			mu.Unlock()
+			rw.WriteHeader(status)
+		}))
+		t.Cleanup(s.Close)
+		return s
+	}
+	down := newServer("down", http.StatusServiceUnavailable)
+	up := newServer("up", http.StatusAccepted)
+	invalid := newServer("invalid", http.StatusBadRequest)
+
+	pool := newTestTargetPool(t, RoundRobin, PoolTarget{URL: down.URL}, PoolTarget{URL: up.URL})
+	pool.MaxFailures = 2
+	p, err := New(WithTargetPool(pool))
+	require.NoError(t, err)
+
+	// Requests to the failing target fail over to the other one, until it is
+	// ejected.
+	for i := 0; i < 6; i++ {
+		err = p.Send(context.Background(), newWebhookTestEvent())
+		require.True(t, protocol.IsACK(err), "got %v", err)
+	}
+	require.Equal(t, map[string]int{"down": 2, "up": 6}, requests)
+	downURL, err := url.Parse(down.URL)
+	require.NoError(t, err)
+	require.NotContains(t, pool.Healthy(), downURL)
+
+	// Invalid requests do not fail over.
+	requests = map[string]int{}
+	p, err = New(WithTargets(RoundRobin, invalid.URL, up.URL))
+	require.NoError(t, err)
+	err = p.Send(context.Background(), newWebhookTestEvent())
+	var result *Result
+	require.True(t, protocol.ResultAs(err, &result), "got %v", err)
+	require.Equal(t, http.StatusBadRequest, result.StatusCode)
+	require.Equal(t, map[string]int{"invalid": 1}, requests)
+
+	// The target of the context takes precedence.
+	requests = map[string]int{}
+	err = p.Send(cecontext.WithTarget(context.Background(), invalid.URL), newWebhookTestEvent())
+	require.False(t, protocol.IsACK(err))
+	require.Equal(t, map[string]int{"invalid": 1}, requests)
+}
+
+func TestSendTargetPool_failoverPolicy(t *testing.T) {
+	var requests int32
+	up := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer up.Close()
+	internalError := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer internalError.Close()
+	// resetServer resets the connections after reading the requests.
+	resetServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		conn, _, err := rw.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		_ = conn.(*net.TCPConn).SetLinger(0)
+		_ = conn.Close()
+	}))
+	defer resetServer.Close()
+	// closed is the address of a closed listener, which refuses connections.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closed := "http://" + ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	testCases := map[string]struct {
+		target       string
+		opts         []Option
+		wantFailover bool
+	}{
+		"not retriable status":            {target: internalError.URL},
+		"retriable status":                {target: internalError.URL, opts: []Option{WithIsRetriableFunc(func(int) bool { return true })}, wantFailover: true},
+		"all, reset":                      {target: resetServer.URL, wantFailover: true},
+		"unsent, reset":                   {target: resetServer.URL, opts: []Option{WithIdempotencyPolicy(RetryUnsentRequests)}},
+		"unsent, connection refused":      {target: closed, opts: []Option{WithIdempotencyPolicy(RetryUnsentRequests)}, wantFailover: true},
+		"idempotent, connection refused":  {target: closed, opts: []Option{WithIdempotencyPolicy(RetryIdempotentRequests)}, wantFailover: true},
+		"idempotent, reset without a key": {target: resetServer.URL, opts: []Option{WithIdempotencyPolicy(RetryIdempotentRequests)}},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			atomic.StoreInt32(&requests, 0)
+			pool := newTestTargetPool(t, RoundRobin, PoolTarget{URL: tc.target}, PoolTarget{URL: up.URL})
+			p, err := New(append([]Option{WithTargetPool(pool)}, tc.opts...)...)
+			require.NoError(t, err)
+			err = p.Send(context.Background(), newWebhookTestEvent())
+			require.Equal(t, tc.wantFailover, protocol.IsACK(err), "got %v", err)
+			if tc.wantFailover {
+				require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+			} else {
+				require.Equal(t, int32(0), atomic.LoadInt32(&requests))
+			}
+		})
+	}
+}
+
+type failingAuthenticator struct {
+	err error
+}
+
+func (a failingAuthenticator) Authenticate(*http.Request) error {
+	return a.err
+}
+
+func TestSendTargetPool_request(t *testing.T) {
+	hosts := make(chan string, 1)
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		hosts <- req.Host
+	}))
+	defer s.Close()
+	target, err := url.Parse(s.URL)
+	require.NoError(t, err)
+
+	// The Host of the request template is not sent to the targets.
+	pool := newTestTargetPool(t, RoundRobin, PoolTarget{URL: s.URL})
+	p, err := New(WithTargetPool(pool), WithHeader("X-Test", "test"))
+	require.NoError(t, err)
+	p.RequestTemplate.Host = "template.example.com"
+	err = p.Send(context.Background(), newWebhookTestEvent())
+	require.True(t, protocol.IsACK(err), "got %v", err)
+	require.Equal(t, target.Host, <-hosts)
+
+	// Failing to authenticate the requests does not eject the target.
+	failed := errors.New("no credentials")
+	pool.MaxFailures = 1
+	p, err = New(WithTargetPool(pool), WithAuthenticator(failingAuthenticator{err: failed}))
+	require.NoError(t, err)
+	require.ErrorIs(t, p.Send(context.Background(), newWebhookTestEvent()), failed)
+	require.Equal(t, []*url.URL{target}, pool.Healthy())
+}
@@ -7,6 +7,7 @@ package http
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -21,7 +22,9 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
 	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/test"
 )
 
 func TestNew(t *testing.T) {
@@ -248,6 +251,69 @@ func TestServeHTTP_Receive(t *testing.T) {
 	}
 }
 
+func TestServeHTTP_ResponseStatus(t *testing.T) {
+	testCases := map[string]struct {
+		result     protocol.Result
+		wantStatus int
+		wantBody   string
+	}{
+		"ack":        {result: nil, wantStatus: http.StatusOK},
+		"result":     {result: NewResult(http.StatusNotFound, "%s not found", "a"), wantStatus: http.StatusNotFound, wantBody: "a not found"},
+		"validation": {result: event.ValidationError{"id": errors.New("missing")}, wantStatus: http.StatusBadRequest, wantBody: "id: missing\n"},
+		"encoding":   {result: binding.ErrUnknownEncoding, wantStatus: http.StatusUnsupportedMediaType},
+		"error":      {result: errors.New("failed"), wantStatus: http.StatusInternalServerError},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			p, err := New()
+			require.NoError(t, err)
+			go func() {
+				m, fn, err := p.Respond(context.Background())
+				if err != nil {
+					return
+				}
+				_ = m.Finish(nil)
+				_ = fn(context.Background(), nil, tc.result)
+			}()
+			rec := httptest.NewRecorder()
+			p.ServeHTTP(rec, httptest.NewRequest("POST", "http://unittest", nil))
+			require.Equal(t, tc.wantStatus, rec.Code)
+			require.Equal(t, tc.wantBody, rec.Body.String())
+			if tc.wantStatus == http.StatusBadRequest {
+				require.Equal(t, "text/plain", rec.Header().Get(ContentType))
+			}
+		})
+	}
+}
+
+func TestServeHTTP_FinishResult(t *testing.T) {
+	testCases := map[string]struct {
+		result     error
+		wantStatus int
+	}{
+		"nil":   {result: nil, wantStatus: http.StatusOK},
+		"ack":   {result: protocol.ResultACK, wantStatus: http.StatusOK},
+		"nack":  {result: protocol.ResultNACK, wantStatus: http.StatusInternalServerError},
+		"error": {result: errors.New("failed"), wantStatus: http.StatusInternalServerError},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			p, err := New()
+			require.NoError(t, err)
+			go func() {
+				m, err := p.Receive(context.Background())
+				if err != nil {
+					return
+				}
+				_ = m.Finish(tc.result)
+			}()
+			rec := httptest.NewRecorder()
+			p.ServeHTTP(rec, httptest.NewRequest("POST", "http://unittest", nil))
+			require.Equal(t, tc.wantStatus, rec.Code)
+		})
+	}
+}
+
 func ReceiveTest(t *testing.T, p *Protocol, ctx context.Context, rec *httptest.ResponseRecorder, want binding.Message, wantErr string) {
 	got, err := p.Receive(ctx)
 	if wantErr != "" {
@@ -415,3 +481,25 @@ func TestDefaultIsRetriable(t *testing.T) {
 		})
 	}
 }
+
+func TestConformance(t *testing.T) {
+	newProtocols := func(t *testing.T) (*Protocol, *Protocol) {
+		receiver, err := New()
+		require.NoError(t, err)
+		server := httptest.NewServer(receiver)
+		t.Cleanup(server.Close)
+		sender, err := New(WithTarget(server.URL))
+		require.NoError(t, err)
+		return sender, receiver
+	}
+	test.RunConformance(t, test.Conformance{
+		NewSenderReceiver: func(t *testing.T) (protocol.Sender, protocol.Receiver) {
+			return newProtocols(t)
+		},
+		NewRequesterResponder: func(t *testing.T) (protocol.Requester, protocol.Responder) {
+			return newProtocols(t)
+		},
+		// Extensions are sent as headers.
+		StringExtensions: true,
+	})
+}
@@ -0,0 +1,483 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+const (
+	// DefaultPullAckTimeout is the time a consumer of a PullServer has to
+	// acknowledge the events it pulled before they are delivered again.
+	DefaultPullAckTimeout = 30 * time.Second
+	// DefaultPullMaxWait is the maximum time a pull request waits for events.
+	DefaultPullMaxWait = 30 * time.Second
+	// DefaultPullMaxBatchSize is the maximum number of events of a pull
+	// response.
+	DefaultPullMaxBatchSize = 100
+	// DefaultPullBufferSize is the maximum number of events a PullServer
+	// buffers for a consumer.
+	DefaultPullBufferSize = 1000
+	// DefaultPullConsumerIdleTimeout is the time after which a PullServer
+	// removes the consumers registered by pull requests which stopped
+	// pulling.
+	DefaultPullConsumerIdleTimeout = 10 * time.Minute
+	// DefaultPullMaxConsumers is the maximum number of consumers a
+	// PullServer registers with pull requests.
+	DefaultPullMaxConsumers = 100
+	// DefaultPullMaxAckBytes is the maximum size of the body of an
+	// acknowledgement request.
+	DefaultPullMaxAckBytes = 1 << 20
+
+	// PullDeliveryIDExtension is the extension holding the delivery id the
+	// PullServer assigned to a pulled event, to acknowledge it.
+	PullDeliveryIDExtension = "pulldeliveryid"
+
+	// Query parameters of pull requests.
+	pullConsumerParam  = "consumer"
+	pullBatchSizeParam = "max"
+	pullWaitParam      = "wait"
+)
+
+var (
+	_ protocol.Sender = (*PullServer)(nil)
+	_ http.Handler    = (*PullServer)(nil)
+
+	// ErrPullBufferFull is returned by PullServer.Send when the buffer of a
+	// consumer is full.
+	ErrPullBufferFull = errors.New("pull buffer is full")
+
+	errUnknownPullConsumer  = errors.New("unknown consumer")
+	errTooManyPullConsumers = errors.New("too many consumers")
+)
+
+// PullAck is the body of the request acknowledging pulled events. Events
+// are identified by their delivery id, in their PullDeliveryIDExtension
+// extension. Nacked events are delivered again right away.
+type PullAck struct {
+	Ack  []string `json:"ack,omitempty"`
+	Nack []string `json:"nack,omitempty"`
+}
+
+type pullEvent struct {
+	event    event.Event
+	deadline time.Time
+}
+
+type pullConsumer struct {
+	ready  []*pullEvent
+	leased map[string]*pullEvent
+	// notify is closed when events are ready.
+	notify chan struct{}
+
+	// static consumers are not removed when idle.
+	static   bool
+	pulling  int
+	lastSeen time.Time
+}
+
+// PullServer buffers the events it sends for each of its consumers, which pull
+// them with HTTP requests, for consumers which cannot receive requests. It
+// serves:
+//   - GET ?consumer=<name>&max=<n>&wait=<duration>: returns up to max ready
+//     events in the application/cloudevents-batch+json format, waiting up to
+//     wait for at least one, or 204 No Content.
+//   - POST ?consumer=<name>, with a JSON PullAck body: acknowledges pulled
+//     events.
+//
+// Pulled events which are not acknowledged within AckTimeout are delivered
+// again. Consumers are registered by their first pull request or with
+// AddConsumers; events sent while there are no consumers are dropped.
+// Consumers registered by a pull request are removed, with their events, once
+// they have not pulled for ConsumerIdleTimeout, the others with
+// RemoveConsumers. The consumers of pull requests are not authenticated: use
+// StaticConsumers to only serve the ones added with AddConsumers.
+type PullServer struct {
+	// AckTimeout is the time consumers have to acknowledge pulled events. If
+	// 0, DefaultPullAckTimeout is used.
+	AckTimeout time.Duration
+	// MaxWait is the maximum time a pull request waits. If 0,
+	// DefaultPullMaxWait is used.
+	MaxWait time.Duration
+	// MaxBatchSize is the maximum number of events of a response. If 0,
+	// DefaultPullMaxBatchSize is used.
+	MaxBatchSize int
+	// BufferSize is the maximum number of events buffered for a consumer,
+	// including the pulled ones waiting for an acknowledgement. If 0,
+	// DefaultPullBufferSize is used.
+	BufferSize int
+	// ConsumerIdleTimeout is the time after which the consumers registered
+	// by a pull request which stopped pulling are removed. If 0,
+	// DefaultPullConsumerIdleTimeout is used.
+	ConsumerIdleTimeout time.Duration
+	// MaxConsumers is the maximum number of consumers registered by pull
+	// requests. Pull requests of other consumers are rejected with 429 Too
+	// Many Requests. If 0, DefaultPullMaxConsumers is used.
+	MaxConsumers int
+	// StaticConsumers rejects the pull requests of the consumers which were
+	// not added with AddConsumers or NewPullServer with 404 Not Found.
+	StaticConsumers bool
+	// MaxAckBytes is the maximum size of the body of an acknowledgement
+	// request. Larger requests are rejected with 413 Request Entity Too
+	// Large. If 0, DefaultPullMaxAckBytes is used.
+	MaxAckBytes int64
+
+	mu         sync.Mutex
+	consumers  map[string]*pullConsumer
+	deliveries uint64
+	now        func() time.Time
+}
+
+// NewPullServer returns a PullServer for consumers.
+func NewPullServer(consumers ...string) *PullServer {
+	s := &PullServer{consumers: make(map[string]*pullConsumer), now: time.Now}
+	s.AddConsumers(consumers...)
+	return s
+}
+
+// AddConsumers registers consumers, so that events are buffered for them
+// before their first pull request, until they are removed with
+// RemoveConsumers.
+func (s *PullServer) AddConsumers(consumers ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range consumers {
+		s.consumer(name).static = true
+	}
+}
+
+// RemoveConsumers removes consumers and drops their events.
+func (s *PullServer) RemoveConsumers(consumers ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range consumers {
+		if c, ok := s.consumers[name]; ok {
+			delete(s.consumers, name)
+			// Let the waiting pull requests return.
+			c.wake()
+		}
+	}
+}
+
+func (s *PullServer) consumer(name string) *pullConsumer {
+	c, ok := s.consumers[name]
+	if !ok {
+		c = &pullConsumer{leased: make(map[string]*pullEvent), notify: make(chan struct{}), lastSeen: s.now()}
+		s.consumers[name] = c
+	}
+	return c
+}
+
+// pullConsumer returns the consumer of a pull request, registering it if
+// allowed. It must be called with mu held.
+func (s *PullServer) pullConsumer(name string) (*pullConsumer, error) {
+	if c, ok := s.consumers[name]; ok {
+		return c, nil
+	}
+	if s.StaticConsumers {
+		return nil, errUnknownPullConsumer
+	}
+	maxConsumers := s.MaxConsumers
+	if maxConsumers <= 0 {
+		maxConsumers = DefaultPullMaxConsumers
+	}
+	dynamic := 0
+	for _, c := range s.consumers {
+		if !c.static {
+			dynamic++
+		}
+	}
+	if dynamic >= maxConsumers {
+		return nil, errTooManyPullConsumers
+	}
+	return s.consumer(name), nil
+}
+
+// removeIdle removes the consumers registered by a pull request which
+// stopped pulling. It must be called with mu held.
+func (s *PullServer) removeIdle(now time.Time) {
+	idleTimeout := s.ConsumerIdleTimeout
+	if idleTimeout <= 0 {
+		idleTimeout = DefaultPullConsumerIdleTimeout
+	}
+	for name, c := range s.consumers {
+		if !c.static && c.pulling == 0 && now.Sub(c.lastSeen) >= idleTimeout {
+			delete(s.consumers, name)
+		}
+	}
+}
+
+// Send implements binding.Sender. The event is buffered for every consumer;
+// ErrPullBufferFull is returned if it could not be buffered for some of
+// them.
+func (s *PullServer) Send(ctx context.Context, m binding.Message, transformers ...binding.Transformer) (err error) {
+	if ctx == nil {
+		return fmt.Errorf("nil Context")
+	} else if m == nil {
+		return fmt.Errorf("nil Message")
+	}
+	defer func() { _ = m.Finish(err) }()
+
+	e, err := binding.ToEvent(ctx, m, transformers...)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeIdle(s.now())
+	bufferSize := s.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = DefaultPullBufferSize
+	}
+	var full []string
+	for name, c := range s.consumers {
+		if len(c.ready)+len(c.leased) >= bufferSize {
+			full = append(full, name)
+			continue
+		}
+		s.deliveries++
+		pe := &pullEvent{event: e.Clone()}
+		pe.event.SetExtension(PullDeliveryIDExtension, strconv.FormatUint(s.deliveries, 10))
+		c.ready = append(c.ready, pe)
+		c.wake()
+	}
+	if len(full) > 0 {
+		return fmt.Errorf("%w for consumers %v", ErrPullBufferFull, full)
+	}
+	return nil
+}
+
+// wake notifies the waiting pull requests that events are ready.
+func (c *pullConsumer) wake() {
+	close(c.notify)
+	c.notify = make(chan struct{})
+}
+
+// expire makes the leased events past their deadline ready again, and returns
+// the earliest deadline of the others.
+func (c *pullConsumer) expire(now time.Time) time.Time {
+	var next time.Time
+	for id, pe := range c.leased {
+		if !now.Before(pe.deadline) {
+			delete(c.leased, id)
+			c.ready = append([]*pullEvent{pe}, c.ready...)
+		} else if next.IsZero() || pe.deadline.Before(next) {
+			next = pe.deadline
+		}
+	}
+	return next
+}
+
+// ServeHTTP serves pull and acknowledgement requests.
+func (s *PullServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	name := req.URL.Query().Get(pullConsumerParam)
+	if name == "" {
+		http.Error(rw, "Missing consumer parameter", http.StatusBadRequest)
+		return
+	}
+	switch req.Method {
+	case http.MethodGet:
+		s.servePull(rw, req, name)
+	case http.MethodPost:
+		s.serveAck(rw, req, name)
+	default:
+		rw.Header().Set("Allow", "GET, POST")
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *PullServer) servePull(rw http.ResponseWriter, req *http.Request, name string) {
+	maxBatchSize := s.MaxBatchSize
+	if maxBatchSize <= 0 {
+		maxBatchSize = DefaultPullMaxBatchSize
+	}
+	batchSize := maxBatchSize
+	if v := req.URL.Query().Get(pullBatchSizeParam); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(rw, fmt.Sprintf("Invalid %s parameter %q", pullBatchSizeParam, v), http.StatusBadRequest)
+			return
+		}
+		if n < batchSize {
+			batchSize = n
+		}
+	}
+	maxWait := s.MaxWait
+	if maxWait <= 0 {
+		maxWait = DefaultPullMaxWait
+	}
+	var wait time.Duration
+	if v := req.URL.Query().Get(pullWaitParam); v != "" {
+		var err error
+		if wait, err = time.ParseDuration(v); err != nil || wait < 0 {
+			http.Error(rw, fmt.Sprintf("Invalid %s parameter %q", pullWaitParam, v), http.StatusBadRequest)
+			return
+		}
+		if wait > maxWait {
+			wait = maxWait
+		}
+	}
+
+	events, err := s.pull(req.Context(), name, batchSize, wait)
+	switch {
+	case errors.Is(err, errUnknownPullConsumer):
+		http.Error(rw, fmt.Sprintf("Unknown consumer %q", name), http.StatusNotFound)
+		return
+	case errors.Is(err, errTooManyPullConsumers):
+		http.Error(rw, "Too many consumers", http.StatusTooManyRequests)
+		return
+	}
+	if len(events) == 0 {
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+	rw.Header().Set(ContentType, event.ApplicationCloudEventsBatchJSON)
+	_ = json.NewEncoder(rw).Encode(events)
+}
+
+// pull leases up to n ready events of a consumer, waiting up to wait for at
+// least one.
+func (s *PullServer) pull(ctx context.Context, name string, n int, wait time.Duration) ([]event.Event, error) {
+	s.mu.Lock()
+	s.removeIdle(s.now())
+	c, err := s.pullConsumer(name)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	c.pulling++
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		c.pulling--
+		c.lastSeen = s.now()
+		s.mu.Unlock()
+	}()
+
+	timeout := time.NewTimer(wait)
+	defer timeout.Stop()
+	for {
+		s.mu.Lock()
+		now := s.now()
+		next := c.expire(now)
+		if len(c.ready) > 0 {
+			events := s.lease(c, n, now)
+			s.mu.Unlock()
+			return events, nil
+		}
+		notify := c.notify
+		s.mu.Unlock()
+
+		// Wake up when a leased event expires.
+		var expiry *time.Timer
+		var expired <-chan time.Time
+		if !next.IsZero() {
+			expiry = time.NewTimer(next.Sub(now))
+			expired = expiry.C
+		}
+		select {
+		case <-notify:
+		case <-expired:
+		case <-timeout.C:
+			return nil, nil
+		case <-ctx.Done():
+			return nil, nil
+		}
+		if expiry != nil {
+			expiry.Stop()
+		}
+	}
+}
+
+func (s *PullServer) lease(c *pullConsumer, n int, now time.Time) []event.Event {
+	ackTimeout := s.AckTimeout
+	if ackTimeout <= 0 {
+		ackTimeout = DefaultPullAckTimeout
+	}
+	if n > len(c.ready) {
+		n = len(c.ready)
+	}
+	events := make([]event.Event, 0, n)
+	for _, pe := range c.ready[:n] {
+		pe.deadline = now.Add(ackTimeout)
+		c.leased[deliveryID(pe.event)] = pe
+		events = append(events, pe.event)
+	}
+	c.ready = c.ready[n:]
+	return events
+}
+
+func (s *PullServer) serveAck(rw http.ResponseWriter, req *http.Request, name string) {
+	maxBytes := s.MaxAckBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultPullMaxAckBytes
+	}
+	var ack PullAck
+	if err := json.NewDecoder(http.MaxBytesReader(rw, req.Body, maxBytes)).Decode(&ack); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(rw, "Acknowledgement too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(rw, fmt.Sprintf("Invalid acknowledgement: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.consumers[name]
+	if !ok {
+		// The consumer was removed with its events.
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+	c.lastSeen = s.now()
+	acked := make(map[string]bool, len(ack.Ack))
+	for _, id := range ack.Ack {
+		delete(c.leased, id)
+		acked[id] = true
+	}
+	if len(acked) > 0 {
+		// An acknowledgement can arrive after the event expired.
+		ready := c.ready[:0]
+		for _, pe := range c.ready {
+			if !acked[deliveryID(pe.event)] {
+				ready = append(ready, pe)
+			}
+		}
+		c.ready = ready
+	}
+	nacked := false
+	for _, id := range ack.Nack {
+		if pe, ok := c.leased[id]; ok {
+			delete(c.leased, id)
+			c.ready = append([]*pullEvent{pe}, c.ready...)
+			nacked = true
+		}
+	}
+	if nacked {
+		c.wake()
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// deliveryID returns the delivery id of a pulled event.
+func deliveryID(e event.Event) string {
+	id, _ := e.Extensions()[PullDeliveryIDExtension].(string)
+	return id
+}
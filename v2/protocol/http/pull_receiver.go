@@ -0,0 +1,302 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+const (
+	// DefaultPullBatchSize is the number of events a PullReceiver asks for.
+	DefaultPullBatchSize = 10
+	// DefaultPullWaitTime is the time a PullReceiver asks the server to wait
+	// for events.
+	DefaultPullWaitTime = 20 * time.Second
+	// DefaultPullErrorDelay is the time a PullReceiver waits before
+	// returning an error, so that callers receiving in a loop do not flood
+	// the server.
+	DefaultPullErrorDelay = time.Second
+	// DefaultPullAckRequestTimeout is the time a PullReceiver waits for the
+	// server to answer the acknowledgement of a finished message.
+	DefaultPullAckRequestTimeout = 5 * time.Second
+)
+
+var (
+	_ protocol.Receiver = (*PullReceiver)(nil)
+	_ protocol.Closer   = (*PullReceiver)(nil)
+)
+
+// PullReceiver receives events by pulling them from a PullServer. Finishing
+// a received message acknowledges it with a request to the server; finishing
+// it with an error which is not an ACK makes the server deliver it again.
+type PullReceiver struct {
+	// Client is the client used for the requests. If nil, http.DefaultClient
+	// is used.
+	Client *http.Client
+	// BatchSize is the maximum number of events of a pull request. If 0,
+	// DefaultPullBatchSize is used.
+	BatchSize int
+	// WaitTime is the time the server waits for events. If 0,
+	// DefaultPullWaitTime is used.
+	WaitTime time.Duration
+	// ErrorDelay is the time waited before returning an error. If 0,
+	// DefaultPullErrorDelay is used.
+	ErrorDelay time.Duration
+	// AckRequestTimeout is the timeout of the acknowledgement request of a
+	// finished message. If 0, DefaultPullAckRequestTimeout is used.
+	AckRequestTimeout time.Duration
+
+	target   string
+	consumer string
+
+	// pullMu serializes pull requests.
+	pullMu  sync.Mutex
+	mu      sync.Mutex
+	pending []event.Event
+	closed  bool
+	done    chan struct{}
+}
+
+// NewPullReceiver returns a receiver pulling the events of consumer from the
+// PullServer at target.
+func NewPullReceiver(target, consumer string) *PullReceiver {
+	return &PullReceiver{target: target, consumer: consumer, done: make(chan struct{})}
+}
+
+// Receive implements protocol.Receiver. It returns io.EOF once the receiver
+// is closed.
+func (r *PullReceiver) Receive(ctx context.Context) (binding.Message, error) {
+	for {
+		if e, ok, err := r.next(); ok || err != nil {
+			if err != nil {
+				return nil, err
+			}
+			// The delivery id is only meaningful to the server.
+			id := deliveryID(e)
+			e.SetExtension(PullDeliveryIDExtension, nil)
+			return binding.WithFinish(binding.ToMessage(&e), func(err error) { r.ack(e, id, err) }), nil
+		}
+
+		if err := r.pull(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if r.isClosed() {
+				return nil, io.EOF
+			}
+			delay := r.ErrorDelay
+			if delay <= 0 {
+				delay = DefaultPullErrorDelay
+			}
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+			case <-r.done:
+			}
+			return nil, err
+		}
+	}
+}
+
+// next returns the next pending event.
+func (r *PullReceiver) next() (event.Event, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return event.Event{}, false, io.EOF
+	}
+	if len(r.pending) == 0 {
+		return event.Event{}, false, nil
+	}
+	e := r.pending[0]
+	r.pending = r.pending[1:]
+	return e, true, nil
+}
+
+func (r *PullReceiver) isClosed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closed
+}
+
+// Close implements protocol.Closer. Pending events which were not received
+// are nacked.
+func (r *PullReceiver) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	close(r.done)
+	pending := r.pending
+	r.pending = nil
+	r.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+	var ack PullAck
+	for _, e := range pending {
+		ack.Nack = append(ack.Nack, deliveryID(e))
+	}
+	return r.sendAck(ctx, ack)
+}
+
+func (r *PullReceiver) client() *http.Client {
+	if r.Client == nil {
+		return http.DefaultClient
+	}
+	return r.Client
+}
+
+func (r *PullReceiver) url(params url.Values) (string, error) {
+	u, err := url.Parse(r.target)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set(pullConsumerParam, r.consumer)
+	for k, v := range params {
+		q[k] = v
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// pull requests events from the server, until it returns some.
+func (r *PullReceiver) pull(ctx context.Context) error {
+	r.pullMu.Lock()
+	defer r.pullMu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-r.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	batchSize := r.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultPullBatchSize
+	}
+	wait := r.WaitTime
+	if wait <= 0 {
+		wait = DefaultPullWaitTime
+	}
+	target, err := r.url(url.Values{
+		pullBatchSizeParam: {strconv.Itoa(batchSize)},
+		pullWaitParam:      {wait.String()},
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Another receiving goroutine might have pulled events meanwhile.
+		r.mu.Lock()
+		pending := len(r.pending)
+		r.mu.Unlock()
+		if pending > 0 {
+			return nil
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", event.ApplicationCloudEventsBatchJSON)
+		resp, err := r.client().Do(req)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode == http.StatusNoContent {
+			resp.Body.Close()
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return NewResult(resp.StatusCode, "pull: %w", protocol.ResultNACK)
+		}
+		events, err := NewEventsFromHTTPResponse(resp)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		r.mu.Lock()
+		r.pending = append(r.pending, events...)
+		r.mu.Unlock()
+	}
+}
+
+// ack acknowledges e, delivered with id, if err is nil or an ACK, and nacks
+// it otherwise.
+func (r *PullReceiver) ack(e event.Event, id string, err error) {
+	var ack PullAck
+	if protocol.IsACK(err) {
+		ack.Ack = append(ack.Ack, id)
+	} else {
+		ack.Nack = append(ack.Nack, id)
+	}
+	timeout := r.AckRequestTimeout
+	if timeout <= 0 {
+		timeout = DefaultPullAckRequestTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := r.sendAck(ctx, ack); err != nil {
+		// The event is delivered again once its acknowledgement timeout
+		// expires.
+		cecontext.LoggerFrom(context.Background()).Warnw("could not acknowledge pulled event",
+			zap.String("id", e.ID()),
+			zap.Error(err))
+	}
+}
+
+func (r *PullReceiver) sendAck(ctx context.Context, ack PullAck) error {
+	target, err := r.url(nil)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(ack)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(ContentType, event.ApplicationJSON)
+	resp, err := r.client().Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("pull acknowledgement: unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
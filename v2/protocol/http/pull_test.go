@@ -0,0 +1,286 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func sendPull(t *testing.T, s *PullServer, ids ...string) {
+	for _, id := range ids {
+		e := newSSETestEvent(id)
+		require.NoError(t, s.Send(context.Background(), binding.ToMessage(&e)))
+	}
+}
+
+// pullIDs pulls the events of consumer a from s and returns their ids.
+func pullIDs(t *testing.T, s *PullServer, query string) (int, []string) {
+	code, ids, _ := pullDeliveries(t, s, query)
+	return code, ids
+}
+
+// pullDeliveries pulls the events of consumer a from s and returns their ids
+// and delivery ids.
+func pullDeliveries(t *testing.T, s *PullServer, query string) (int, []string, []string) {
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://unittest/?consumer=a&"+query, nil))
+	if rec.Code != http.StatusOK {
+		return rec.Code, nil, nil
+	}
+	require.Equal(t, event.ApplicationCloudEventsBatchJSON, rec.Header().Get(ContentType))
+	events, err := NewEventsFromHTTPResponse(rec.Result())
+	require.NoError(t, err)
+	var ids, deliveries []string
+	for _, e := range events {
+		ids = append(ids, e.ID())
+		deliveries = append(deliveries, deliveryID(e))
+	}
+	return rec.Code, ids, deliveries
+}
+
+func ackPull(t *testing.T, s *PullServer, ack, nack []string) {
+	body, err := json.Marshal(PullAck{Ack: ack, Nack: nack})
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "http://unittest/?consumer=a", bytes.NewReader(body)))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestPullServer(t *testing.T) {
+	clock := newTestClock()
+	s := NewPullServer("a")
+	s.AckTimeout = time.Minute
+	s.now = clock.Now
+	sendPull(t, s, "1", "2", "3")
+
+	code, ids, deliveries := pullDeliveries(t, s, "max=2")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, []string{"1", "2"}, ids)
+
+	// Leased events are not delivered to other requests.
+	_, ids = pullIDs(t, s, "")
+	require.Equal(t, []string{"3"}, ids)
+	code, _ = pullIDs(t, s, "wait=10ms")
+	require.Equal(t, http.StatusNoContent, code)
+
+	ackPull(t, s, deliveries[:1], deliveries[1:])
+
+	// Nacked events are delivered again right away, expired ones after the
+	// acknowledgement timeout.
+	_, ids = pullIDs(t, s, "")
+	require.Equal(t, []string{"2"}, ids)
+	clock.Add(time.Minute)
+	_, ids = pullIDs(t, s, "")
+	require.ElementsMatch(t, []string{"2", "3"}, ids)
+
+	for _, query := range []string{"max=0", "max=x", "wait=-1s", "wait=x"} {
+		code, _ = pullIDs(t, s, query)
+		require.Equal(t, http.StatusBadRequest, code, query)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://unittest/", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestPullServer_deliveryID(t *testing.T) {
+	clock := newTestClock()
+	s := NewPullServer("a")
+	s.AckTimeout = time.Minute
+	s.now = clock.Now
+
+	// Events with the same source and id are acknowledged separately.
+	sendPull(t, s, "1", "1")
+	_, ids, deliveries := pullDeliveries(t, s, "")
+	require.Equal(t, []string{"1", "1"}, ids)
+	require.NotEqual(t, deliveries[0], deliveries[1])
+	ackPull(t, s, deliveries[:1], nil)
+
+	clock.Add(time.Minute)
+	_, _, redelivered := pullDeliveries(t, s, "")
+	require.Equal(t, deliveries[1:], redelivered)
+}
+
+func TestPullServer_consumers(t *testing.T) {
+	clock := newTestClock()
+	s := NewPullServer()
+	s.BufferSize = 1
+	s.ConsumerIdleTimeout = time.Minute
+	s.now = clock.Now
+
+	// Consumers which stopped pulling are removed, and do not fill up.
+	code, _ := pullIDs(t, s, "")
+	require.Equal(t, http.StatusNoContent, code)
+	sendPull(t, s, "1")
+	clock.Add(time.Minute)
+	sendPull(t, s, "2")
+	require.Empty(t, s.consumers)
+
+	// Consumers added explicitly are kept until they are removed.
+	s.AddConsumers("a")
+	sendPull(t, s, "1")
+	clock.Add(time.Hour)
+	e := newSSETestEvent("2")
+	require.ErrorIs(t, s.Send(context.Background(), binding.ToMessage(&e)), ErrPullBufferFull)
+	s.RemoveConsumers("a")
+	sendPull(t, s, "2")
+	require.Empty(t, s.consumers)
+}
+
+func TestPullServer_consumerLimits(t *testing.T) {
+	pull := func(s *PullServer, consumer string) int {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://unittest/?consumer="+consumer, nil))
+		return rec.Code
+	}
+
+	// Pull requests register up to MaxConsumers consumers, not counting the
+	// added ones.
+	s := NewPullServer("static")
+	s.MaxConsumers = 2
+	require.Equal(t, http.StatusNoContent, pull(s, "a"))
+	require.Equal(t, http.StatusNoContent, pull(s, "b"))
+	require.Equal(t, http.StatusTooManyRequests, pull(s, "c"))
+	require.Equal(t, http.StatusNoContent, pull(s, "a"))
+	require.Equal(t, http.StatusNoContent, pull(s, "static"))
+	require.Len(t, s.consumers, 3)
+
+	// Only the added consumers are served with StaticConsumers.
+	s = NewPullServer("static")
+	s.StaticConsumers = true
+	require.Equal(t, http.StatusNotFound, pull(s, "a"))
+	require.Equal(t, http.StatusNoContent, pull(s, "static"))
+	require.Len(t, s.consumers, 1)
+}
+
+func TestPullServer_wait(t *testing.T) {
+	s := NewPullServer()
+	go func() {
+		// Wait for the pull request to register the consumer.
+		require.Eventually(t, func() bool {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return len(s.consumers) == 1
+		}, 5*time.Second, time.Millisecond)
+		sendPull(t, s, "1")
+	}()
+	code, ids := pullIDs(t, s, "wait=5s")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, []string{"1"}, ids)
+}
+
+func TestPullServer_bufferFull(t *testing.T) {
+	s := NewPullServer("a", "b")
+	s.BufferSize = 1
+	sendPull(t, s, "1")
+	_, ids := pullIDs(t, s, "")
+	require.Equal(t, []string{"1"}, ids)
+
+	e := newSSETestEvent("2")
+	err := s.Send(context.Background(), binding.ToMessage(&e))
+	require.True(t, errors.Is(err, ErrPullBufferFull))
+	require.Len(t, s.consumers["a"].ready, 0)
+	require.Len(t, s.consumers["b"].ready, 1)
+}
+
+func TestPullReceiver(t *testing.T) {
+	s := NewPullServer("a")
+	server := httptest.NewServer(s)
+	defer server.Close()
+	r := NewPullReceiver(server.URL, "a")
+	r.WaitTime = 10 * time.Millisecond
+
+	receive := func() (string, binding.Message) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		m, err := r.Receive(ctx)
+		require.NoError(t, err)
+		e, err := binding.ToEvent(ctx, m)
+		require.NoError(t, err)
+		require.NotContains(t, e.Extensions(), PullDeliveryIDExtension)
+		return e.ID(), m
+	}
+
+	sendPull(t, s, "1", "2")
+	id, m := receive()
+	require.Equal(t, "1", id)
+	require.NoError(t, m.Finish(nil))
+	id, m = receive()
+	require.Equal(t, "2", id)
+	require.NoError(t, m.Finish(protocol.ResultNACK))
+
+	// The nacked event is delivered again, the acked one is not.
+	id, m = receive()
+	require.Equal(t, "2", id)
+	require.NoError(t, m.Finish(nil))
+	s.mu.Lock()
+	require.Empty(t, s.consumers["a"].leased)
+	require.Empty(t, s.consumers["a"].ready)
+	s.mu.Unlock()
+
+	// Waiting for events stops on Close.
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = r.Close(context.Background())
+	}()
+	_, err := r.Receive(context.Background())
+	require.Equal(t, io.EOF, err)
+}
+
+func TestPullServer_maxAckBytes(t *testing.T) {
+	s := NewPullServer("a")
+	s.MaxAckBytes = 16
+	body, err := json.Marshal(PullAck{Ack: []string{"0123456789", "0123456789"}})
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "http://unittest/?consumer=a", bytes.NewReader(body)))
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestPullReceiver_ackTimeout(t *testing.T) {
+	s := NewPullServer("a")
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost {
+			// The server stops responding to acknowledgements.
+			<-unblock
+			return
+		}
+		s.ServeHTTP(rw, req)
+	}))
+	defer server.Close()
+	defer close(unblock)
+	r := NewPullReceiver(server.URL, "a")
+	r.WaitTime = 10 * time.Millisecond
+	r.AckRequestTimeout = 50 * time.Millisecond
+
+	sendPull(t, s, "1")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	m, err := r.Receive(ctx)
+	require.NoError(t, err)
+	finished := make(chan error, 1)
+	go func() { finished <- m.Finish(nil) }()
+	select {
+	case err := <-finished:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Finish blocked on the acknowledgement")
+	}
+}
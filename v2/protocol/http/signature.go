@@ -0,0 +1,286 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// Headers of the Standard Webhooks signature scheme:
+// https://github.com/standard-webhooks/standard-webhooks/blob/main/spec/standard-webhooks.md
+const (
+	WebhookIDHeader        = "Webhook-Id"
+	WebhookTimestampHeader = "Webhook-Timestamp"
+	WebhookSignatureHeader = "Webhook-Signature"
+
+	webhookSecretPrefix    = "whsec_"
+	webhookSignatureScheme = "v1"
+)
+
+// DefaultWebhookSignatureTolerance is the default maximum difference between
+// the webhook-timestamp of a request and the time it is verified.
+const DefaultWebhookSignatureTolerance = 5 * time.Minute
+
+// ErrInvalidWebhookSignature is wrapped by the results of failed signature
+// verifications, which have the status 401 Unauthorized.
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// ParseWebhookSecret decodes a Standard Webhooks secret: the base64 encoded
+// key, optionally prefixed with "whsec_".
+func ParseWebhookSecret(secret string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, webhookSecretPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook secret: %w", err)
+	}
+	if len(key) == 0 {
+		return nil, errors.New("invalid webhook secret: empty key")
+	}
+	return key, nil
+}
+
+func parseWebhookSecrets(secrets []string) ([][]byte, error) {
+	if len(secrets) == 0 {
+		return nil, errors.New("at least one webhook secret is required")
+	}
+	keys := make([][]byte, 0, len(secrets))
+	for _, s := range secrets {
+		key, err := ParseWebhookSecret(s)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func webhookSignature(key []byte, id string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(id))
+	mac.Write([]byte("."))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return webhookSignatureScheme + "," + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookSigner signs outgoing requests with HMAC-SHA256, following the
+// Standard Webhooks scheme.
+type WebhookSigner struct {
+	keys [][]byte
+	now  func() time.Time
+}
+
+// NewWebhookSigner returns a signer for the given secrets. Each request gets
+// one signature per secret: during a rotation, sign with both the old and the
+// new secret until every receiver knows the new one.
+func NewWebhookSigner(secrets ...string) (*WebhookSigner, error) {
+	keys, err := parseWebhookSecrets(secrets)
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookSigner{keys: keys, now: time.Now}, nil
+}
+
+// Sign returns the webhook-signature header value for body.
+func (s *WebhookSigner) Sign(id string, timestamp time.Time, body []byte) string {
+	sigs := make([]string, 0, len(s.keys))
+	for _, key := range s.keys {
+		sigs = append(sigs, webhookSignature(key, id, timestamp.Unix(), body))
+	}
+	return strings.Join(sigs, " ")
+}
+
+// SignRequest sets the webhook-id, webhook-timestamp and webhook-signature
+// headers of req, reading and restoring its body. If id is empty, a random one
+// is generated.
+func (s *WebhookSigner) SignRequest(req *http.Request, id string) error {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return err
+		}
+		_ = req.Body.Close()
+		resetBody(req, body)
+	}
+	if id == "" {
+		id = uuid.New().String()
+	}
+	now := s.now()
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	req.Header.Set(WebhookIDHeader, id)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(WebhookSignatureHeader, s.Sign(id, now, body))
+	return nil
+}
+
+// WebhookVerifier verifies the Standard Webhooks signature of incoming
+// requests.
+type WebhookVerifier struct {
+	keys [][]byte
+	// Tolerance is the maximum difference between the webhook-timestamp of a
+	// request and the time it is verified, in either direction. If 0,
+	// DefaultWebhookSignatureTolerance is used.
+	Tolerance time.Duration
+	// RejectDuplicates rejects requests whose webhook-id was already verified
+	// within the tolerance window. Note that this also rejects retries of a
+	// request the receiver failed to handle.
+	RejectDuplicates bool
+
+	now       func() time.Time
+	mu        sync.Mutex
+	seen      map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewWebhookVerifier returns a verifier accepting signatures made with any of
+// the given secrets, which allows rotating them without downtime.
+func NewWebhookVerifier(secrets ...string) (*WebhookVerifier, error) {
+	keys, err := parseWebhookSecrets(secrets)
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookVerifier{keys: keys, now: time.Now}, nil
+}
+
+// Verify checks the signature of a request with header and body. It returns
+// nil or a Result with the status 401 Unauthorized, wrapping
+// ErrInvalidWebhookSignature.
+func (v *WebhookVerifier) Verify(header http.Header, body []byte) protocol.Result {
+	id := header.Get(WebhookIDHeader)
+	ts := header.Get(WebhookTimestampHeader)
+	sigs := header.Get(WebhookSignatureHeader)
+	if id == "" || ts == "" || sigs == "" {
+		return NewResult(http.StatusUnauthorized, "%w: missing webhook headers", ErrInvalidWebhookSignature)
+	}
+
+	timestamp, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return NewResult(http.StatusUnauthorized, "%w: invalid timestamp %q", ErrInvalidWebhookSignature, ts)
+	}
+	tolerance := v.Tolerance
+	if tolerance == 0 {
+		tolerance = DefaultWebhookSignatureTolerance
+	}
+	now := v.now()
+	if d := now.Sub(time.Unix(timestamp, 0)); d > tolerance || d < -tolerance {
+		return NewResult(http.StatusUnauthorized, "%w: timestamp out of the tolerance window", ErrInvalidWebhookSignature)
+	}
+
+	if !v.match(id, timestamp, body, sigs) {
+		return NewResult(http.StatusUnauthorized, "%w: no matching signature", ErrInvalidWebhookSignature)
+	}
+
+	if v.RejectDuplicates && !v.firstSeen(id, now, now.Add(2*tolerance)) {
+		return NewResult(http.StatusUnauthorized, "%w: webhook-id %q was already received", ErrInvalidWebhookSignature, id)
+	}
+	return nil
+}
+
+// VerifyRequest verifies the signature of req, reading and restoring its body.
+func (v *WebhookVerifier) VerifyRequest(req *http.Request) protocol.Result {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			status := http.StatusBadRequest
+			if isMaxBytesError(err) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			return NewResult(status, "failed to read the request body: %w", err)
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return v.Verify(req.Header, body)
+}
+
+func (v *WebhookVerifier) match(id string, timestamp int64, body []byte, signatures string) bool {
+	for _, key := range v.keys {
+		expected := []byte(webhookSignature(key, id, timestamp, body))
+		for _, sig := range strings.Fields(signatures) {
+			if hmac.Equal(expected, []byte(sig)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// firstSeen records id until expires, and returns false if it was already
+// recorded.
+func (v *WebhookVerifier) firstSeen(id string, now, expires time.Time) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.seen == nil {
+		v.seen = make(map[string]time.Time)
+	}
+	if now.Sub(v.lastSweep) >= time.Minute {
+		for k, exp := range v.seen {
+			if now.After(exp) {
+				delete(v.seen, k)
+			}
+		}
+		v.lastSweep = now
+	}
+	if exp, ok := v.seen[id]; ok && !now.After(exp) {
+		return false
+	}
+	v.seen[id] = expires
+	return true
+}
+
+// messageID returns the id of the event in m, if it can be read without
+// consuming the message.
+func messageID(m binding.Message) string {
+	if r, ok := m.(binding.MessageMetadataReader); ok {
+		if _, v := r.GetAttribute(spec.ID); v != nil {
+			if s, ok := v.(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// webhookID returns the webhook-id of the requests sending m: a hash of the
+// source and id of its event, which identify it, or else a random id.
+func webhookID(m binding.Message) string {
+	if r, ok := m.(binding.MessageMetadataReader); ok {
+		_, source := r.GetAttribute(spec.Source)
+		_, id := r.GetAttribute(spec.ID)
+		if source != nil && id != nil {
+			if source, err := types.Format(source); err == nil {
+				if id, ok := id.(string); ok && id != "" {
+					sum := sha256.Sum256([]byte(source + "\x00" + id))
+					return hex.EncodeToString(sum[:])
+				}
+			}
+		}
+	}
+	return uuid.New().String()
+}
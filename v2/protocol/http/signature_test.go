@@ -0,0 +1,312 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+var (
+	testWebhookSecret  = "whsec_" + base64.StdEncoding.EncodeToString([]byte("current-secret"))
+	testWebhookSecret2 = base64.StdEncoding.EncodeToString([]byte("previous-secret"))
+)
+
+func TestParseWebhookSecret(t *testing.T) {
+	key, err := ParseWebhookSecret(testWebhookSecret)
+	require.NoError(t, err)
+	require.Equal(t, []byte("current-secret"), key)
+
+	_, err = ParseWebhookSecret("whsec_not base64")
+	require.Error(t, err)
+	_, err = ParseWebhookSecret("whsec_")
+	require.Error(t, err)
+	_, err = NewWebhookSigner()
+	require.Error(t, err)
+}
+
+func TestWebhookSignature(t *testing.T) {
+	// Test vector of the Standard Webhooks specification.
+	key, err := ParseWebhookSecret("whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw")
+	require.NoError(t, err)
+	require.Equal(t,
+		"v1,g0hM9SsE+OTPJTGt/tmIKtSyZlE3uFJELVlNIOLJ1OE=",
+		webhookSignature(key, "msg_p5jXN8AQM9LWM0D4loKWxJek", 1614265330, []byte(`{"test": 2432232314}`)),
+	)
+}
+
+func signedTestHeader(s *WebhookSigner, id string, ts time.Time, body []byte) http.Header {
+	h := http.Header{}
+	h.Set(WebhookIDHeader, id)
+	h.Set(WebhookTimestampHeader, strconv.FormatInt(ts.Unix(), 10))
+	h.Set(WebhookSignatureHeader, s.Sign(id, ts, body))
+	return h
+}
+
+func TestWebhookVerifier(t *testing.T) {
+	clock := newTestClock()
+	body := []byte(`{"id":"abc-123"}`)
+
+	current, err := NewWebhookSigner(testWebhookSecret)
+	require.NoError(t, err)
+	previous, err := NewWebhookSigner(testWebhookSecret2)
+	require.NoError(t, err)
+	rotating, err := NewWebhookSigner(testWebhookSecret2, testWebhookSecret)
+	require.NoError(t, err)
+	other, err := NewWebhookSigner(base64.StdEncoding.EncodeToString([]byte("other-secret")))
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		header  http.Header
+		body    []byte
+		wantErr bool
+	}{
+		"valid": {
+			header: signedTestHeader(current, "1", clock.now, body),
+		},
+		"previous secret": {
+			header: signedTestHeader(previous, "1", clock.now, body),
+		},
+		"signed with both secrets": {
+			header: signedTestHeader(rotating, "1", clock.now, body),
+		},
+		"unknown secret": {
+			header:  signedTestHeader(other, "1", clock.now, body),
+			wantErr: true,
+		},
+		"tampered body": {
+			header:  signedTestHeader(current, "1", clock.now, body),
+			body:    []byte(`{"id":"abc-124"}`),
+			wantErr: true,
+		},
+		"tampered id": {
+			header: func() http.Header {
+				h := signedTestHeader(current, "1", clock.now, body)
+				h.Set(WebhookIDHeader, "2")
+				return h
+			}(),
+			wantErr: true,
+		},
+		"within tolerance": {
+			header: signedTestHeader(current, "1", clock.now.Add(-4*time.Minute), body),
+		},
+		"too old": {
+			header:  signedTestHeader(current, "1", clock.now.Add(-6*time.Minute), body),
+			wantErr: true,
+		},
+		"too far in the future": {
+			header:  signedTestHeader(current, "1", clock.now.Add(6*time.Minute), body),
+			wantErr: true,
+		},
+		"invalid timestamp": {
+			header: func() http.Header {
+				h := signedTestHeader(current, "1", clock.now, body)
+				h.Set(WebhookTimestampHeader, "yesterday")
+				return h
+			}(),
+			wantErr: true,
+		},
+		"missing headers": {
+			header:  http.Header{},
+			wantErr: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			v, err := NewWebhookVerifier(testWebhookSecret, testWebhookSecret2)
+			require.NoError(t, err)
+			v.now = clock.Now
+			if tc.body == nil {
+				tc.body = body
+			}
+
+			res := v.Verify(tc.header, tc.body)
+			if !tc.wantErr {
+				require.Nil(t, res)
+				return
+			}
+			require.True(t, errors.Is(res, ErrInvalidWebhookSignature), "got %v", res)
+			var httpResult *Result
+			require.True(t, protocol.ResultAs(res, &httpResult))
+			require.Equal(t, http.StatusUnauthorized, httpResult.StatusCode)
+		})
+	}
+}
+
+func TestWebhookVerifierRejectDuplicates(t *testing.T) {
+	clock := newTestClock()
+	s, err := NewWebhookSigner(testWebhookSecret)
+	require.NoError(t, err)
+	v, err := NewWebhookVerifier(testWebhookSecret)
+	require.NoError(t, err)
+	v.now = clock.Now
+	v.Tolerance = time.Minute
+	v.RejectDuplicates = true
+
+	h := signedTestHeader(s, "1", clock.now, nil)
+	require.Nil(t, v.Verify(h, nil))
+	require.True(t, errors.Is(v.Verify(h, nil), ErrInvalidWebhookSignature))
+	require.Nil(t, v.Verify(signedTestHeader(s, "2", clock.now, nil), nil))
+
+	// Once out of the replay window, the id is forgotten.
+	clock.Add(3 * time.Minute)
+	require.Nil(t, v.Verify(signedTestHeader(s, "1", clock.now, nil), nil))
+}
+
+func TestServeHTTP_WebhookSignature(t *testing.T) {
+	s, err := NewWebhookSigner(testWebhookSecret)
+	require.NoError(t, err)
+	v, err := NewWebhookVerifier(testWebhookSecret)
+	require.NoError(t, err)
+	p, err := New(WithWebhookVerifier(v))
+	require.NoError(t, err)
+
+	received := make(chan string, 1)
+	go func() {
+		for {
+			m, fn, err := p.Respond(context.Background())
+			if err != nil {
+				return
+			}
+			received <- messageID(m)
+			_ = m.Finish(nil)
+			_ = fn(context.Background(), nil, nil)
+		}
+	}()
+
+	req, err := NewHTTPRequestFromEvent(context.Background(), "http://unittest", newBatchTestEvents("a")[0])
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	require.True(t, strings.Contains(rec.Body.String(), ErrInvalidWebhookSignature.Error()), rec.Body.String())
+
+	req, err = NewHTTPRequestFromEvent(context.Background(), "http://unittest", newBatchTestEvents("a")[0])
+	require.NoError(t, err)
+	require.NoError(t, s.SignRequest(req, "a"))
+	rec = httptest.NewRecorder()
+	p.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "a", <-received)
+}
+
+func TestSendWithWebhookSigner(t *testing.T) {
+	v, err := NewWebhookVerifier(testWebhookSecret)
+	require.NoError(t, err)
+	ids := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if res := v.VerifyRequest(req); res != nil {
+			http.Error(rw, res.Error(), http.StatusUnauthorized)
+			return
+		}
+		ids <- req.Header.Get(WebhookIDHeader)
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	s, err := NewWebhookSigner(testWebhookSecret)
+	require.NoError(t, err)
+	p, err := New(WithTarget(server.URL), WithWebhookSigner(s))
+	require.NoError(t, err)
+
+	err = p.Send(context.Background(), newWebhookTestEvent())
+	require.True(t, protocol.IsACK(err), "got %v", err)
+	require.Equal(t, webhookID(newWebhookTestEvent()), <-ids)
+}
+
+func TestSendWithWebhookSigner_sameIDOtherSource(t *testing.T) {
+	v, err := NewWebhookVerifier(testWebhookSecret)
+	require.NoError(t, err)
+	v.RejectDuplicates = true
+	ids := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if res := v.VerifyRequest(req); res != nil {
+			http.Error(rw, res.Error(), http.StatusUnauthorized)
+			return
+		}
+		ids <- req.Header.Get(WebhookIDHeader)
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	s, err := NewWebhookSigner(testWebhookSecret)
+	require.NoError(t, err)
+	p, err := New(WithTarget(server.URL), WithWebhookSigner(s))
+	require.NoError(t, err)
+
+	// Event ids are only unique per source: events of two sources sharing an
+	// id are not replays.
+	for _, source := range []string{"/source/a", "/source/b"} {
+		e := event.New()
+		e.SetID("abc-123")
+		e.SetSource(source)
+		e.SetType("webhook.test")
+		err = p.Send(context.Background(), binding.ToMessage(&e))
+		require.True(t, protocol.IsACK(err), "got %v", err)
+	}
+	require.NotEqual(t, <-ids, <-ids)
+}
+
+func TestSendWithWebhookSigner_retries(t *testing.T) {
+	v, err := NewWebhookVerifier(testWebhookSecret)
+	require.NoError(t, err)
+	var (
+		mu         sync.Mutex
+		timestamps []string
+		ids        []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if res := v.VerifyRequest(req); res != nil {
+			http.Error(rw, res.Error(), http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		timestamps = append(timestamps, req.Header.Get(WebhookTimestampHeader))
+		ids = append(ids, req.Header.Get(WebhookIDHeader))
+		if len(timestamps) == 1 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	s, err := NewWebhookSigner(testWebhookSecret)
+	require.NoError(t, err)
+	start := time.Now()
+	signatures := 0
+	s.now = func() time.Time {
+		signatures++
+		return start.Add(time.Duration(signatures) * time.Minute)
+	}
+	p, err := New(WithTarget(server.URL), WithWebhookSigner(s))
+	require.NoError(t, err)
+
+	// Retries are signed again, with the same webhook-id.
+	ctx := cecontext.WithRetriesConstantBackoff(context.Background(), time.Nanosecond, 1)
+	err = p.Send(ctx, newWebhookTestEvent())
+	require.True(t, protocol.IsACK(err), "got %v", err)
+	require.Len(t, timestamps, 2)
+	require.NotEqual(t, timestamps[0], timestamps[1])
+	id := webhookID(newWebhookTestEvent())
+	require.Equal(t, []string{id, id}, ids)
+}
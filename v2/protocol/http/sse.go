@@ -0,0 +1,260 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+const (
+	// ContentTypeEventStream is the media type of Server-Sent Events streams.
+	ContentTypeEventStream = "text/event-stream"
+
+	// DefaultSSEReplaySize is the number of events an SSEServer keeps to
+	// resume the streams of reconnecting subscribers.
+	DefaultSSEReplaySize = 100
+	// DefaultSSEHeartbeatInterval is the interval between the comments an
+	// SSEServer writes on idle streams, so that they are not closed by
+	// proxies.
+	DefaultSSEHeartbeatInterval = 15 * time.Second
+
+	// sseSubscriberBuffer is the number of events buffered for a subscriber.
+	// Subscribers too slow to keep up are disconnected, and resume their
+	// stream when they reconnect.
+	sseSubscriberBuffer = 64
+)
+
+var (
+	_ protocol.Sender = (*SSEServer)(nil)
+	_ protocol.Closer = (*SSEServer)(nil)
+	_ http.Handler    = (*SSEServer)(nil)
+)
+
+type sseEvent struct {
+	id   string
+	data []byte
+}
+
+type sseSubscriber struct {
+	events chan sseEvent
+	// done is closed when the subscriber is disconnected.
+	done chan struct{}
+}
+
+// SSEServer serves a Server-Sent Events stream, fanning out the events it
+// sends to every connected subscriber in the structured JSON format. The SSE
+// id of an event is its CloudEvents source, escaped as a URL path segment, and
+// its CloudEvents id, separated by a space.
+//
+// Subscribers reconnecting with a Last-Event-ID header get the events sent
+// after that one, if it is still in the replay buffer, or the whole buffer.
+//
+// It can be served on its own or as the GET handler of a Protocol, with
+// WithGetHandlerFunc(s.ServeHTTP).
+type SSEServer struct {
+	// HeartbeatInterval is the interval between the comments written on idle
+	// streams. If 0, DefaultSSEHeartbeatInterval is used.
+	HeartbeatInterval time.Duration
+
+	replaySize int
+
+	mu          sync.Mutex
+	replay      []sseEvent
+	subscribers map[*sseSubscriber]struct{}
+	closed      bool
+}
+
+// NewSSEServer returns an SSEServer keeping the last replaySize events to
+// resume streams. If replaySize is 0, DefaultSSEReplaySize is used.
+func NewSSEServer(replaySize int) *SSEServer {
+	if replaySize <= 0 {
+		replaySize = DefaultSSEReplaySize
+	}
+	return &SSEServer{
+		replaySize:  replaySize,
+		subscribers: make(map[*sseSubscriber]struct{}),
+	}
+}
+
+// Send implements binding.Sender. It returns once the event is queued for
+// the connected subscribers.
+func (s *SSEServer) Send(ctx context.Context, m binding.Message, transformers ...binding.Transformer) (err error) {
+	if ctx == nil {
+		return fmt.Errorf("nil Context")
+	} else if m == nil {
+		return fmt.Errorf("nil Message")
+	}
+	defer func() { _ = m.Finish(err) }()
+
+	e, err := binding.ToEvent(ctx, m, transformers...)
+	if err != nil {
+		return err
+	}
+	data, err := format.JSON.Marshal(e)
+	if err != nil {
+		return err
+	}
+	ev := sseEvent{id: sseID(e.Source(), e.ID()), data: data}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return errors.New("sse server is closed")
+	}
+	s.replay = append(s.replay, ev)
+	if len(s.replay) > s.replaySize {
+		s.replay = s.replay[len(s.replay)-s.replaySize:]
+	}
+	for sub := range s.subscribers {
+		select {
+		case sub.events <- ev:
+		default:
+			s.disconnect(sub)
+		}
+	}
+	return nil
+}
+
+// Close disconnects every subscriber. Events can no longer be sent.
+func (s *SSEServer) Close(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for sub := range s.subscribers {
+		s.disconnect(sub)
+	}
+	return nil
+}
+
+func (s *SSEServer) disconnect(sub *sseSubscriber) {
+	delete(s.subscribers, sub)
+	close(sub.done)
+}
+
+// subscribe registers a subscriber, and returns the events to replay to it.
+func (s *SSEServer) subscribe(lastEventID string) (*sseSubscriber, []sseEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, nil, errors.New("sse server is closed")
+	}
+	sub := &sseSubscriber{
+		events: make(chan sseEvent, sseSubscriberBuffer),
+		done:   make(chan struct{}),
+	}
+	s.subscribers[sub] = struct{}{}
+
+	if lastEventID == "" {
+		return sub, nil, nil
+	}
+	replay := s.replay
+	for i := len(replay) - 1; i >= 0; i-- {
+		if replay[i].id == lastEventID {
+			replay = replay[i+1:]
+			break
+		}
+	}
+	return sub, append([]sseEvent(nil), replay...), nil
+}
+
+func (s *SSEServer) unsubscribe(sub *sseSubscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscribers[sub]; ok {
+		s.disconnect(sub)
+	}
+}
+
+// ServeHTTP streams the events to a subscriber until it disconnects.
+func (s *SSEServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		rw.Header().Set("Allow", http.MethodGet)
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		http.Error(rw, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	sub, replay, err := s.subscribe(req.Header.Get("Last-Event-ID"))
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer s.unsubscribe(sub)
+
+	rw.Header().Set(ContentType, ContentTypeEventStream)
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.WriteHeader(http.StatusOK)
+	for _, ev := range replay {
+		if err := writeSSEEvent(rw, ev); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	interval := s.HeartbeatInterval
+	if interval <= 0 {
+		interval = DefaultSSEHeartbeatInterval
+	}
+	heartbeat := time.NewTicker(interval)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case <-req.Context().Done():
+			return
+		case <-sub.done:
+			return
+		case ev := <-sub.events:
+			err = writeSSEEvent(rw, ev)
+		case <-heartbeat.C:
+			_, err = rw.Write([]byte(":\n\n"))
+		}
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// sseID returns the SSE id of the event with source and id, so that events
+// of different sources with the same id are told apart when resuming streams.
+func sseID(source, id string) string {
+	return url.PathEscape(source) + " " + id
+}
+
+func writeSSEEvent(rw http.ResponseWriter, ev sseEvent) error {
+	var b bytes.Buffer
+	if ev.id != "" && !strings.ContainsAny(ev.id, "\r\n\x00") {
+		b.WriteString("id: ")
+		b.WriteString(ev.id)
+		b.WriteByte('\n')
+	}
+	// Each line of the data is a data field.
+	for _, line := range bytes.Split(ev.data, []byte("\n")) {
+		b.WriteString("data: ")
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	_, err := rw.Write(b.Bytes())
+	return err
+}
@@ -0,0 +1,303 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// DefaultSSEReconnectDelay is the delay before an SSEReceiver reconnects,
+// unless the server sets another one with a retry field.
+const DefaultSSEReconnectDelay = 3 * time.Second
+
+// DefaultSSEMaxEventBytes is the maximum size of a line and of the data of an
+// event of a stream received by an SSEReceiver.
+const DefaultSSEMaxEventBytes = 1 << 20
+
+// ErrSSEEventTooLarge is returned when a line or the data of an event of a
+// stream exceeds the maximum size.
+var ErrSSEEventTooLarge = errors.New("sse event too large")
+
+var (
+	_ protocol.Receiver = (*SSEReceiver)(nil)
+	_ protocol.Opener   = (*SSEReceiver)(nil)
+	_ protocol.Closer   = (*SSEReceiver)(nil)
+)
+
+// SSEReceiver receives the events of a Server-Sent Events stream in the
+// structured JSON format, such as one served by an SSEServer. OpenInbound
+// connects to the stream, and reconnects with a Last-Event-ID header when the
+// connection is lost, to resume it.
+type SSEReceiver struct {
+	// Client is the client used to connect to the stream. If nil,
+	// http.DefaultClient is used.
+	Client *http.Client
+	// ReconnectDelay is the delay before reconnecting. If 0,
+	// DefaultSSEReconnectDelay is used. The server can change it with a
+	// retry field.
+	ReconnectDelay time.Duration
+	// MaxEventBytes is the maximum size of a line and of the data of an
+	// event. A stream exceeding it is dropped and reconnected. If 0,
+	// DefaultSSEMaxEventBytes is used.
+	MaxEventBytes int
+
+	target string
+
+	mu          sync.Mutex
+	lastEventID string
+	incoming    chan binding.Message
+	closeOnce   sync.Once
+	done        chan struct{}
+}
+
+// NewSSEReceiver returns a receiver of the stream at target.
+func NewSSEReceiver(target string) *SSEReceiver {
+	return &SSEReceiver{
+		target:   target,
+		incoming: make(chan binding.Message),
+		done:     make(chan struct{}),
+	}
+}
+
+// LastEventID returns the SSE id of the last event received.
+func (r *SSEReceiver) LastEventID() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastEventID
+}
+
+// Receive implements protocol.Receiver. It returns io.EOF once the receiver
+// is closed.
+func (r *SSEReceiver) Receive(ctx context.Context) (binding.Message, error) {
+	select {
+	case m := <-r.incoming:
+		return m, nil
+	case <-r.done:
+		return nil, io.EOF
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close implements protocol.Closer.
+func (r *SSEReceiver) Close(context.Context) error {
+	r.closeOnce.Do(func() { close(r.done) })
+	return nil
+}
+
+// OpenInbound implements protocol.Opener. It receives the stream until ctx is
+// done, the receiver is closed, or the server answers with 204 No Content,
+// which closes the receiver. Other failures than transport errors, 429 and
+// 5xx status codes are returned.
+func (r *SSEReceiver) OpenInbound(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-r.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		retry, err := r.stream(ctx)
+		if err == io.EOF {
+			return r.Close(ctx)
+		}
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		delay := r.ReconnectDelay
+		if retry > 0 {
+			delay = retry
+		} else if delay <= 0 {
+			delay = DefaultSSEReconnectDelay
+		}
+		cecontext.LoggerFrom(ctx).Debugw("sse stream lost, reconnecting", zap.Duration("delay", delay))
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+	}
+}
+
+// stream receives the stream until the connection is lost, returning the
+// reconnection delay set by the server, if any. It returns an error if it
+// should not reconnect.
+func (r *SSEReceiver) stream(ctx context.Context) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.target, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", ContentTypeEventStream)
+	req.Header.Set("Cache-Control", "no-cache")
+	if id := r.LastEventID(); id != "" {
+		req.Header.Set("Last-Event-ID", id)
+	}
+
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		cecontext.LoggerFrom(ctx).Debugw("sse connection failed", zap.Error(err))
+		return 0, nil
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNoContent:
+		return 0, io.EOF
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode/100 == 5:
+		retryAfter, _ := retryAfterFrom(NewMessage(resp.Header, nil), time.Now())
+		return retryAfter, nil
+	case resp.StatusCode != http.StatusOK:
+		return 0, NewResult(resp.StatusCode, "sse stream: %w", protocol.ResultNACK)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get(ContentType)); mediaType != ContentTypeEventStream {
+		return 0, fmt.Errorf("sse stream: unexpected content type %q", resp.Header.Get(ContentType))
+	}
+
+	maxBytes := r.MaxEventBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultSSEMaxEventBytes
+	}
+	var retry time.Duration
+	err = readSSE(resp.Body, maxBytes, func(f sseFields) error {
+		if f.retry != nil {
+			retry = *f.retry
+		}
+		if f.data != nil {
+			m := NewMessage(http.Header{ContentType: {event.ApplicationCloudEventsJSON}}, io.NopCloser(bytes.NewReader(f.data)))
+			select {
+			case r.incoming <- m:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		// The stream resumes after the last event delivered.
+		if f.id != nil {
+			r.mu.Lock()
+			r.lastEventID = *f.id
+			r.mu.Unlock()
+		}
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		cecontext.LoggerFrom(ctx).Debugw("sse stream failed", zap.Error(err))
+	}
+	return retry, nil
+}
+
+// sseFields are the fields of an event of a stream, nil if not set.
+type sseFields struct {
+	id    *string
+	retry *time.Duration
+	data  []byte
+}
+
+// readSSE parses the events of the stream rd as defined by
+// https://html.spec.whatwg.org/multipage/server-sent-events.html, calling
+// dispatch for each of them, until the end of the stream. A line or the data
+// of an event larger than maxBytes fails with ErrSSEEventTooLarge.
+func readSSE(rd io.Reader, maxBytes int, dispatch func(sseFields) error) error {
+	br := bufio.NewReader(rd)
+	var (
+		f    sseFields
+		data bytes.Buffer
+	)
+	for {
+		line, err := readSSELine(br, maxBytes)
+		if err != nil {
+			// An incomplete event at the end of the stream is dropped.
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" {
+			// Events with an empty data are not dispatched.
+			if d := bytes.TrimSuffix(data.Bytes(), []byte("\n")); len(d) > 0 {
+				f.data = d
+			}
+			if f.id != nil || f.retry != nil || f.data != nil {
+				if err := dispatch(f); err != nil {
+					return err
+				}
+			}
+			f, data = sseFields{}, bytes.Buffer{}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// Comment.
+			continue
+		}
+
+		name, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch name {
+		case "data":
+			if data.Len()+len(value)+1 > maxBytes {
+				return ErrSSEEventTooLarge
+			}
+			data.WriteString(value)
+			data.WriteByte('\n')
+		case "id":
+			if !strings.Contains(value, "\x00") {
+				id := value
+				f.id = &id
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(value, 10, 32); err == nil {
+				retry := time.Duration(ms) * time.Millisecond
+				f.retry = &retry
+			}
+		}
+	}
+}
+
+// readSSELine reads a line of br, including its end of line, failing with
+// ErrSSEEventTooLarge if it is larger than maxBytes.
+func readSSELine(br *bufio.Reader, maxBytes int) (string, error) {
+	var line []byte
+	for {
+		b, err := br.ReadSlice('\n')
+		if len(line)+len(b) > maxBytes {
+			return "", ErrSSEEventTooLarge
+		}
+		line = append(line, b...)
+		if err != bufio.ErrBufferFull {
+			return string(line), err
+		}
+	}
+}
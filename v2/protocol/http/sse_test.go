@@ -0,0 +1,235 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+func TestReadSSE(t *testing.T) {
+	id := func(s string) *string { return &s }
+	retry := func(d time.Duration) *time.Duration { return &d }
+	testCases := map[string]struct {
+		stream string
+		want   []sseFields
+	}{
+		"data": {
+			stream: "data: a\n\n",
+			want:   []sseFields{{data: []byte("a")}},
+		},
+		"multiline data and crlf": {
+			stream: "data: a\r\ndata:b\r\n\r\n",
+			want:   []sseFields{{data: []byte("a\nb")}},
+		},
+		"id and retry": {
+			stream: "id: 1\nretry: 500\ndata: a\n\nid\ndata: b\n\n",
+			want: []sseFields{
+				{id: id("1"), retry: retry(500 * time.Millisecond), data: []byte("a")},
+				{id: id(""), data: []byte("b")},
+			},
+		},
+		"comments and unknown fields": {
+			stream: ":\n: keepalive\nevent: x\nfoo: bar\ndata: a\n\n",
+			want:   []sseFields{{data: []byte("a")}},
+		},
+		"empty data": {
+			stream: "data:\n\n",
+		},
+		"invalid retry": {
+			stream: "retry: soon\ndata: a\n\n",
+			want:   []sseFields{{data: []byte("a")}},
+		},
+		"incomplete event": {
+			stream: "data: a\n\ndata: b\n",
+			want:   []sseFields{{data: []byte("a")}},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var got []sseFields
+			require.NoError(t, readSSE(strings.NewReader(tc.stream), DefaultSSEMaxEventBytes, func(f sseFields) error {
+				got = append(got, f)
+				return nil
+			}))
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestReadSSE_maxBytes(t *testing.T) {
+	testCases := map[string]string{
+		"line":             "data: a\n\n: " + strings.Repeat("x", 64) + "\n\n",
+		"line without end": "data: a\n\n" + strings.Repeat("x", 8192),
+		"event":            "data: a\n\n" + strings.Repeat("data: 0123456789\n", 4) + "\n",
+	}
+	for n, stream := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var got []sseFields
+			err := readSSE(strings.NewReader(stream), 32, func(f sseFields) error {
+				got = append(got, f)
+				return nil
+			})
+			require.ErrorIs(t, err, ErrSSEEventTooLarge)
+			require.Equal(t, []sseFields{{data: []byte("a")}}, got)
+		})
+	}
+}
+
+func newSSETestEvent(id string) event.Event {
+	e := event.New()
+	e.SetID(id)
+	e.SetSource("/sse")
+	e.SetType("sse.test")
+	return e
+}
+
+func sendSSE(t *testing.T, s *SSEServer, ids ...string) {
+	for _, id := range ids {
+		e := newSSETestEvent(id)
+		require.NoError(t, s.Send(context.Background(), binding.ToMessage(&e)))
+	}
+}
+
+func TestSSEServer_ServeHTTP(t *testing.T) {
+	testCases := map[string]struct {
+		lastEventID string
+		want        []string
+	}{
+		"new subscriber":  {want: []string{"4"}},
+		"resume":          {lastEventID: sseID("/sse", "2"), want: []string{"3", "4"}},
+		"resume too late": {lastEventID: sseID("/sse", "1"), want: []string{"2", "3", "4"}},
+		// The same id from another source is not the same event.
+		"resume other source": {lastEventID: sseID("/other", "3"), want: []string{"2", "3", "4"}},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			s := NewSSEServer(2)
+			server := httptest.NewServer(s)
+			defer server.Close()
+			sendSSE(t, s, "1", "2", "3")
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+			if tc.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tc.lastEventID)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, ContentTypeEventStream, resp.Header.Get(ContentType))
+
+			// The subscriber is registered once the headers are received.
+			e := newSSETestEvent("4")
+			require.NoError(t, s.Send(context.Background(), binding.ToMessage(&e)))
+
+			var got []string
+			br := bufio.NewReader(resp.Body)
+			for len(got) < len(tc.want) {
+				line, err := br.ReadString('\n')
+				require.NoError(t, err)
+				if strings.HasPrefix(line, "id: ") {
+					id := strings.TrimSuffix(strings.TrimPrefix(line, "id: "), "\n")
+					source, id, _ := strings.Cut(id, " ")
+					require.Equal(t, "%2Fsse", source)
+					got = append(got, id)
+				}
+			}
+			require.Equal(t, tc.want, got)
+		})
+	}
+
+	server := httptest.NewServer(NewSSEServer(0))
+	defer server.Close()
+	resp, err := http.Post(server.URL, event.ApplicationCloudEventsJSON, nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestSSEReceiver(t *testing.T) {
+	s := NewSSEServer(10)
+	p, err := New(WithGetHandlerFunc(s.ServeHTTP))
+	require.NoError(t, err)
+	server := httptest.NewServer(p)
+	defer server.Close()
+
+	r := NewSSEReceiver(server.URL)
+	r.ReconnectDelay = 100 * time.Millisecond
+	done := make(chan error)
+	go func() { done <- r.OpenInbound(context.Background()) }()
+
+	receive := func() string {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		m, err := r.Receive(ctx)
+		require.NoError(t, err)
+		e, err := binding.ToEvent(ctx, m)
+		require.NoError(t, err)
+		require.NoError(t, m.Finish(nil))
+		return e.ID()
+	}
+	waitSubscribers := func(n int) {
+		require.Eventually(t, func() bool {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return len(s.subscribers) == n
+		}, 5*time.Second, time.Millisecond)
+	}
+
+	waitSubscribers(1)
+	sendSSE(t, s, "1")
+	require.Equal(t, "1", receive())
+	require.Equal(t, sseID("/sse", "1"), r.LastEventID())
+
+	// Events sent while disconnected are received after reconnecting.
+	server.CloseClientConnections()
+	waitSubscribers(0)
+	sendSSE(t, s, "2", "3")
+	require.Equal(t, "2", receive())
+	require.Equal(t, "3", receive())
+
+	require.NoError(t, r.Close(context.Background()))
+	require.NoError(t, <-done)
+	_, err = r.Receive(context.Background())
+	require.Equal(t, io.EOF, err)
+}
+
+func TestSSEReceiver_undelivered(t *testing.T) {
+	s := NewSSEServer(10)
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	r := NewSSEReceiver(server.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- r.OpenInbound(ctx) }()
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.subscribers) == 1
+	}, 5*time.Second, time.Millisecond)
+
+	// The event is read from the stream, but never received.
+	sendSSE(t, s, "1")
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+	require.Empty(t, r.LastEventID())
+}
@@ -0,0 +1,91 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package decorator holds the base of the protocol decorators of the
+// protocol/pipeline and protocol/record packages.
+package decorator
+
+import (
+	"context"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// DecorateFunc decorates a received message. If it returns an error, the
+// message is not delivered: the error is returned by Receive and Respond.
+type DecorateFunc func(ctx context.Context, m binding.Message) (binding.Message, error)
+
+// Receiver decorates the messages received by a protocol.Receiver or
+// protocol.Responder. It also opens and closes the decorated protocol, so it
+// can be used in its place with client.New.
+type Receiver struct {
+	receiver protocol.Receiver
+	decorate DecorateFunc
+}
+
+var (
+	_ protocol.Receiver  = (*Receiver)(nil)
+	_ protocol.Responder = (*Receiver)(nil)
+	_ protocol.Opener    = (*Receiver)(nil)
+	_ protocol.Closer    = (*Receiver)(nil)
+)
+
+// NewReceiver returns a Receiver decorating the messages received by r with
+// decorate.
+func NewReceiver(r protocol.Receiver, decorate DecorateFunc) *Receiver {
+	return &Receiver{receiver: r, decorate: decorate}
+}
+
+// Receive implements protocol.Receiver.
+func (r *Receiver) Receive(ctx context.Context) (binding.Message, error) {
+	m, err := r.receiver.Receive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.decorate(ctx, m)
+}
+
+// Respond implements protocol.Responder. If the decorated protocol is not a
+// protocol.Responder, messages are received without responses. The responses
+// are not decorated.
+func (r *Receiver) Respond(ctx context.Context) (binding.Message, protocol.ResponseFn, error) {
+	responder, ok := r.receiver.(protocol.Responder)
+	if !ok {
+		m, err := r.Receive(ctx)
+		return m, noResponse, err
+	}
+	m, fn, err := responder.Respond(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if m, err = r.decorate(ctx, m); err != nil {
+		// Respond with the error, for the sender not to wait.
+		_ = fn(ctx, nil, err)
+		return nil, nil, err
+	}
+	return m, fn, nil
+}
+
+func noResponse(_ context.Context, _ binding.Message, r protocol.Result, _ ...binding.Transformer) error {
+	return r
+}
+
+// OpenInbound implements protocol.Opener, opening the decorated protocol if
+// needed.
+func (r *Receiver) OpenInbound(ctx context.Context) error {
+	if o, ok := r.receiver.(protocol.Opener); ok {
+		return o.OpenInbound(ctx)
+	}
+	return nil
+}
+
+// Close implements protocol.Closer, closing the decorated protocol if needed.
+func (r *Receiver) Close(ctx context.Context) error {
+	if c, ok := r.receiver.(protocol.Closer); ok {
+		return c.Close(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package decorator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/gochan"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+func TestReceiver_decorateError(t *testing.T) {
+	failed := errors.New("failed")
+	in := make(chan binding.Message, 1)
+	out := make(chan gochan.ChanResponderResponse, 1)
+	r := NewReceiver(struct {
+		gochan.Receiver
+		*gochan.Responder
+	}{Responder: &gochan.Responder{In: in, Out: out}}, func(context.Context, binding.Message) (binding.Message, error) {
+		return nil, failed
+	})
+
+	// The sender gets the error rather than waiting for a response.
+	e := test.MinEvent()
+	in <- binding.ToMessage(&e)
+	_, _, err := r.Respond(context.Background())
+	require.ErrorIs(t, err, failed)
+	res := (<-out).Result
+	require.ErrorIs(t, res, failed)
+	require.False(t, protocol.IsACK(res))
+}
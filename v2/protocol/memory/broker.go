@@ -0,0 +1,263 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package memory
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/extensions"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// DefaultMaxAttempts is the number of times a message is delivered to a
+// subscription before it is given up on.
+const DefaultMaxAttempts = 5
+
+// ErrClosed is returned when sending with a closed broker.
+var ErrClosed = errors.New("memory broker is closed")
+
+// Broker is an in-process message broker. Messages sent to a topic are
+// delivered to each of its subscriptions; the receivers of a subscription,
+// its consumer group, share its messages.
+//
+// A message is delivered again when it is finished with an error which is not
+// an ACK, up to the maximum number of attempts. Messages with the same
+// ordering key are delivered in order to a subscription: a message is not
+// delivered while a previous message with the same key is being processed or
+// waiting for redelivery.
+type Broker struct {
+	maxAttempts     int
+	redeliveryDelay time.Duration
+	orderingKey     func(event.Event) string
+	faults          FaultFunc
+	deadLetterTopic string
+
+	mu     sync.Mutex
+	topics map[string]*topic
+	seq    uint64
+	closed bool
+	done   chan struct{}
+}
+
+type topic struct {
+	subscriptions map[string]*subscription
+}
+
+type subscription struct {
+	topic string
+	name  string
+	// queue is sorted by sequence number, so that redelivered messages keep
+	// their place.
+	queue []*delivery
+	// inFlight are the ordering keys of the messages being processed.
+	inFlight map[string]bool
+	// notify is closed when messages are queued.
+	notify chan struct{}
+}
+
+type delivery struct {
+	seq       uint64
+	event     event.Event
+	key       string
+	attempts  int
+	notBefore time.Time
+}
+
+// NewBroker returns a new Broker.
+func NewBroker(opts ...Option) (*Broker, error) {
+	b := &Broker{
+		maxAttempts: DefaultMaxAttempts,
+		orderingKey: partitionKey,
+		topics:      make(map[string]*topic),
+		done:        make(chan struct{}),
+	}
+	if err := b.applyOptions(opts...); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *Broker) applyOptions(opts ...Option) error {
+	for _, fn := range opts {
+		if err := fn(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// partitionKey is the default ordering key, the partitionkey extension.
+func partitionKey(e event.Event) string {
+	key, _ := extensions.PartitionKey.Get(e)
+	return key
+}
+
+// Sender returns a sender of messages to topic.
+func (b *Broker) Sender(topic string) *Sender {
+	return &Sender{broker: b, topic: topic}
+}
+
+// Receiver returns a receiver of the messages of the subscription to topic
+// named group, creating the subscription if needed. Receivers of the same
+// subscription share its messages. Only the messages sent after the
+// subscription is created are delivered to it.
+func (b *Broker) Receiver(topic, group string) *Receiver {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return &Receiver{broker: b, sub: b.subscription(topic, group), done: make(chan struct{})}
+}
+
+func (b *Broker) topic(name string) *topic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &topic{subscriptions: make(map[string]*subscription)}
+		b.topics[name] = t
+	}
+	return t
+}
+
+func (b *Broker) subscription(topic, name string) *subscription {
+	t := b.topic(topic)
+	s, ok := t.subscriptions[name]
+	if !ok {
+		s = &subscription{topic: topic, name: name, inFlight: make(map[string]bool), notify: make(chan struct{})}
+		t.subscriptions[name] = s
+	}
+	return s
+}
+
+// Close closes the broker: sending fails and receiving returns io.EOF.
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.closed {
+		b.closed = true
+		close(b.done)
+	}
+	return nil
+}
+
+// publish queues e in every subscription to topic.
+func (b *Broker) publish(topic string, e event.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+	b.publishLocked(topic, e)
+	return nil
+}
+
+func (b *Broker) publishLocked(topic string, e event.Event) {
+	key := b.orderingKey(e)
+	// Iterate in a stable order, for fault injection to be deterministic.
+	t := b.topic(topic)
+	names := make([]string, 0, len(t.subscriptions))
+	for name := range t.subscriptions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.seq++
+		b.enqueue(t.subscriptions[name], &delivery{seq: b.seq, event: e, key: key})
+	}
+}
+
+// enqueue queues d in s, after injecting faults.
+func (b *Broker) enqueue(s *subscription, d *delivery) {
+	var fault Fault
+	if b.faults != nil {
+		fault = b.faults(Delivery{Topic: s.topic, Subscription: s.name, Event: d.event.Clone(), Attempt: d.attempts + 1})
+	}
+	if fault.Drop {
+		return
+	}
+	if fault.Delay > 0 {
+		if notBefore := time.Now().Add(fault.Delay); notBefore.After(d.notBefore) {
+			d.notBefore = notBefore
+		}
+	}
+	s.insert(d)
+	if fault.Duplicate {
+		b.seq++
+		dup := *d
+		dup.seq = b.seq
+		s.insert(&dup)
+	}
+	s.wake()
+}
+
+func (s *subscription) insert(d *delivery) {
+	i := sort.Search(len(s.queue), func(i int) bool { return s.queue[i].seq > d.seq })
+	s.queue = append(s.queue, nil)
+	copy(s.queue[i+1:], s.queue[i:])
+	s.queue[i] = d
+}
+
+// wake notifies the waiting receivers that messages are queued.
+func (s *subscription) wake() {
+	close(s.notify)
+	s.notify = make(chan struct{})
+}
+
+// next removes the next message which can be delivered from the queue. If
+// there is none, it returns when one might be, or a zero time.
+func (s *subscription) next(now time.Time) (*delivery, time.Time) {
+	var (
+		wake    time.Time
+		blocked map[string]bool
+	)
+	for i, d := range s.queue {
+		if d.key != "" && (s.inFlight[d.key] || blocked[d.key]) {
+			continue
+		}
+		if now.Before(d.notBefore) {
+			if wake.IsZero() || d.notBefore.Before(wake) {
+				wake = d.notBefore
+			}
+			if d.key != "" {
+				// Later messages with the same key must wait for it.
+				if blocked == nil {
+					blocked = make(map[string]bool)
+				}
+				blocked[d.key] = true
+			}
+			continue
+		}
+		s.queue = append(s.queue[:i], s.queue[i+1:]...)
+		if d.key != "" {
+			s.inFlight[d.key] = true
+		}
+		d.attempts++
+		return d, time.Time{}
+	}
+	return nil, wake
+}
+
+// finish acknowledges d, or delivers it again.
+func (b *Broker) finish(s *subscription, d *delivery, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if d.key != "" {
+		delete(s.inFlight, d.key)
+	}
+	defer s.wake()
+	if protocol.IsACK(err) || b.closed {
+		return
+	}
+	if d.attempts >= b.maxAttempts {
+		if b.deadLetterTopic != "" {
+			b.publishLocked(b.deadLetterTopic, d.event)
+		}
+		return
+	}
+	d.notBefore = time.Now().Add(b.redeliveryDelay)
+	b.enqueue(s, d)
+}
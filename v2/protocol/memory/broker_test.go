@@ -0,0 +1,238 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/extensions"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/test"
+)
+
+func newBroker(t *testing.T, opts ...Option) *Broker {
+	b, err := NewBroker(opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = b.Close() })
+	return b
+}
+
+func newEvent(id, key string) event.Event {
+	e := event.New()
+	e.SetID(id)
+	e.SetSource("/memory")
+	e.SetType("memory.test")
+	if key != "" {
+		_ = extensions.PartitionKey.Set(&e, key)
+	}
+	return e
+}
+
+func send(t *testing.T, s *Sender, ids ...string) {
+	for _, id := range ids {
+		e := newEvent(id, "")
+		require.NoError(t, s.Send(context.Background(), binding.ToMessage(&e)))
+	}
+}
+
+// receive returns the next message of r and the id of its event.
+func receive(t *testing.T, r *Receiver) (string, binding.Message) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	m, err := r.Receive(ctx)
+	require.NoError(t, err)
+	e, err := binding.ToEvent(ctx, m)
+	require.NoError(t, err)
+	return e.ID(), m
+}
+
+// receiveIDs receives n messages of r, acknowledging them.
+func receiveIDs(t *testing.T, r *Receiver, n int) []string {
+	t.Helper()
+	var ids []string
+	for i := 0; i < n; i++ {
+		id, m := receive(t, r)
+		require.NoError(t, m.Finish(nil))
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// requireEmpty requires that r has no message ready.
+func requireEmpty(t *testing.T, r *Receiver) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := r.Receive(ctx)
+	require.Equal(t, io.EOF, err)
+}
+
+func TestSendReceive(t *testing.T) {
+	b := newBroker(t)
+	e := newEvent("1", "")
+	test.SendReceive(t, context.Background(), binding.ToMessage(&e), b.Sender("topic"), b.Receiver("topic", "group"), func(m binding.Message) {
+		got, err := binding.ToEvent(context.Background(), m)
+		require.NoError(t, err)
+		require.Equal(t, e, *got)
+	})
+}
+
+func TestBroker_subscriptions(t *testing.T) {
+	b := newBroker(t)
+	s := b.Sender("orders")
+	billing1 := b.Receiver("orders", "billing")
+	billing2 := b.Receiver("orders", "billing")
+	shipping := b.Receiver("orders", "shipping")
+	other := b.Receiver("other", "billing")
+
+	send(t, s, "1", "2")
+
+	// Each subscription gets every message, shared by its receivers.
+	require.Equal(t, []string{"1", "2"}, receiveIDs(t, shipping, 2))
+	require.Equal(t, []string{"1"}, receiveIDs(t, billing1, 1))
+	require.Equal(t, []string{"2"}, receiveIDs(t, billing2, 1))
+	requireEmpty(t, billing1)
+	requireEmpty(t, other)
+
+	// Messages sent before a subscription are not delivered to it.
+	requireEmpty(t, b.Receiver("orders", "late"))
+}
+
+func TestBroker_redelivery(t *testing.T) {
+	b := newBroker(t, WithMaxAttempts(2), WithDeadLetterTopic("dead"))
+	s := b.Sender("topic")
+	r := b.Receiver("topic", "group")
+	dead := b.Receiver("dead", "group")
+
+	send(t, s, "1", "2")
+	id, m := receive(t, r)
+	require.Equal(t, "1", id)
+	require.NoError(t, m.Finish(errors.New("failed")))
+
+	// The message keeps its place.
+	id, m = receive(t, r)
+	require.Equal(t, "1", id)
+	require.NoError(t, m.Finish(protocol.ResultNACK))
+	require.Equal(t, []string{"2"}, receiveIDs(t, r, 1))
+
+	// After the last attempt, it is sent to the dead letter topic.
+	requireEmpty(t, r)
+	require.Equal(t, []string{"1"}, receiveIDs(t, dead, 1))
+}
+
+func TestBroker_redeliveryDelay(t *testing.T) {
+	b := newBroker(t, WithRedeliveryDelay(50*time.Millisecond))
+	s := b.Sender("topic")
+	r := b.Receiver("topic", "group")
+
+	send(t, s, "1", "2")
+	_, m := receive(t, r)
+	start := time.Now()
+	require.NoError(t, m.Finish(protocol.ResultNACK))
+	require.Equal(t, []string{"2", "1"}, receiveIDs(t, r, 2))
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestBroker_orderingKeys(t *testing.T) {
+	b := newBroker(t, WithRedeliveryDelay(100*time.Millisecond))
+	s := b.Sender("topic")
+	r := b.Receiver("topic", "group")
+
+	for _, e := range []event.Event{newEvent("a1", "a"), newEvent("a2", "a"), newEvent("b1", "b"), newEvent("x", "")} {
+		require.NoError(t, s.Send(context.Background(), binding.ToMessage(&e)))
+	}
+
+	// a2 waits for a1 to be processed, others do not.
+	id, a1 := receive(t, r)
+	require.Equal(t, "a1", id)
+	require.Equal(t, []string{"b1", "x"}, receiveIDs(t, r, 2))
+	requireEmpty(t, r)
+
+	// a2 also waits for a1 to be delivered again.
+	require.NoError(t, a1.Finish(protocol.ResultNACK))
+	requireEmpty(t, r)
+	require.Equal(t, []string{"a1", "a2"}, receiveIDs(t, r, 2))
+}
+
+func TestBroker_faults(t *testing.T) {
+	b := newBroker(t, WithFaults(func(d Delivery) Fault {
+		switch {
+		case d.Event.ID() == "1":
+			return Fault{Delay: 50 * time.Millisecond}
+		case d.Event.ID() == "2" && d.Attempt == 1:
+			return Fault{Drop: true}
+		case d.Event.ID() == "3":
+			return Fault{Duplicate: true}
+		}
+		return Fault{}
+	}))
+	s := b.Sender("topic")
+	r := b.Receiver("topic", "group")
+
+	send(t, s, "1", "2", "3")
+	require.Equal(t, []string{"3", "3", "1"}, receiveIDs(t, r, 3))
+	requireEmpty(t, r)
+}
+
+func TestRandomFaults(t *testing.T) {
+	rates := FaultRates{Drop: 0.2, Duplicate: 0.2, Delay: 0.2, MaxDelay: time.Second}
+	f1, f2 := RandomFaults(42, rates), RandomFaults(42, rates)
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		fault := f1(Delivery{})
+		require.Equal(t, fault, f2(Delivery{}))
+		if fault.Drop {
+			counts["drop"]++
+		}
+		if fault.Duplicate {
+			counts["duplicate"]++
+		}
+		if fault.Delay > 0 {
+			require.Less(t, fault.Delay, time.Second)
+			counts["delay"]++
+		}
+	}
+	for name, n := range counts {
+		require.InDelta(t, 200, n, 60, name)
+	}
+	require.Len(t, counts, 3)
+}
+
+func TestBroker_Close(t *testing.T) {
+	b := newBroker(t)
+	r := b.Receiver("topic", "group")
+	done := make(chan error)
+	go func() {
+		_, err := r.Receive(context.Background())
+		done <- err
+	}()
+	require.NoError(t, b.Close())
+	require.Equal(t, io.EOF, <-done)
+
+	e := newEvent("1", "")
+	require.Equal(t, ErrClosed, b.Sender("topic").Send(context.Background(), binding.ToMessage(&e)))
+
+	_, err := NewBroker(WithMaxAttempts(0))
+	require.Error(t, err)
+}
+
+func TestConformance(t *testing.T) {
+	test.RunConformance(t, test.Conformance{
+		NewSenderReceiver: func(t *testing.T) (protocol.Sender, protocol.Receiver) {
+			b := newBroker(t)
+			return b.Sender("topic"), b.Receiver("topic", "group")
+		},
+	})
+}
@@ -0,0 +1,12 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package memory implements an in-process broker protocol, with topics,
+subscriptions shared by consumer groups, acknowledgements, redelivery,
+ordering keys and fault injection. It is meant to test handlers locally with
+the delivery semantics of brokers such as Kafka or NATS.
+*/
+package memory
@@ -0,0 +1,68 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package memory
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+// Delivery describes the delivery of a message to a subscription.
+type Delivery struct {
+	Topic        string
+	Subscription string
+	Event        event.Event
+	// Attempt is 1 for the first delivery.
+	Attempt int
+}
+
+// Fault is a fault injected in a delivery.
+type Fault struct {
+	// Drop drops the message, as if it was lost.
+	Drop bool
+	// Duplicate delivers the message twice.
+	Duplicate bool
+	// Delay delays the delivery of the message.
+	Delay time.Duration
+}
+
+// FaultFunc returns the fault to inject in a delivery. It is called with the
+// broker locked, in the order of the deliveries.
+type FaultFunc func(Delivery) Fault
+
+// FaultRates are the probabilities of the faults injected by RandomFaults.
+type FaultRates struct {
+	Drop      float64
+	Duplicate float64
+	Delay     float64
+	// MaxDelay is the maximum delay of delayed deliveries.
+	MaxDelay time.Duration
+}
+
+// RandomFaults returns a FaultFunc injecting faults with the probabilities of
+// rates. The faults only depend on seed and the order of the deliveries, so
+// that runs can be reproduced.
+func RandomFaults(seed uint64, rates FaultRates) FaultFunc {
+	var mu sync.Mutex
+	rnd := rand.New(rand.NewPCG(seed, seed))
+	return func(Delivery) Fault {
+		mu.Lock()
+		defer mu.Unlock()
+		// Always draw the same numbers, for the faults of a delivery not to
+		// depend on the rates.
+		drop, duplicate, delay, d := rnd.Float64(), rnd.Float64(), rnd.Float64(), rnd.Float64()
+		var f Fault
+		f.Drop = drop < rates.Drop
+		f.Duplicate = duplicate < rates.Duplicate
+		if delay < rates.Delay {
+			f.Delay = time.Duration(d * float64(rates.MaxDelay))
+		}
+		return f
+	}
+}
@@ -0,0 +1,76 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package memory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+// Option is the function signature required to be considered a memory.Option.
+type Option func(*Broker) error
+
+// WithMaxAttempts sets the number of times a message is delivered to a
+// subscription before it is given up on. If not set, DefaultMaxAttempts is
+// used.
+func WithMaxAttempts(n int) Option {
+	return func(b *Broker) error {
+		if n < 1 {
+			return fmt.Errorf("max attempts must be at least 1, got %d", n)
+		}
+		b.maxAttempts = n
+		return nil
+	}
+}
+
+// WithRedeliveryDelay sets the delay before a message is delivered again.
+func WithRedeliveryDelay(d time.Duration) Option {
+	return func(b *Broker) error {
+		if d < 0 {
+			return fmt.Errorf("redelivery delay can not be negative")
+		}
+		b.redeliveryDelay = d
+		return nil
+	}
+}
+
+// WithOrderingKey sets the function returning the ordering key of an event.
+// Events with an empty key are not ordered. If not set, the partitionkey
+// extension is used.
+func WithOrderingKey(fn func(event.Event) string) Option {
+	return func(b *Broker) error {
+		if fn == nil {
+			return fmt.Errorf("ordering key function can not be nil")
+		}
+		b.orderingKey = fn
+		return nil
+	}
+}
+
+// WithFaults sets the function injecting faults in deliveries.
+func WithFaults(fn FaultFunc) Option {
+	return func(b *Broker) error {
+		if fn == nil {
+			return fmt.Errorf("fault function can not be nil")
+		}
+		b.faults = fn
+		return nil
+	}
+}
+
+// WithDeadLetterTopic sets the topic messages are sent to once they were
+// delivered the maximum number of times. If not set, they are dropped.
+func WithDeadLetterTopic(topic string) Option {
+	return func(b *Broker) error {
+		if topic == "" {
+			return fmt.Errorf("dead letter topic can not be empty")
+		}
+		b.deadLetterTopic = topic
+		return nil
+	}
+}
@@ -0,0 +1,88 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// Receiver receives the messages of a subscription of a Broker. Finishing a
+// message with an error which is not an ACK delivers it again.
+type Receiver struct {
+	broker *Broker
+	sub    *subscription
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+var _ protocol.ReceiveCloser = (*Receiver)(nil)
+
+// Receive implements protocol.Receiver. It returns io.EOF once the receiver
+// or the broker is closed.
+func (r *Receiver) Receive(ctx context.Context) (binding.Message, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("nil Context")
+	}
+	for {
+		r.broker.mu.Lock()
+		if r.broker.closed {
+			r.broker.mu.Unlock()
+			return nil, io.EOF
+		}
+		select {
+		case <-r.done:
+			r.broker.mu.Unlock()
+			return nil, io.EOF
+		default:
+		}
+		d, wake := r.sub.next(time.Now())
+		notify := r.sub.notify
+		r.broker.mu.Unlock()
+
+		if d != nil {
+			e := d.event.Clone()
+			return binding.WithFinish(binding.ToMessage(&e), func(err error) {
+				r.broker.finish(r.sub, d, err)
+			}), nil
+		}
+
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if !wake.IsZero() {
+			timer = time.NewTimer(time.Until(wake))
+			timeout = timer.C
+		}
+		select {
+		case <-notify:
+		case <-timeout:
+		case <-r.done:
+		case <-r.broker.done:
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return nil, io.EOF
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// Close implements protocol.Closer. Messages being processed can still be
+// finished.
+func (r *Receiver) Close(context.Context) error {
+	r.closeOnce.Do(func() { close(r.done) })
+	return nil
+}
@@ -0,0 +1,44 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// Sender sends messages to a topic of a Broker.
+type Sender struct {
+	broker *Broker
+	topic  string
+}
+
+var _ protocol.SendCloser = (*Sender)(nil)
+
+// Send implements binding.Sender. It returns once the message is queued in
+// the subscriptions to the topic.
+func (s *Sender) Send(ctx context.Context, m binding.Message, transformers ...binding.Transformer) (err error) {
+	if ctx == nil {
+		return fmt.Errorf("nil Context")
+	} else if m == nil {
+		return fmt.Errorf("nil Message")
+	}
+	defer func() { _ = m.Finish(err) }()
+
+	e, err := binding.ToEvent(ctx, m, transformers...)
+	if err != nil {
+		return err
+	}
+	return s.broker.publish(s.topic, *e)
+}
+
+// Close implements protocol.Closer. It does not close the broker.
+func (s *Sender) Close(context.Context) error {
+	return nil
+}
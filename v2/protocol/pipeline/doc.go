@@ -0,0 +1,24 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package pipeline implements protocol decorators transforming the events they
+send or receive, including their data, with binding.EventTransformers.
+
+The events are transformed lazily, when the decorated protocol writes the
+messages, and the metadata transformers passed to Send are applied after the
+event transformers.
+
+	p, _ := cloudevents.NewHTTP(cloudevents.WithTarget("http://localhost:8080/"))
+	s := pipeline.NewSender(p, transformer.MapJSON(map[string]string{
+		"customer.email": "",          // Delete the email.
+		"customer.name":  "recipient", // Move the name.
+	}))
+	c, _ := cloudevents.NewClient(s)
+
+Likewise, NewReceiver transforms the events received by a protocol, before the
+client reads them.
+*/
+package pipeline
@@ -0,0 +1,37 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package pipeline
+
+import (
+	"context"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/internal/decorator"
+)
+
+// Receiver transforms the events received by a protocol.Receiver or
+// protocol.Responder. It also opens and closes the decorated protocol, so it
+// can be used in its place with client.New. The responses are not
+// transformed.
+type Receiver struct {
+	*decorator.Receiver
+}
+
+var (
+	_ protocol.Receiver  = (*Receiver)(nil)
+	_ protocol.Responder = (*Receiver)(nil)
+	_ protocol.Opener    = (*Receiver)(nil)
+	_ protocol.Closer    = (*Receiver)(nil)
+)
+
+// NewReceiver returns a Receiver applying transformers, in order, to the
+// events received by r.
+func NewReceiver(r protocol.Receiver, transformers ...binding.EventTransformer) *Receiver {
+	return &Receiver{decorator.NewReceiver(r, func(ctx context.Context, m binding.Message) (binding.Message, error) {
+		return binding.WithEventTransformers(ctx, m, transformers...), nil
+	})}
+}
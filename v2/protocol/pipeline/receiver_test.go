@@ -0,0 +1,68 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	bindingtest "github.com/cloudevents/sdk-go/v2/binding/test"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/gochan"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+var setText = binding.EventTransformerFunc(func(ctx context.Context, e *event.Event) error {
+	return e.SetData(event.TextPlain, "transformed")
+})
+
+func TestReceiver_Receive(t *testing.T) {
+	ch := make(chan binding.Message, 1)
+	r := NewReceiver(gochan.Receiver(ch), setText)
+
+	e := test.FullEvent()
+	ch <- bindingtest.MustCreateMockBinaryMessage(e)
+	m, err := r.Receive(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, binding.EncodingBinary, m.ReadEncoding())
+	got := test.MustToEvent(t, context.Background(), m)
+	require.Equal(t, "transformed", string(got.Data()))
+	require.Equal(t, e.ID(), got.ID())
+
+	close(ch)
+	_, err = r.Receive(context.Background())
+	require.Error(t, err)
+}
+
+func TestReceiver_Respond(t *testing.T) {
+	in := make(chan binding.Message, 1)
+	out := make(chan gochan.ChanResponderResponse, 1)
+	r := NewReceiver(struct {
+		gochan.Receiver
+		*gochan.Responder
+	}{Responder: &gochan.Responder{In: in, Out: out}}, setText)
+
+	e := test.MinEvent()
+	in <- binding.ToMessage(&e)
+	m, fn, err := r.Respond(context.Background())
+	require.NoError(t, err)
+	got := test.MustToEvent(t, context.Background(), m)
+	require.Equal(t, "transformed", string(got.Data()))
+	require.NoError(t, fn(context.Background(), nil, protocol.ResultACK))
+	require.True(t, protocol.IsACK((<-out).Result))
+
+	// Receivers which are not responders respond with the result only.
+	ch := make(chan binding.Message, 1)
+	r = NewReceiver(gochan.Receiver(ch), setText)
+	ch <- binding.ToMessage(&e)
+	_, fn, err = r.Respond(context.Background())
+	require.NoError(t, err)
+	require.True(t, protocol.IsACK(fn(context.Background(), nil, protocol.ResultACK)))
+}
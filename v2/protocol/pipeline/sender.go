@@ -0,0 +1,57 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// Sender transforms the events sent with a protocol.Sender, and the requests
+// sent with it if it is a protocol.Requester.
+type Sender struct {
+	sender       protocol.Sender
+	transformers binding.EventTransformers
+}
+
+var (
+	_ protocol.Sender    = (*Sender)(nil)
+	_ protocol.Requester = (*Sender)(nil)
+	_ protocol.Closer    = (*Sender)(nil)
+)
+
+// NewSender returns a Sender applying transformers, in order, to the events
+// sent with s.
+func NewSender(s protocol.Sender, transformers ...binding.EventTransformer) *Sender {
+	return &Sender{sender: s, transformers: transformers}
+}
+
+// Send implements protocol.Sender.
+func (s *Sender) Send(ctx context.Context, m binding.Message, transformers ...binding.Transformer) error {
+	return s.sender.Send(ctx, binding.WithEventTransformers(ctx, m, s.transformers...), transformers...)
+}
+
+// Request implements protocol.Requester. The responses are not transformed.
+func (s *Sender) Request(ctx context.Context, m binding.Message, transformers ...binding.Transformer) (binding.Message, error) {
+	r, ok := s.sender.(protocol.Requester)
+	if !ok {
+		err := fmt.Errorf("%T is not a protocol.Requester", s.sender)
+		_ = m.Finish(err)
+		return nil, err
+	}
+	return r.Request(ctx, binding.WithEventTransformers(ctx, m, s.transformers...), transformers...)
+}
+
+// Close implements protocol.Closer, closing the decorated protocol if needed.
+func (s *Sender) Close(ctx context.Context) error {
+	if c, ok := s.sender.(protocol.Closer); ok {
+		return c.Close(ctx)
+	}
+	return nil
+}
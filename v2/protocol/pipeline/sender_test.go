@@ -0,0 +1,87 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/transformer"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/gochan"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+func TestSender_http(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- r
+		bodies <- body
+	}))
+	defer server.Close()
+	p, err := cehttp.New(cehttp.WithTarget(server.URL))
+	require.NoError(t, err)
+	s := NewSender(p, transformer.MapJSON(map[string]string{"email": "", "name": "recipient"}))
+
+	e := test.MinEvent()
+	require.NoError(t, e.SetData(event.ApplicationJSON, map[string]string{"name": "Jane", "email": "jane@example.com"}))
+
+	// The encoding of the context is used, and metadata transformers compose.
+	ctx := binding.WithForceStructured(context.Background())
+	require.True(t, protocol.IsACK(s.Send(ctx, binding.ToMessage(&e), transformer.AddExtension("ext", "value"))))
+	r := <-requests
+	require.Equal(t, event.ApplicationCloudEventsJSON, r.Header.Get("Content-Type"))
+	got := test.MustToEvent(t, context.Background(), cehttp.NewMessage(r.Header, io.NopCloser(bytes.NewReader(<-bodies))))
+	require.JSONEq(t, `{"recipient":"Jane"}`, string(got.Data()))
+	require.Equal(t, "value", got.Extensions()["ext"])
+
+	ctx = binding.WithForceBinary(context.Background())
+	require.True(t, protocol.IsACK(s.Send(ctx, binding.ToMessage(&e))))
+	r = <-requests
+	require.Equal(t, event.ApplicationJSON, r.Header.Get("Content-Type"))
+	require.JSONEq(t, `{"recipient":"Jane"}`, string(<-bodies))
+
+	// The event sent is not modified.
+	require.JSONEq(t, `{"name":"Jane","email":"jane@example.com"}`, string(e.Data()))
+}
+
+func TestSender_lazy(t *testing.T) {
+	ch := make(chan binding.Message, 1)
+	calls := 0
+	s := NewSender(gochan.Sender(ch), binding.EventTransformerFunc(func(ctx context.Context, e *event.Event) error {
+		calls++
+		return e.SetData(event.TextPlain, "transformed")
+	}))
+
+	e := test.MinEvent()
+	require.NoError(t, s.Send(context.Background(), binding.ToMessage(&e)))
+	require.Equal(t, 0, calls)
+	got := test.MustToEvent(t, context.Background(), <-ch)
+	require.Equal(t, "transformed", string(got.Data()))
+	require.Equal(t, 1, calls)
+}
+
+func TestSender_Request(t *testing.T) {
+	s := NewSender(gochan.Sender(make(chan binding.Message)))
+	e := test.MinEvent()
+	var finished error
+	_, err := s.Request(context.Background(), binding.WithFinish(binding.ToMessage(&e), func(err error) {
+		finished = err
+	}))
+	require.Error(t, err)
+	require.Equal(t, err, finished)
+}
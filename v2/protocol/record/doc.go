@@ -0,0 +1,25 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package record implements protocol decorators recording the messages they
+receive or send, and a receiver replaying the recordings, to debug handlers
+and write regression tests with production traffic.
+
+Recordings are newline-delimited JSON: each line is a Record, holding the event
+of the message, its encoding and its transport metadata, such as HTTP headers
+with WithMetadataFunc.
+
+	f, _ := os.Create("messages.ndjson")
+	recorder, _ := record.NewRecorder(f, record.WithMetadataFunc(cehttp.MessageHeader))
+	p, _ := cloudevents.NewHTTP()
+	c, _ := cloudevents.NewClient(recorder.Receiver(p))
+
+	// Later, replay the recorded messages to the same handler.
+	f, _ = os.Open("messages.ndjson")
+	replay, _ := record.NewReplayReceiver(f, record.WithTimeScale(1))
+	c, _ = cloudevents.NewClient(replay)
+*/
+package record
@@ -0,0 +1,43 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package record
+
+import (
+	"fmt"
+)
+
+// Option is the function signature required to be considered a record.Option.
+type Option func(*Recorder) error
+
+// WithMetadataFunc sets the function returning the transport metadata of the
+// recorded messages, such as http.MessageHeader for the http protocol. By
+// default, only the metadata of replayed messages is recorded.
+func WithMetadataFunc(fn MetadataFunc) Option {
+	return func(r *Recorder) error {
+		if fn == nil {
+			return fmt.Errorf("metadata function must not be nil")
+		}
+		r.metadata = fn
+		return nil
+	}
+}
+
+// ReplayOption is the function signature required to be considered a
+// record.ReplayOption.
+type ReplayOption func(*ReplayReceiver) error
+
+// WithTimeScale makes a ReplayReceiver wait between messages for the time
+// between their recordings, multiplied by scale: 1 replays them in real time,
+// 0.5 twice as fast. By default, messages are replayed without waiting.
+func WithTimeScale(scale float64) ReplayOption {
+	return func(r *ReplayReceiver) error {
+		if scale < 0 {
+			return fmt.Errorf("time scale must not be negative, got %v", scale)
+		}
+		r.timeScale = scale
+		return nil
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package record
+
+import (
+	"context"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/internal/decorator"
+)
+
+// Receiver records the messages received by a protocol.Receiver or
+// protocol.Responder. It also opens and closes the decorated protocol, so it
+// can be used in its place with client.New.
+type Receiver struct {
+	*decorator.Receiver
+}
+
+var (
+	_ protocol.Receiver  = (*Receiver)(nil)
+	_ protocol.Responder = (*Receiver)(nil)
+	_ protocol.Opener    = (*Receiver)(nil)
+	_ protocol.Closer    = (*Receiver)(nil)
+)
+
+// Receiver returns a Receiver recording the messages received by p. Messages
+// which cannot be recorded are not delivered.
+func (r *Recorder) Receiver(p protocol.Receiver) *Receiver {
+	return &Receiver{decorator.NewReceiver(p, func(ctx context.Context, m binding.Message) (binding.Message, error) {
+		return r.Record(ctx, m)
+	})}
+}
+
+// Sender records the messages sent with a protocol.Sender.
+type Sender struct {
+	recorder *Recorder
+	sender   protocol.Sender
+}
+
+var (
+	_ protocol.Sender = (*Sender)(nil)
+	_ protocol.Closer = (*Sender)(nil)
+)
+
+// Sender returns a Sender recording the messages sent with p.
+func (r *Recorder) Sender(p protocol.Sender) *Sender {
+	return &Sender{recorder: r, sender: p}
+}
+
+// Send implements protocol.Sender. The transformers are applied before
+// recording.
+func (s *Sender) Send(ctx context.Context, m binding.Message, transformers ...binding.Transformer) error {
+	m, err := s.recorder.Record(ctx, m, transformers...)
+	if err != nil {
+		return err
+	}
+	return s.sender.Send(ctx, m)
+}
+
+// Close implements protocol.Closer, closing the decorated protocol if needed.
+func (s *Sender) Close(ctx context.Context) error {
+	if c, ok := s.sender.(protocol.Closer); ok {
+		return c.Close(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package record
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+// Record is a recorded message, a line of a recording.
+type Record struct {
+	// Time is when the message was recorded.
+	Time time.Time `json:"time"`
+	// Encoding is the encoding of the message: binary, structured or event.
+	Encoding string `json:"encoding"`
+	// Format is the media type of the format of structured messages.
+	Format string `json:"format,omitempty"`
+	// Metadata is the transport metadata of the message, such as HTTP
+	// headers.
+	Metadata map[string][]string `json:"metadata,omitempty"`
+	// Event is the event of the message.
+	Event event.Event `json:"event"`
+}
+
+type metadataKey struct{}
+
+// WithMetadata returns a Context with the transport metadata of a replayed
+// message.
+func WithMetadata(ctx context.Context, metadata map[string][]string) context.Context {
+	return context.WithValue(ctx, metadataKey{}, metadata)
+}
+
+// MetadataFromContext returns the transport metadata of a replayed message
+// from the Context of its handler. If not set nil is returned.
+func MetadataFromContext(ctx context.Context) map[string][]string {
+	if metadata, ok := ctx.Value(metadataKey{}).(map[string][]string); ok {
+		return metadata
+	}
+	return nil
+}
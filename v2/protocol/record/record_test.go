@@ -0,0 +1,204 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package record
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	nethttp "net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/client"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol/gochan"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+func newEvent(id string) event.Event {
+	e := event.New()
+	e.SetID(id)
+	e.SetSource("/record")
+	e.SetType("record.test")
+	e.SetTime(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	return e
+}
+
+// newHTTPMessages returns a binary, a structured and an event message, with
+// their events.
+func newHTTPMessages(t *testing.T) ([]binding.Message, []event.Event) {
+	binary := newEvent("binary")
+	require.NoError(t, binary.SetData("application/octet-stream", []byte{0xff, 0x00, 0xfe}))
+	binary.DataBase64 = false
+	header := nethttp.Header{
+		"Content-Type":   {"application/octet-stream"},
+		"Ce-Specversion": {"1.0"},
+		"Ce-Id":          {"binary"},
+		"Ce-Source":      {"/record"},
+		"Ce-Type":        {"record.test"},
+		"Ce-Time":        {"2021-01-01T00:00:00Z"},
+		"X-Trace":        {"1"},
+	}
+	binaryMessage := cehttp.NewMessage(header, io.NopCloser(bytes.NewReader([]byte{0xff, 0x00, 0xfe})))
+
+	structured := newEvent("structured")
+	require.NoError(t, structured.SetData(event.ApplicationJSON, map[string]string{"hello": "world"}))
+	b, err := structured.MarshalJSON()
+	require.NoError(t, err)
+	structuredMessage := cehttp.NewMessage(nethttp.Header{"Content-Type": {event.ApplicationCloudEventsJSON}}, io.NopCloser(bytes.NewReader(b)))
+
+	e := newEvent("event")
+	return []binding.Message{binaryMessage, structuredMessage, binding.ToMessage(&e)}, []event.Event{binary, structured, e}
+}
+
+func receiveEvent(t *testing.T, m binding.Message) event.Event {
+	e, err := binding.ToEvent(context.Background(), m)
+	require.NoError(t, err)
+	return *e
+}
+
+func TestRecordReplay(t *testing.T) {
+	messages, events := newHTTPMessages(t)
+	ch := make(chan binding.Message, len(messages))
+	finished := 0
+	for _, m := range messages {
+		ch <- binding.WithFinish(m, func(error) { finished++ })
+	}
+	close(ch)
+
+	var buf bytes.Buffer
+	recorder, err := NewRecorder(&buf, WithMetadataFunc(cehttp.MessageHeader))
+	require.NoError(t, err)
+	receiver := recorder.Receiver(gochan.Receiver(ch))
+	for i, e := range events {
+		m, err := receiver.Receive(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, messages[i].ReadEncoding(), m.ReadEncoding())
+		require.Equal(t, e, receiveEvent(t, m))
+		require.NoError(t, m.Finish(nil))
+	}
+	require.Equal(t, 3, finished)
+	require.Equal(t, 3, strings.Count(buf.String(), "\n"))
+
+	replay, err := NewReplayReceiver(&buf)
+	require.NoError(t, err)
+	for i, e := range events {
+		m, err := replay.Receive(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, messages[i].ReadEncoding(), m.ReadEncoding())
+		require.Equal(t, e, receiveEvent(t, m))
+		metadata := MetadataFromContext(m.(binding.MessageContext).Context())
+		if i == 0 {
+			require.Equal(t, []string{"1"}, metadata["X-Trace"])
+		}
+	}
+	_, err = replay.Receive(context.Background())
+	require.Equal(t, io.EOF, err)
+}
+
+func TestRecorder_metadata(t *testing.T) {
+	messages, _ := newHTTPMessages(t)
+
+	// Without a metadata function, no transport metadata is recorded.
+	var buf bytes.Buffer
+	recorder, err := NewRecorder(&buf)
+	require.NoError(t, err)
+	m, err := recorder.Record(context.Background(), messages[0])
+	require.NoError(t, err)
+	require.NoError(t, m.Finish(nil))
+	require.NotContains(t, buf.String(), "metadata")
+
+	// The metadata of replayed messages is recorded again.
+	replay, err := NewReplayReceiver(strings.NewReader(`{"time":"2021-01-01T00:00:00Z","encoding":"event","metadata":{"X-Trace":["1"]},"event":{"specversion":"1.0","id":"1","source":"/record","type":"record.test"}}`))
+	require.NoError(t, err)
+	m, err = replay.Receive(context.Background())
+	require.NoError(t, err)
+	buf.Reset()
+	m, err = recorder.Record(context.Background(), m)
+	require.NoError(t, err)
+	require.NoError(t, m.Finish(nil))
+	var rec Record
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &rec))
+	require.Equal(t, map[string][]string{"X-Trace": {"1"}}, rec.Metadata)
+}
+
+func TestRecorder_Sender(t *testing.T) {
+	var buf bytes.Buffer
+	recorder, err := NewRecorder(&buf)
+	require.NoError(t, err)
+	ch := make(chan binding.Message, 1)
+	sender := recorder.Sender(gochan.Sender(ch))
+
+	e := newEvent("1")
+	require.NoError(t, sender.Send(context.Background(), binding.ToMessage(&e)))
+	require.Equal(t, e, receiveEvent(t, <-ch))
+
+	replay, err := NewReplayReceiver(&buf)
+	require.NoError(t, err)
+	m, err := replay.Receive(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, e, receiveEvent(t, m))
+}
+
+func TestReplayReceiver(t *testing.T) {
+	start := time.Now()
+	recording := strings.Join([]string{
+		`{"time":"2021-01-01T00:00:00Z","encoding":"event","event":{"specversion":"1.0","id":"1","source":"/record","type":"record.test"}}`,
+		`not a record`,
+		``,
+		`{"time":"2021-01-01T00:00:00.1Z","encoding":"event","event":{"specversion":"1.0","id":"2","source":"/record","type":"record.test"}}`,
+	}, "\n")
+	replay, err := NewReplayReceiver(strings.NewReader(recording), WithTimeScale(0.5))
+	require.NoError(t, err)
+
+	m, err := replay.Receive(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "1", receiveEvent(t, m).ID())
+	_, err = replay.Receive(context.Background())
+	require.Error(t, err)
+	m, err = replay.Receive(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "2", receiveEvent(t, m).ID())
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	_, err = replay.Receive(context.Background())
+	require.Equal(t, io.EOF, err)
+
+	_, err = NewReplayReceiver(strings.NewReader(""), WithTimeScale(-1))
+	require.Error(t, err)
+}
+
+func TestReplayReceiver_client(t *testing.T) {
+	_, events := newHTTPMessages(t)
+	var buf bytes.Buffer
+	recorder, err := NewRecorder(&buf)
+	require.NoError(t, err)
+	for i := range events {
+		_, err := recorder.Record(context.Background(), binding.ToMessage(&events[i]))
+		require.NoError(t, err)
+	}
+
+	replay, err := NewReplayReceiver(&buf)
+	require.NoError(t, err)
+	c, err := client.New(replay)
+	require.NoError(t, err)
+	var (
+		mu  sync.Mutex
+		ids []string
+	)
+	require.NoError(t, c.StartReceiver(context.Background(), func(e event.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		ids = append(ids, e.ID())
+	}))
+	require.ElementsMatch(t, []string{"binary", "structured", "event"}, ids)
+}
@@ -0,0 +1,178 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package record
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/buffering"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+)
+
+// MetadataFunc returns the transport metadata of a message to record.
+type MetadataFunc func(binding.Message) map[string][]string
+
+// Recorder records messages to a writer, one Record per line. Failing to
+// record a message does not fail its delivery: the error is logged.
+type Recorder struct {
+	metadata MetadataFunc
+	now      func() time.Time
+
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewRecorder returns a Recorder writing to w. Writes are serialized, so w
+// does not need to be safe for concurrent use.
+func NewRecorder(w io.Writer, opts ...Option) (*Recorder, error) {
+	r := &Recorder{
+		now: time.Now,
+		enc: json.NewEncoder(w),
+	}
+	if err := r.applyOptions(opts...); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Recorder) applyOptions(opts ...Option) error {
+	for _, fn := range opts {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replayedMetadata returns the metadata of m if it is, or wraps, a replayed
+// message.
+func replayedMetadata(m binding.Message) (map[string][]string, bool) {
+	for m != nil {
+		if mt, ok := m.(*message); ok {
+			return mt.metadata, true
+		}
+		w, ok := m.(binding.MessageWrapper)
+		if !ok {
+			break
+		}
+		m = w.GetWrappedMessage()
+	}
+	return nil, false
+}
+
+// Record records m and returns a copy of it to use instead, which finishes m
+// when finished. Messages with an unknown encoding are returned as is without
+// being recorded. If m cannot be read, it is finished with the error.
+func (r *Recorder) Record(ctx context.Context, m binding.Message, transformers ...binding.Transformer) (binding.Message, error) {
+	if m.ReadEncoding() == binding.EncodingUnknown {
+		return m, nil
+	}
+	metadata, ok := replayedMetadata(m)
+	if !ok && r.metadata != nil {
+		metadata = r.metadata(m)
+	}
+	c, err := buffering.CopyMessage(ctx, m, transformers...)
+	if err != nil {
+		_ = m.Finish(err)
+		return nil, err
+	}
+	copied := &recordedMessage{Message: c, original: m}
+
+	rec := Record{
+		Time:     r.now(),
+		Encoding: c.ReadEncoding().String(),
+		Metadata: metadata,
+	}
+	if c.ReadEncoding() == binding.EncodingStructured {
+		if err := c.ReadStructured(ctx, (*formatWriter)(&rec)); err != nil {
+			_ = copied.Finish(err)
+			return nil, err
+		}
+	}
+	e, err := binding.ToEvent(ctx, c)
+	if err != nil {
+		_ = copied.Finish(err)
+		return nil, err
+	}
+	rec.Event = *e
+	if !utf8.Valid(rec.Event.DataEncoded) {
+		// Binary data could not be written as a JSON string.
+		rec.Event.DataBase64 = true
+	}
+	if err := r.write(&rec); err != nil {
+		cecontext.LoggerFrom(ctx).Warnw("could not record message",
+			zap.String("id", e.ID()),
+			zap.Error(err))
+	}
+	return copied, nil
+}
+
+func (r *Recorder) write(rec *Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.enc.Encode(rec)
+}
+
+// formatWriter is a binding.StructuredWriter setting the format of a Record.
+type formatWriter Record
+
+func (w *formatWriter) SetStructuredEvent(_ context.Context, f format.Format, _ io.Reader) error {
+	w.Format = f.MediaType()
+	return nil
+}
+
+// recordedMessage is the copy of a recorded message.
+type recordedMessage struct {
+	binding.Message
+	original binding.Message
+}
+
+var (
+	_ binding.MessageContext = (*recordedMessage)(nil)
+	_ binding.MessageWrapper = (*recordedMessage)(nil)
+)
+
+// Context returns the Context of the original message, so that handlers can
+// still access its transport information.
+func (m *recordedMessage) Context() context.Context {
+	if mctx, ok := m.original.(binding.MessageContext); ok {
+		return mctx.Context()
+	}
+	return context.Background()
+}
+
+func (m *recordedMessage) GetAttribute(k spec.Kind) (spec.Attribute, interface{}) {
+	if r, ok := m.Message.(binding.MessageMetadataReader); ok {
+		return r.GetAttribute(k)
+	}
+	return nil, nil
+}
+
+func (m *recordedMessage) GetExtension(name string) interface{} {
+	if r, ok := m.Message.(binding.MessageMetadataReader); ok {
+		return r.GetExtension(name)
+	}
+	return nil
+}
+
+func (m *recordedMessage) GetWrappedMessage() binding.Message {
+	return m.Message
+}
+
+func (m *recordedMessage) Finish(err error) error {
+	_ = m.Message.Finish(err)
+	return m.original.Finish(err)
+}
@@ -0,0 +1,185 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// ReplayReceiver receives the messages of a recording, in order, with their
+// recorded encoding. The transport metadata of a message is available to its
+// handler with MetadataFromContext. Receive returns io.EOF at the end of the
+// recording, so that client.StartReceiver returns once the messages are
+// handled.
+type ReplayReceiver struct {
+	timeScale float64
+
+	mu     sync.Mutex
+	reader *bufio.Reader
+	// next is the record waiting to be replayed.
+	next *Record
+	// prev is the recording time of the previous message, and replayed when
+	// it was replayed.
+	prev     time.Time
+	replayed time.Time
+}
+
+var _ protocol.Receiver = (*ReplayReceiver)(nil)
+
+// NewReplayReceiver returns a ReplayReceiver of the recording read from r.
+func NewReplayReceiver(r io.Reader, opts ...ReplayOption) (*ReplayReceiver, error) {
+	rr := &ReplayReceiver{reader: bufio.NewReader(r)}
+	for _, fn := range opts {
+		if err := fn(rr); err != nil {
+			return nil, err
+		}
+	}
+	return rr, nil
+}
+
+// Receive implements protocol.Receiver. An invalid record is returned as an
+// error and skipped.
+func (r *ReplayReceiver) Receive(ctx context.Context) (binding.Message, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("nil Context")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.next == nil {
+		rec, err := r.read()
+		if err != nil {
+			return nil, err
+		}
+		r.next = rec
+	}
+
+	if r.timeScale > 0 && !r.prev.IsZero() {
+		delay := time.Duration(float64(r.next.Time.Sub(r.prev)) * r.timeScale)
+		if wait := time.Until(r.replayed.Add(delay)); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, io.EOF
+			}
+		}
+	}
+
+	rec := r.next
+	r.next = nil
+	r.prev = rec.Time
+	r.replayed = time.Now()
+	return newMessage(rec), nil
+}
+
+// read reads the next record.
+func (r *ReplayReceiver) read() (*Record, error) {
+	for {
+		line, err := r.reader.ReadBytes('\n')
+		if err != nil && !(errors.Is(err, io.EOF) && len(line) > 0) {
+			return nil, err
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		rec := &Record{}
+		if err := json.Unmarshal(line, rec); err != nil {
+			return nil, fmt.Errorf("invalid record: %w", err)
+		}
+		return rec, nil
+	}
+}
+
+// message is a replayed message.
+type message struct {
+	event    *event.Event
+	encoding binding.Encoding
+	format   format.Format
+	metadata map[string][]string
+}
+
+var (
+	_ binding.Message        = (*message)(nil)
+	_ binding.MessageWrapper = (*message)(nil)
+	_ binding.MessageContext = (*message)(nil)
+)
+
+func newMessage(rec *Record) *message {
+	m := &message{event: &rec.Event, encoding: binding.EncodingEvent, metadata: rec.Metadata}
+	switch rec.Encoding {
+	case binding.EncodingBinary.String():
+		m.encoding = binding.EncodingBinary
+	case binding.EncodingStructured.String():
+		m.encoding = binding.EncodingStructured
+		if m.format = format.Lookup(rec.Format); m.format == nil {
+			m.format = format.JSON
+		}
+	}
+	return m
+}
+
+func (m *message) ReadEncoding() binding.Encoding {
+	return m.encoding
+}
+
+func (m *message) ReadStructured(ctx context.Context, w binding.StructuredWriter) error {
+	switch m.encoding {
+	case binding.EncodingBinary:
+		return binding.ErrNotStructured
+	case binding.EncodingStructured:
+		b, err := m.format.Marshal(m.event)
+		if err != nil {
+			return err
+		}
+		return w.SetStructuredEvent(ctx, m.format, bytes.NewReader(b))
+	}
+	return (*binding.EventMessage)(m.event).ReadStructured(ctx, w)
+}
+
+func (m *message) ReadBinary(ctx context.Context, w binding.BinaryWriter) error {
+	if m.encoding == binding.EncodingStructured {
+		return binding.ErrNotBinary
+	}
+	return (*binding.EventMessage)(m.event).ReadBinary(ctx, w)
+}
+
+func (m *message) GetAttribute(k spec.Kind) (spec.Attribute, interface{}) {
+	return (*binding.EventMessage)(m.event).GetAttribute(k)
+}
+
+func (m *message) GetExtension(name string) interface{} {
+	return (*binding.EventMessage)(m.event).GetExtension(name)
+}
+
+func (m *message) GetWrappedMessage() binding.Message {
+	return (*binding.EventMessage)(m.event)
+}
+
+// Context returns a Context with the transport metadata of the message.
+func (m *message) Context() context.Context {
+	return WithMetadata(context.Background(), m.metadata)
+}
+
+func (m *message) Finish(error) error {
+	return nil
+}
@@ -0,0 +1,361 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	bindingtest "github.com/cloudevents/sdk-go/v2/binding/test"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+// DefaultConformanceTimeout is the time a conformance test waits for each
+// operation of a protocol.
+const DefaultConformanceTimeout = 5 * time.Second
+
+// Conformance configures the conformance tests of a protocol implementation,
+// run with RunConformance. The tests verify the behaviour the SDK expects of
+// protocols:
+//   - binary, structured and event messages round trip with every attribute
+//     type and their extensions, for every spec version;
+//   - Finish ACK and NACK results reach the sender;
+//   - Send is safe for concurrent use;
+//   - Receive returns on context expiration, and io.EOF once closed;
+//   - Requester and Responder exchange responses and results.
+type Conformance struct {
+	// NewSenderReceiver returns a connected sender and receiver: the messages
+	// sent with the sender are received by the receiver, and by no other
+	// receiver. It is called for each test, which releases its resources with
+	// t.Cleanup. The close test closes the receiver and the sender if they
+	// are protocol.Closers.
+	NewSenderReceiver func(t *testing.T) (protocol.Sender, protocol.Receiver)
+
+	// NewRequesterResponder returns a connected requester and responder. If
+	// nil, the request tests are skipped.
+	NewRequesterResponder func(t *testing.T) (protocol.Requester, protocol.Responder)
+
+	// StringExtensions is set for protocols which do not preserve the type of
+	// extensions, such as those encoding them as headers: extensions are
+	// compared as strings.
+	StringExtensions bool
+
+	// SkipNACK is set for protocols which do not propagate NACKs to the
+	// sender, such as at-most-once protocols.
+	SkipNACK bool
+
+	// Timeout is the time waited for each operation. If 0,
+	// DefaultConformanceTimeout is used.
+	Timeout time.Duration
+}
+
+// RunConformance runs the conformance tests of a protocol implementation, as
+// subtests of t.
+func RunConformance(t *testing.T, c Conformance) {
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultConformanceTimeout
+	}
+	t.Run("RoundTrip", c.testRoundTrip)
+	t.Run("FinishACK", c.testFinishACK)
+	if !c.SkipNACK {
+		t.Run("FinishNACK", c.testFinishNACK)
+	}
+	t.Run("ConcurrentSend", c.testConcurrentSend)
+	t.Run("Close", c.testClose)
+	if c.NewRequesterResponder != nil {
+		t.Run("Request", c.testRequest)
+	}
+}
+
+func (c Conformance) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.Timeout)
+}
+
+// send sends m in a goroutine, returning the channels of the result of Send
+// and of the error m is finished with.
+func (c Conformance) send(ctx context.Context, s protocol.Sender, m binding.Message) (<-chan error, <-chan error) {
+	result := make(chan error, 1)
+	finished := make(chan error, 1)
+	var once sync.Once
+	m = binding.WithFinish(m, func(err error) {
+		once.Do(func() { finished <- err })
+	})
+	go func() { result <- s.Send(ctx, m) }()
+	return result, finished
+}
+
+func (c Conformance) receive(t *testing.T, r protocol.Receiver) binding.Message {
+	t.Helper()
+	ctx, cancel := c.context()
+	defer cancel()
+	m, err := r.Receive(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	return m
+}
+
+func (c Conformance) wait(t *testing.T, ch <-chan error, what string) error {
+	t.Helper()
+	select {
+	case err := <-ch:
+		return err
+	case <-time.After(c.Timeout):
+		require.FailNow(t, fmt.Sprintf("timed out waiting for %s", what))
+		return nil
+	}
+}
+
+// requireEvent requires that the event of m is want.
+func (c Conformance) requireEvent(t *testing.T, want event.Event, m binding.Message) {
+	t.Helper()
+	c.requireEventWithExtensions(t, want, m, c.StringExtensions)
+}
+
+func (c Conformance) requireEventWithExtensions(t *testing.T, want event.Event, m binding.Message, stringExtensions bool) {
+	t.Helper()
+	got := test.MustToEvent(t, context.Background(), m)
+	if stringExtensions {
+		want = test.ConvertEventExtensionsToString(t, want)
+		got = test.ConvertEventExtensionsToString(t, got)
+	}
+	test.AssertEventEquals(t, want, got)
+}
+
+func (c Conformance) testRoundTrip(t *testing.T) {
+	encodings := map[string]func(t *testing.T, e event.Event) binding.Message{
+		"binary": func(t *testing.T, e event.Event) binding.Message {
+			return bindingtest.MustCreateMockBinaryMessage(e)
+		},
+		"structured": func(t *testing.T, e event.Event) binding.Message {
+			return bindingtest.MustCreateMockStructuredMessage(t, e)
+		},
+		"event": func(t *testing.T, e event.Event) binding.Message {
+			return binding.ToMessage(&e)
+		},
+	}
+	for name, newMessage := range encodings {
+		name, newMessage := name, newMessage
+		t.Run(name, func(t *testing.T) {
+			s, r := c.NewSenderReceiver(t)
+			test.EachEvent(t, test.Events(), func(t *testing.T, e event.Event) {
+				ctx, cancel := c.context()
+				defer cancel()
+				in := newMessage(t, e)
+				result, finished := c.send(ctx, s, in)
+
+				out := c.receive(t, r)
+				// Protocols may decode messages to events.
+				if encoding := out.ReadEncoding(); encoding != binding.EncodingEvent && in.ReadEncoding() != binding.EncodingEvent {
+					require.Equal(t, in.ReadEncoding(), encoding)
+				}
+				// Structured formats do not preserve the type of extensions.
+				c.requireEventWithExtensions(t, e, out, c.StringExtensions || name == "structured")
+				require.NoError(t, out.Finish(nil))
+
+				require.True(t, protocol.IsACK(c.wait(t, result, "Send")))
+				require.True(t, protocol.IsACK(c.wait(t, finished, "the sent message to be finished")))
+			})
+		})
+	}
+}
+
+func (c Conformance) testFinishACK(t *testing.T) {
+	s, r := c.NewSenderReceiver(t)
+	ctx, cancel := c.context()
+	defer cancel()
+	e := test.MinEvent()
+	result, finished := c.send(ctx, s, binding.ToMessage(&e))
+
+	m := c.receive(t, r)
+	require.NoError(t, m.Finish(protocol.ResultACK))
+	err := c.wait(t, result, "Send")
+	require.True(t, protocol.IsACK(err), "Send result %v is not an ACK", err)
+	err = c.wait(t, finished, "the sent message to be finished")
+	require.True(t, protocol.IsACK(err), "sent message finished with %v, not an ACK", err)
+}
+
+// testFinishNACK verifies that a NACK is not lost: the result of Send, the
+// error the sent message is finished with, or a redelivery reports it.
+func (c Conformance) testFinishNACK(t *testing.T) {
+	s, r := c.NewSenderReceiver(t)
+	ctx, cancel := c.context()
+	defer cancel()
+	e := test.MinEvent()
+	result, finished := c.send(ctx, s, binding.ToMessage(&e))
+
+	m := c.receive(t, r)
+	require.NoError(t, m.Finish(protocol.ResultNACK))
+
+	redelivered := make(chan binding.Message, 1)
+	go func() {
+		if m, err := r.Receive(ctx); err == nil {
+			redelivered <- m
+		}
+	}()
+	for {
+		select {
+		case err := <-result:
+			if !protocol.IsACK(err) {
+				return
+			}
+			result = nil
+		case err := <-finished:
+			if !protocol.IsACK(err) {
+				return
+			}
+			finished = nil
+		case m := <-redelivered:
+			c.requireEvent(t, e, m)
+			require.NoError(t, m.Finish(nil))
+			return
+		case <-ctx.Done():
+			require.FailNow(t, "NACK was not reported to the sender nor redelivered")
+		}
+	}
+}
+
+func (c Conformance) testConcurrentSend(t *testing.T) {
+	const senders, messages = 5, 10
+	s, r := c.NewSenderReceiver(t)
+	ctx, cancel := c.context()
+	defer cancel()
+
+	var want []string
+	errs := make(chan error, senders)
+	for i := 0; i < senders; i++ {
+		var ids []string
+		for j := 0; j < messages; j++ {
+			ids = append(ids, fmt.Sprintf("%d-%d", i, j))
+		}
+		want = append(want, ids...)
+		go func() {
+			for _, id := range ids {
+				e := test.MinEvent()
+				e.SetID(id)
+				if err := s.Send(ctx, binding.ToMessage(&e)); !protocol.IsACK(err) {
+					errs <- err
+					return
+				}
+			}
+			errs <- nil
+		}()
+	}
+
+	var got []string
+	for range want {
+		m := c.receive(t, r)
+		got = append(got, test.MustToEvent(t, context.Background(), m).ID())
+		require.NoError(t, m.Finish(nil))
+	}
+	require.ElementsMatch(t, want, got)
+	for i := 0; i < senders; i++ {
+		require.NoError(t, c.wait(t, errs, "Send"))
+	}
+}
+
+func (c Conformance) testClose(t *testing.T) {
+	s, r := c.NewSenderReceiver(t)
+
+	// Receive returns once its context is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	received := make(chan error, 1)
+	go func() {
+		_, err := r.Receive(ctx)
+		received <- err
+	}()
+	require.Error(t, c.wait(t, received, "Receive to return on context expiration"))
+
+	if closer, ok := r.(protocol.Closer); ok {
+		go func() {
+			_, err := r.Receive(context.Background())
+			received <- err
+		}()
+		// Give Receive a chance to start waiting.
+		time.Sleep(10 * time.Millisecond)
+		ctx, cancel := c.context()
+		defer cancel()
+		require.NoError(t, closer.Close(ctx))
+		require.Equal(t, io.EOF, c.wait(t, received, "Receive to return on Close"))
+
+		ctx, cancel = c.context()
+		defer cancel()
+		_, err := r.Receive(ctx)
+		require.Equal(t, io.EOF, err)
+	}
+
+	if closer, ok := s.(protocol.Closer); ok && interface{}(s) != interface{}(r) {
+		ctx, cancel := c.context()
+		defer cancel()
+		require.NoError(t, closer.Close(ctx))
+	}
+}
+
+func (c Conformance) testRequest(t *testing.T) {
+	req, resp := c.NewRequesterResponder(t)
+	request := test.FullEvent()
+	response := test.MinEvent()
+
+	tests := map[string]struct {
+		response binding.Message
+		result   protocol.Result
+		ack      bool
+	}{
+		"response":    {response: binding.ToMessage(&response), result: protocol.ResultACK, ack: true},
+		"no response": {result: protocol.ResultACK, ack: true},
+		"NACK":        {result: protocol.ResultNACK},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := c.context()
+			defer cancel()
+			type reply struct {
+				m   binding.Message
+				err error
+			}
+			replies := make(chan reply, 1)
+			go func() {
+				m, err := req.Request(ctx, binding.ToMessage(&request))
+				replies <- reply{m, err}
+			}()
+
+			m, fn, err := resp.Respond(ctx)
+			require.NoError(t, err)
+			c.requireEvent(t, request, m)
+			require.NoError(t, m.Finish(nil))
+			require.NoError(t, fn(ctx, tc.response, tc.result))
+
+			var got reply
+			select {
+			case got = <-replies:
+			case <-ctx.Done():
+				require.FailNow(t, "timed out waiting for Request")
+			}
+			require.Equal(t, tc.ack, protocol.IsACK(got.err), "Request result %v", got.err)
+			if tc.response != nil {
+				require.NotNil(t, got.m)
+				c.requireEvent(t, response, got.m)
+			} else if got.m != nil {
+				_, err := binding.ToEvent(ctx, got.m)
+				require.True(t, errors.Is(err, binding.ErrUnknownEncoding), "unexpected response message")
+			}
+			if got.m != nil {
+				require.NoError(t, got.m.Finish(nil))
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package redact
+
+import (
+	"crypto/aes"
+	gocipher "crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// cipher encrypts the values of an event with its data key, using AES-GCM.
+// The name of the values is authenticated, so that ciphertexts cannot be
+// swapped between fields.
+type cipher struct {
+	aead gocipher.AEAD
+}
+
+func newCipher(key []byte) (*cipher, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &cipher{aead: aead}, nil
+}
+
+func newAEAD(key []byte) (gocipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return gocipher.NewGCM(block)
+}
+
+// encrypt returns the ciphertext of v, as a string. Extensions are encrypted in
+// their canonical string format, and data fields in JSON.
+func (c *cipher) encrypt(name string, v interface{}) (interface{}, error) {
+	var plaintext []byte
+	var err error
+	if isExtension(name) {
+		plaintext, err = valueBytes(v)
+	} else {
+		plaintext, err = encodeJSON(v)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(seal(c.aead, plaintext, []byte(name))), nil
+}
+
+// decrypt returns the value encrypted in v. Extensions are decrypted as
+// strings.
+func (c *cipher) decrypt(name string, v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok || !strings.HasPrefix(s, encryptedPrefix) {
+		return nil, errors.New("value is not encrypted")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s, encryptedPrefix))
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := open(c.aead, ciphertext, []byte(name))
+	if err != nil {
+		return nil, err
+	}
+	if isExtension(name) {
+		return string(plaintext), nil
+	}
+	return decodeJSONValue(plaintext)
+}
+
+// seal encrypts plaintext, prefixing the result with a random nonce.
+func seal(aead gocipher.AEAD, plaintext, additionalData []byte) []byte {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		// crypto/rand.Read never returns an error.
+		panic(err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData)
+}
+
+// open decrypts the result of seal.
+func open(aead gocipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short: %d bytes", len(ciphertext))
+	}
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
+
+func isExtension(name string) bool {
+	return strings.HasPrefix(name, "extension:")
+}
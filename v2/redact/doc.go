@@ -0,0 +1,38 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package redact masks, hashes or encrypts the sensitive values of events, for
+them not to be forwarded or logged verbatim.
+
+Policies declare, per event type, rules selecting the JSON data fields and the
+extensions to redact, and the action to apply to them:
+
+	r, _ := redact.New([]redact.Policy{{
+		Type: "com.example.order.created",
+		Rules: []redact.Rule{
+			{Action: redact.Mask, Paths: []string{"card.number"}},
+			{Action: redact.Hash, Paths: []string{"customer.email"}},
+			{Action: redact.Encrypt, Paths: []string{"customer.address"}, Extensions: []string{"userid"}},
+		},
+	}}, redact.WithKeyring(keyring), redact.WithHashKey(hashKey))
+
+Values are encrypted with envelope encryption: each event gets a random AES-GCM
+data key, which is wrapped with a key encryption key of the Keyring and carried
+in the KeyExtension and KeyIDExtension extensions.
+
+Redactor.Redact applies the policies to the events sent, and Redactor.Decrypt
+restores the encrypted values of the events received, with the same policies:
+
+	sender := pipeline.NewSender(p, r.Redact())
+	receiver := pipeline.NewReceiver(p, r.Decrypt())
+
+Redactor.WithLogger redacts the events logged as fields with the logger of a
+context, the values to encrypt being masked:
+
+	ctx = r.WithLogger(ctx)
+	cecontext.LoggerFrom(ctx).Infow("received", "event", e)
+*/
+package redact
@@ -0,0 +1,48 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/internal/jsondata"
+)
+
+// decodeJSON returns the JSON data of e, numbers being decoded as json.Number
+// to be encoded back unchanged.
+func decodeJSON(e *event.Event) (interface{}, error) {
+	if !jsondata.IsJSON(e.DataMediaType()) {
+		return nil, fmt.Errorf("cannot redact %q data", e.DataMediaType())
+	}
+	var raw json.RawMessage
+	if err := e.DataAs(&raw); err != nil {
+		return nil, err
+	}
+	return decodeJSONValue(raw)
+}
+
+func decodeJSONValue(b []byte) (interface{}, error) {
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func encodeJSON(v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
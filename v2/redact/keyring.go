@@ -0,0 +1,60 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package redact
+
+import (
+	gocipher "crypto/cipher"
+	"errors"
+	"fmt"
+)
+
+// Keyring wraps the data keys of events with key encryption keys, e.g. the
+// keys of a key management service.
+type Keyring interface {
+	// WrapKey encrypts the data key, returning the ID of the key encryption
+	// key used.
+	WrapKey(key []byte) (id string, wrapped []byte, err error)
+	// UnwrapKey decrypts a data key wrapped with the key encryption key id.
+	UnwrapKey(id string, wrapped []byte) ([]byte, error)
+}
+
+type aesKeyring struct {
+	id    string
+	aeads map[string]gocipher.AEAD
+}
+
+// NewAESKeyring returns a Keyring wrapping data keys with AES-GCM. keys are
+// the AES key encryption keys by ID: data keys are wrapped with the key id,
+// and unwrapped with any of keys, so that keys can be rotated.
+func NewAESKeyring(id string, keys map[string][]byte) (Keyring, error) {
+	if _, ok := keys[id]; !ok {
+		return nil, fmt.Errorf("unknown key %q", id)
+	}
+	k := &aesKeyring{id: id, aeads: make(map[string]gocipher.AEAD, len(keys))}
+	for kid, key := range keys {
+		if kid == "" {
+			return nil, errors.New("key IDs must not be empty")
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", kid, err)
+		}
+		k.aeads[kid] = aead
+	}
+	return k, nil
+}
+
+func (k *aesKeyring) WrapKey(key []byte) (string, []byte, error) {
+	return k.id, seal(k.aeads[k.id], key, []byte(k.id)), nil
+}
+
+func (k *aesKeyring) UnwrapKey(id string, wrapped []byte) ([]byte, error) {
+	aead, ok := k.aeads[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", id)
+	}
+	return open(aead, wrapped, []byte(id))
+}
@@ -0,0 +1,83 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package redact
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+// Field returns a zap field logging e redacted.
+func (r *Redactor) Field(key string, e event.Event) zap.Field {
+	return zap.Stringer(key, r.Event(e))
+}
+
+// Logger returns a logger redacting the events logged as fields, as
+// event.Event or *event.Event values, e.g. logger.Infow("received", "event", e).
+// Events formatted in the message itself are not redacted.
+func (r *Redactor) Logger(logger *zap.SugaredLogger) *zap.SugaredLogger {
+	return logger.Desugar().WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &redactingCore{Core: core, redactor: r}
+	})).Sugar()
+}
+
+// WithLogger returns a context whose logger, as returned by
+// cecontext.LoggerFrom, redacts events as Logger does.
+func (r *Redactor) WithLogger(ctx context.Context) context.Context {
+	return cecontext.WithLogger(ctx, r.Logger(cecontext.LoggerFrom(ctx)))
+}
+
+type redactingCore struct {
+	zapcore.Core
+	redactor *Redactor
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(c.redact(fields)), redactor: c.redactor}
+}
+
+func (c *redactingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, c.redact(fields))
+}
+
+// redact returns fields with the events redacted, copying fields if needed.
+func (c *redactingCore) redact(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+	for i, f := range fields {
+		var e event.Event
+		switch v := f.Interface.(type) {
+		case event.Event:
+			e = v
+		case *event.Event:
+			if v == nil {
+				continue
+			}
+			e = *v
+		default:
+			continue
+		}
+		if out == nil {
+			out = append([]zapcore.Field(nil), fields...)
+		}
+		out[i] = c.redactor.Field(f.Key, e)
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
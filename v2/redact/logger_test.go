@@ -0,0 +1,58 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package redact
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+)
+
+var testHashKey = []byte("hash key")
+
+// hashHex returns the hex digest of s keyed with testHashKey.
+func hashHex(s string) string {
+	mac := hmac.New(sha256.New, testHashKey)
+	mac.Write([]byte(s))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWithLogger(t *testing.T) {
+	r, err := New([]Policy{{Rules: []Rule{
+		{Action: Mask, Paths: []string{"customer.email"}, Extensions: []string{"userid"}},
+	}}})
+	require.NoError(t, err)
+	core, logs := observer.New(zap.DebugLevel)
+	ctx := r.WithLogger(cecontext.WithLogger(context.Background(), zap.New(core).Sugar()))
+
+	e := testEvent(t)
+	logger := cecontext.LoggerFrom(ctx)
+	logger.Infow("value", "event", e)
+	logger.Infow("pointer", zap.Any("event", &e), zap.String("other", "jane@example.com"))
+	logger.With("event", e).Info("with")
+	logger.Desugar().Info("field", r.Field("event", e))
+
+	require.Equal(t, 4, logs.Len())
+	for _, entry := range logs.All() {
+		fields := entry.ContextMap()
+		require.Contains(t, fields["event"], Masked, entry.Message)
+		require.NotContains(t, fields["event"], "jane@example.com", entry.Message)
+		require.NotContains(t, fields["event"], "u-123", entry.Message)
+		require.Contains(t, fields["event"], "Jane", entry.Message)
+	}
+	require.Equal(t, "jane@example.com", logs.All()[1].ContextMap()["other"])
+
+	// The logged event is not modified.
+	require.Equal(t, "u-123", e.Extensions()["userid"])
+}
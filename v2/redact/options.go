@@ -0,0 +1,36 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package redact
+
+import "errors"
+
+// Option is the function signature required to be considered a redact.Option.
+type Option func(*Redactor) error
+
+// WithKeyring sets the Keyring wrapping the data keys of the events with
+// encrypted values.
+func WithKeyring(k Keyring) Option {
+	return func(r *Redactor) error {
+		if k == nil {
+			return errors.New("keyring must not be nil")
+		}
+		r.keyring = k
+		return nil
+	}
+}
+
+// WithHashKey sets the key of the HMAC-SHA256 digests of the values hashed,
+// for them not to be guessed by hashing candidate values. It is required if
+// any rule hashes values.
+func WithHashKey(key []byte) Option {
+	return func(r *Redactor) error {
+		if len(key) == 0 {
+			return errors.New("hash key must not be empty")
+		}
+		r.hashKey = key
+		return nil
+	}
+}
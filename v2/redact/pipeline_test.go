@@ -0,0 +1,56 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package redact
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	bindingtest "github.com/cloudevents/sdk-go/v2/binding/test"
+	"github.com/cloudevents/sdk-go/v2/protocol/gochan"
+	"github.com/cloudevents/sdk-go/v2/protocol/pipeline"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+func TestPipeline(t *testing.T) {
+	r, err := New([]Policy{{Type: "order", Rules: []Rule{
+		{Action: Encrypt, Paths: []string{"customer.email"}, Extensions: []string{"userid"}},
+	}}}, WithKeyring(testKeyring(t)))
+	require.NoError(t, err)
+
+	sent := make(chan binding.Message, 1)
+	received := make(chan binding.Message, 1)
+	sender := pipeline.NewSender(gochan.Sender(sent), r.Redact())
+	receiver := pipeline.NewReceiver(gochan.Receiver(received), r.Decrypt())
+
+	e := testEvent(t)
+	require.NoError(t, sender.Send(context.Background(), binding.ToMessage(&e)))
+
+	// On the wire, in binary and structured modes.
+	m := <-sent
+	var structured bindingtest.MockStructuredMessage
+	var binary bindingtest.MockBinaryMessage
+	_, err = binding.Write(context.Background(), m, nil, &binary)
+	require.NoError(t, err)
+	_, err = binding.Write(context.Background(), m, &structured, nil)
+	require.NoError(t, err)
+	require.NotContains(t, string(binary.Body), "jane@example.com")
+	require.NotContains(t, string(structured.Bytes), "jane@example.com")
+	require.NotContains(t, string(structured.Bytes), "u-123")
+
+	for _, wire := range []binding.Message{&binary, &structured} {
+		received <- wire
+		m, err := receiver.Receive(context.Background())
+		require.NoError(t, err)
+		got := test.MustToEvent(t, context.Background(), m)
+		require.JSONEq(t, data, string(got.Data()))
+		got.DataEncoded = []byte(data)
+		test.AssertEventEquals(t, test.ConvertEventExtensionsToString(t, testEvent(t)), test.ConvertEventExtensionsToString(t, got))
+	}
+}
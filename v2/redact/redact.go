@@ -0,0 +1,335 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package redact
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/internal/jsondata"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+const (
+	// Masked replaces the values masked.
+	Masked = "[REDACTED]"
+
+	// KeyExtension is the extension holding the wrapped data key of the
+	// events with encrypted values.
+	KeyExtension = "redactkey"
+	// KeyIDExtension is the extension holding the ID of the key encryption
+	// key which wrapped the data key of the events with encrypted values.
+	KeyIDExtension = "redactkeyid"
+
+	hashPrefix      = "sha256:"
+	encryptedPrefix = "enc:"
+	dataKeySize     = 32
+)
+
+// Action is what a Rule does to the values it selects.
+type Action int
+
+const (
+	// Mask replaces the values with Masked.
+	Mask Action = iota
+	// Hash replaces the values with their HMAC-SHA256 digest, keyed with
+	// WithHashKey, prefixed with "sha256:". Equal values have equal digests,
+	// so they can still be correlated, but they cannot be guessed by hashing
+	// candidate values without the key.
+	Hash
+	// Encrypt replaces the values with their ciphertext, prefixed with "enc:".
+	// Receivers with the key encryption key restore them with
+	// Redactor.Decrypt.
+	Encrypt
+)
+
+// String returns the name of the action.
+func (a Action) String() string {
+	switch a {
+	case Mask:
+		return "mask"
+	case Hash:
+		return "hash"
+	case Encrypt:
+		return "encrypt"
+	}
+	return fmt.Sprintf("Action(%d)", int(a))
+}
+
+// Rule selects the values of events to apply an action to.
+type Rule struct {
+	Action Action
+	// Paths are the JSON data fields, as the names of the nested fields
+	// separated by dots, e.g. "customer.email". A path crossing an array
+	// selects the field in each of its elements. Missing fields are skipped.
+	Paths []string
+	// Extensions are the names of the extensions. Missing extensions are
+	// skipped.
+	Extensions []string
+}
+
+// Policy declares the rules for the events of a type.
+type Policy struct {
+	// Type is the type of the events the policy applies to, or "" for all the
+	// events.
+	Type  string
+	Rules []Rule
+}
+
+// Redactor applies policies to events.
+type Redactor struct {
+	policies []Policy
+	keyring  Keyring
+	hashKey  []byte
+}
+
+// New returns a Redactor applying policies. A Keyring is required if any rule
+// encrypts values, and a hash key if any rule hashes them.
+func New(policies []Policy, opts ...Option) (*Redactor, error) {
+	r := &Redactor{policies: policies}
+	for _, opt := range opts {
+		if err := opt(r); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range policies {
+		for _, rule := range p.Rules {
+			switch rule.Action {
+			case Mask:
+			case Hash:
+				if r.hashKey == nil {
+					return nil, errors.New("a hash key is required to hash values")
+				}
+			case Encrypt:
+				if r.keyring == nil {
+					return nil, errors.New("a keyring is required to encrypt values")
+				}
+			default:
+				return nil, fmt.Errorf("unknown action %v", rule.Action)
+			}
+			for _, ext := range rule.Extensions {
+				if ext == KeyExtension || ext == KeyIDExtension {
+					return nil, fmt.Errorf("extension %q is reserved", ext)
+				}
+			}
+		}
+	}
+	return r, nil
+}
+
+// rules returns the rules applying to e.
+func (r *Redactor) rules(e *event.Event) []Rule {
+	var rules []Rule
+	for _, p := range r.policies {
+		if p.Type == "" || p.Type == e.Type() {
+			rules = append(rules, p.Rules...)
+		}
+	}
+	return rules
+}
+
+// Redact returns a transformer applying the policies to events, before they
+// are sent, e.g. with pipeline.NewSender. The data key of the events with
+// encrypted values is wrapped in the KeyExtension and KeyIDExtension
+// extensions. Events with JSON data fields to redact, but without JSON data,
+// are rejected rather than sent verbatim.
+func (r *Redactor) Redact() binding.EventTransformerFunc {
+	return func(ctx context.Context, e *event.Event) error {
+		rules := r.rules(e)
+		if len(rules) == 0 {
+			return nil
+		}
+		var c *cipher
+		for _, rule := range rules {
+			if rule.Action == Encrypt {
+				var err error
+				if c, err = r.newCipher(e); err != nil {
+					return err
+				}
+				break
+			}
+		}
+		return r.apply(e, rules, func(action Action, name string, v interface{}) (interface{}, error) {
+			switch action {
+			case Hash:
+				return r.hash(v)
+			case Encrypt:
+				return c.encrypt(name, v)
+			}
+			return Masked, nil
+		})
+	}
+}
+
+// Decrypt returns a transformer restoring the values encrypted by the
+// policies of events, after they are received, e.g. with
+// pipeline.NewReceiver. The KeyExtension and KeyIDExtension extensions are
+// removed. Events without encrypted values are not modified.
+func (r *Redactor) Decrypt() binding.EventTransformerFunc {
+	return func(ctx context.Context, e *event.Event) error {
+		c, err := r.cipherOf(e)
+		if err != nil || c == nil {
+			return err
+		}
+		var rules []Rule
+		for _, rule := range r.rules(e) {
+			if rule.Action == Encrypt {
+				rules = append(rules, rule)
+			}
+		}
+		err = r.apply(e, rules, func(_ Action, name string, v interface{}) (interface{}, error) {
+			return c.decrypt(name, v)
+		})
+		if err != nil {
+			return err
+		}
+		e.SetExtension(KeyExtension, nil)
+		e.SetExtension(KeyIDExtension, nil)
+		return nil
+	}
+}
+
+// Event returns a copy of e with the policies applied, the values to encrypt
+// being masked, e.g. to log it. If the data cannot be redacted, it is masked
+// entirely.
+func (r *Redactor) Event(e event.Event) event.Event {
+	rules := r.rules(&e)
+	if len(rules) == 0 {
+		return e
+	}
+	e = e.Clone()
+	err := r.apply(&e, rules, func(action Action, _ string, v interface{}) (interface{}, error) {
+		if action == Hash {
+			return r.hash(v)
+		}
+		return Masked, nil
+	})
+	if err != nil {
+		e.DataEncoded = []byte(Masked)
+		e.DataBase64 = false
+		e.SetDataContentType(event.TextPlain)
+	}
+	return e
+}
+
+// apply calls fn with the values of e selected by rules, and replaces them
+// with its results. Data fields are named by their path, and extensions by
+// their name prefixed with "extension:".
+func (r *Redactor) apply(e *event.Event, rules []Rule, fn func(action Action, name string, v interface{}) (interface{}, error)) error {
+	exts := e.Extensions()
+	hasPaths := false
+	for _, rule := range rules {
+		for _, name := range rule.Extensions {
+			v, ok := exts[name]
+			if !ok {
+				continue
+			}
+			v, err := fn(rule.Action, "extension:"+name, v)
+			if err != nil {
+				return fmt.Errorf("cannot %v extension %q: %w", rule.Action, name, err)
+			}
+			if err := e.Context.SetExtension(name, v); err != nil {
+				return err
+			}
+		}
+		hasPaths = hasPaths || len(rule.Paths) > 0
+	}
+	if !hasPaths || len(e.Data()) == 0 {
+		return nil
+	}
+
+	data, err := decodeJSON(e)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		for _, path := range rule.Paths {
+			action := rule.Action
+			data, err = jsondata.UpdateField(data, strings.Split(path, "."), func(v interface{}) (interface{}, error) {
+				return fn(action, path, v)
+			})
+			if err != nil {
+				return fmt.Errorf("cannot %v %q: %w", action, path, err)
+			}
+		}
+	}
+	return e.SetData(e.DataContentType(), data)
+}
+
+// hash returns the keyed digest of v: of the string itself for strings, or else of
+// its JSON encoding.
+func (r *Redactor) hash(v interface{}) (interface{}, error) {
+	b, err := valueBytes(v)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, r.hashKey)
+	mac.Write(b)
+	return hashPrefix + hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+func valueBytes(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte, int32, bool, types.URI, types.URIRef, types.Timestamp:
+		s, err := types.Format(v)
+		return []byte(s), err
+	}
+	return encodeJSON(v)
+}
+
+// newCipher generates the data key of e, and sets its wrapped value in the
+// extensions of e.
+func (r *Redactor) newCipher(e *event.Event) (*cipher, error) {
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	id, wrapped, err := r.keyring.WrapKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("cannot wrap the data key: %w", err)
+	}
+	if err := e.Context.SetExtension(KeyExtension, wrapped); err != nil {
+		return nil, err
+	}
+	if err := e.Context.SetExtension(KeyIDExtension, id); err != nil {
+		return nil, err
+	}
+	return newCipher(key)
+}
+
+// cipherOf unwraps the data key of e, returning nil if e has none.
+func (r *Redactor) cipherOf(e *event.Event) (*cipher, error) {
+	exts := e.Extensions()
+	if _, ok := exts[KeyExtension]; !ok {
+		return nil, nil
+	}
+	if r.keyring == nil {
+		return nil, errors.New("a keyring is required to decrypt values")
+	}
+	wrapped, err := types.ToBinary(exts[KeyExtension])
+	if err != nil {
+		return nil, fmt.Errorf("invalid extension %q: %w", KeyExtension, err)
+	}
+	id, err := types.ToString(exts[KeyIDExtension])
+	if err != nil {
+		return nil, fmt.Errorf("invalid extension %q: %w", KeyIDExtension, err)
+	}
+	key, err := r.keyring.UnwrapKey(id, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unwrap the data key: %w", err)
+	}
+	return newCipher(key)
+}
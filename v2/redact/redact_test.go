@@ -0,0 +1,218 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package redact
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+const data = `{"customer":{"name":"Jane","email":"jane@example.com","age":42},"items":[{"sku":"a","price":1.5},{"sku":"b","price":12345678901234567890}]}`
+
+func testKeyring(t *testing.T) Keyring {
+	k, err := NewAESKeyring("k1", map[string][]byte{"k1": []byte(strings.Repeat("1", 32))})
+	require.NoError(t, err)
+	return k
+}
+
+func testEvent(t *testing.T) event.Event {
+	e := test.MinEvent()
+	e.SetType("order")
+	e.SetDataContentType(event.ApplicationJSON)
+	e.DataEncoded = []byte(data)
+	e.SetExtension("userid", "u-123")
+	return e
+}
+
+func TestRedact(t *testing.T) {
+	r, err := New([]Policy{
+		{Type: "order", Rules: []Rule{
+			{Action: Mask, Paths: []string{"customer.name", "missing.field"}},
+			{Action: Hash, Paths: []string{"customer.email"}, Extensions: []string{"userid"}},
+			{Action: Mask, Paths: []string{"items.price"}},
+		}},
+		{Type: "other", Rules: []Rule{{Action: Mask, Paths: []string{"customer"}}}},
+	}, WithHashKey(testHashKey))
+	require.NoError(t, err)
+
+	e := testEvent(t)
+	require.NoError(t, r.Redact()(context.Background(), &e))
+	require.JSONEq(t, `{"customer":{"name":"[REDACTED]","email":"sha256:`+hashHex("jane@example.com")+`","age":42},"items":[{"sku":"a","price":"[REDACTED]"},{"sku":"b","price":"[REDACTED]"}]}`, string(e.Data()))
+	require.Equal(t, "sha256:"+hashHex("u-123"), e.Extensions()["userid"])
+
+	// Events of other types are not modified.
+	e = testEvent(t)
+	e.SetType("unknown")
+	require.NoError(t, r.Redact()(context.Background(), &e))
+	test.AssertEventEquals(t, func() event.Event { want := testEvent(t); want.SetType("unknown"); return want }(), e)
+}
+
+func TestRedact_hashKey(t *testing.T) {
+	policies := []Policy{{Rules: []Rule{{Action: Hash, Paths: []string{"customer.age"}}}}}
+	r1, err := New(policies, WithHashKey([]byte("key1")))
+	require.NoError(t, err)
+	r2, err := New(policies, WithHashKey([]byte("key2")))
+	require.NoError(t, err)
+
+	e1, e2 := testEvent(t), testEvent(t)
+	require.NoError(t, r1.Redact()(context.Background(), &e1))
+	require.NoError(t, r2.Redact()(context.Background(), &e2))
+	require.NotEqual(t, string(e1.Data()), string(e2.Data()))
+	unkeyed := sha256.Sum256([]byte("42"))
+	require.NotContains(t, string(e1.Data()), hex.EncodeToString(unkeyed[:]))
+	require.Contains(t, string(e1.Data()), `"age":"sha256:`)
+}
+
+func TestRedact_notJSON(t *testing.T) {
+	r, err := New([]Policy{{Rules: []Rule{{Action: Mask, Paths: []string{"a"}}}}})
+	require.NoError(t, err)
+
+	e := test.MinEvent()
+	require.NoError(t, e.SetData(event.TextPlain, "a=1"))
+	require.Error(t, r.Redact()(context.Background(), &e))
+
+	// Events without data have nothing to redact.
+	e = test.MinEvent()
+	require.NoError(t, r.Redact()(context.Background(), &e))
+}
+
+func TestEncrypt(t *testing.T) {
+	policies := []Policy{{Type: "order", Rules: []Rule{
+		{Action: Encrypt, Paths: []string{"customer", "items.price"}, Extensions: []string{"userid"}},
+		{Action: Mask, Paths: []string{"customer.name"}},
+	}}}
+	r, err := New(policies, WithKeyring(testKeyring(t)))
+	require.NoError(t, err)
+
+	e := testEvent(t)
+	require.NoError(t, r.Redact()(context.Background(), &e))
+	require.NotContains(t, string(e.Data()), "jane@example.com")
+	require.NotContains(t, string(e.Data()), "12345678901234567890")
+	require.Contains(t, string(e.Data()), `"customer":"enc:`)
+	require.True(t, strings.HasPrefix(e.Extensions()["userid"].(string), "enc:"))
+	require.Equal(t, "k1", e.Extensions()[KeyIDExtension])
+	require.NotNil(t, e.Extensions()[KeyExtension])
+
+	// The events are decrypted once received, in binary mode.
+	received := test.ConvertEventExtensionsToString(t, e)
+	require.NoError(t, r.Decrypt()(context.Background(), &received))
+	require.JSONEq(t, data, string(received.Data()))
+	require.Equal(t, "u-123", received.Extensions()["userid"])
+	require.NotContains(t, received.Extensions(), KeyExtension)
+	require.NotContains(t, received.Extensions(), KeyIDExtension)
+
+	// Events without data key are not modified.
+	plain := testEvent(t)
+	require.NoError(t, r.Decrypt()(context.Background(), &plain))
+	test.AssertEventEquals(t, testEvent(t), plain)
+}
+
+func TestDecrypt_errors(t *testing.T) {
+	policies := []Policy{{Rules: []Rule{{Action: Encrypt, Paths: []string{"customer.name", "customer.email"}}}}}
+	r, err := New(policies, WithKeyring(testKeyring(t)))
+	require.NoError(t, err)
+
+	tests := map[string]func(e *event.Event){
+		"swapped fields": func(e *event.Event) {
+			e.DataEncoded = []byte(strings.Replace(string(e.DataEncoded), `"name"`, `"tmp"`, 1))
+			e.DataEncoded = []byte(strings.Replace(string(e.DataEncoded), `"email"`, `"name"`, 1))
+			e.DataEncoded = []byte(strings.Replace(string(e.DataEncoded), `"tmp"`, `"email"`, 1))
+		},
+		"unknown key": func(e *event.Event) {
+			e.SetExtension(KeyIDExtension, "k2")
+		},
+		"wrong key": func(e *event.Event) {
+			other := testEvent(t)
+			require.NoError(t, r.Redact()(context.Background(), &other))
+			e.SetExtension(KeyExtension, other.Extensions()[KeyExtension])
+		},
+		"not encrypted": func(e *event.Event) {
+			e.DataEncoded = []byte(data)
+		},
+	}
+	for name, tamper := range tests {
+		tamper := tamper
+		t.Run(name, func(t *testing.T) {
+			e := testEvent(t)
+			require.NoError(t, r.Redact()(context.Background(), &e))
+			tamper(&e)
+			require.Error(t, r.Decrypt()(context.Background(), &e))
+		})
+	}
+
+	// Decrypting requires a keyring.
+	e := testEvent(t)
+	require.NoError(t, r.Redact()(context.Background(), &e))
+	noKeyring, err := New(nil)
+	require.NoError(t, err)
+	require.Error(t, noKeyring.Decrypt()(context.Background(), &e))
+}
+
+func TestNewAESKeyring_rotation(t *testing.T) {
+	key1, key2 := []byte(strings.Repeat("1", 32)), []byte(strings.Repeat("2", 16))
+	old, err := NewAESKeyring("k1", map[string][]byte{"k1": key1})
+	require.NoError(t, err)
+	rotated, err := NewAESKeyring("k2", map[string][]byte{"k1": key1, "k2": key2})
+	require.NoError(t, err)
+
+	id, wrapped, err := old.WrapKey([]byte("data key"))
+	require.NoError(t, err)
+	require.Equal(t, "k1", id)
+	key, err := rotated.UnwrapKey(id, wrapped)
+	require.NoError(t, err)
+	require.Equal(t, []byte("data key"), key)
+
+	id, wrapped, err = rotated.WrapKey([]byte("data key"))
+	require.NoError(t, err)
+	require.Equal(t, "k2", id)
+	_, err = old.UnwrapKey(id, wrapped)
+	require.Error(t, err)
+
+	_, err = NewAESKeyring("k3", map[string][]byte{"k1": key1})
+	require.Error(t, err)
+	_, err = NewAESKeyring("k1", map[string][]byte{"k1": []byte("short")})
+	require.Error(t, err)
+}
+
+func TestNew_errors(t *testing.T) {
+	_, err := New([]Policy{{Rules: []Rule{{Action: Encrypt, Paths: []string{"a"}}}}})
+	require.Error(t, err)
+	_, err = New([]Policy{{Rules: []Rule{{Action: Hash, Paths: []string{"a"}}}}})
+	require.Error(t, err)
+	_, err = New([]Policy{{Rules: []Rule{{Action: Action(42)}}}})
+	require.Error(t, err)
+	_, err = New([]Policy{{Rules: []Rule{{Action: Mask, Extensions: []string{KeyExtension}}}}})
+	require.Error(t, err)
+}
+
+func TestEvent(t *testing.T) {
+	r, err := New([]Policy{{Rules: []Rule{
+		{Action: Encrypt, Paths: []string{"customer.email"}, Extensions: []string{"userid"}},
+		{Action: Hash, Paths: []string{"customer.name"}},
+	}}}, WithKeyring(testKeyring(t)), WithHashKey(testHashKey))
+	require.NoError(t, err)
+
+	e := testEvent(t)
+	got := r.Event(e)
+	require.JSONEq(t, `{"customer":{"name":"sha256:`+hashHex("Jane")+`","email":"[REDACTED]","age":42},"items":[{"sku":"a","price":1.5},{"sku":"b","price":12345678901234567890}]}`, string(got.Data()))
+	require.Equal(t, Masked, got.Extensions()["userid"])
+	require.NotContains(t, got.Extensions(), KeyExtension)
+	test.AssertEventEquals(t, testEvent(t), e)
+
+	// Data which cannot be redacted is masked entirely.
+	require.NoError(t, e.SetData(event.TextPlain, "jane@example.com"))
+	got = r.Event(e)
+	require.Equal(t, Masked, string(got.Data()))
+	require.NotContains(t, got.String(), "jane@example.com")
+}
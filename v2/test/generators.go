@@ -0,0 +1,300 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// RandomEvent is a random valid event, generated by testing/quick:
+//
+//	quick.Check(func(e test.RandomEvent) bool { ... }, nil)
+type RandomEvent struct {
+	event.Event
+}
+
+// Generate implements quick.Generator.
+func (RandomEvent) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(RandomEvent{GenerateEvent(r, size)})
+}
+
+// RandomInvalidEvent is a random invalid event, generated by testing/quick.
+type RandomInvalidEvent struct {
+	event.Event
+}
+
+// Generate implements quick.Generator.
+func (RandomInvalidEvent) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(RandomInvalidEvent{GenerateInvalidEvent(r, size)})
+}
+
+// GenerateEvents returns n random valid events generated from seed, for
+// instance to seed a fuzz test corpus.
+func GenerateEvents(seed int64, n int) []event.Event {
+	r := rand.New(rand.NewSource(seed))
+	events := make([]event.Event, n)
+	for i := range events {
+		events[i] = GenerateEvent(r, 10)
+	}
+	return events
+}
+
+// GenerateEvent returns a random valid event of any spec version, with random
+// optional attributes, extensions and data. size bounds the number of
+// extensions and the length of strings and data.
+func GenerateEvent(r *rand.Rand, size int) event.Event {
+	if size < 1 {
+		size = 1
+	}
+	versions := spec.New().Versions()
+	e := event.New(versions[r.Intn(len(versions))].String())
+	e.SetID(generateString(r, size, true))
+	e.SetSource(generateURIRef(r))
+	e.SetType(generateString(r, size, true))
+
+	if r.Intn(2) == 0 {
+		e.SetSubject(generateString(r, size, true))
+	}
+	if r.Intn(2) == 0 {
+		e.SetTime(generateTime(r))
+	}
+	if r.Intn(2) == 0 {
+		e.SetDataSchema(generateURI(r).String())
+	}
+	for i := r.Intn(size + 1); i > 0; i-- {
+		e.SetExtension(generateExtensionName(r), generateExtensionValue(r, size))
+	}
+	if err := generateData(r, size, &e); err != nil {
+		panic(fmt.Errorf("generated invalid data: %w", err))
+	}
+	return e
+}
+
+// GenerateInvalidEvent returns a random event with a blank required
+// attribute.
+func GenerateInvalidEvent(r *rand.Rand, size int) event.Event {
+	e := GenerateEvent(r, size)
+	blank := []string{"", " ", "\t"}[r.Intn(3)]
+	switch r.Intn(3) {
+	case 0:
+		e.SetID(blank)
+	case 1:
+		// A blank source is percent-encoded, hence not blank.
+		e.SetSource("")
+	case 2:
+		e.SetType(blank)
+	}
+	return e
+}
+
+// reservedNames are the attribute names of all the spec versions, which
+// extensions cannot use.
+var reservedNames = map[string]bool{
+	"specversion": true, "id": true, "source": true, "type": true,
+	"datacontenttype": true, "dataschema": true, "subject": true, "time": true,
+	"schemaurl": true, "datacontentencoding": true, "data": true, "data_base64": true,
+}
+
+const (
+	extensionNameChars = "abcdefghijklmnopqrstuvwxyz0123456789"
+	// maxExtensionNameLength is the length extension names SHOULD NOT exceed.
+	maxExtensionNameLength = 20
+)
+
+// generateExtensionName returns a random extension name, often of the
+// minimum or maximum length.
+func generateExtensionName(r *rand.Rand) string {
+	for {
+		var n int
+		switch r.Intn(4) {
+		case 0:
+			n = 1
+		case 1:
+			n = maxExtensionNameLength
+		default:
+			n = 1 + r.Intn(maxExtensionNameLength)
+		}
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = extensionNameChars[r.Intn(len(extensionNameChars))]
+		}
+		if name := string(b); !reservedNames[name] {
+			return name
+		}
+	}
+}
+
+// generateExtensionValue returns a random value of any CloudEvents type.
+func generateExtensionValue(r *rand.Rand, size int) interface{} {
+	switch r.Intn(7) {
+	case 0:
+		return r.Intn(2) == 0
+	case 1:
+		return []int32{0, 1, -1, math.MinInt32, math.MaxInt32, r.Int31(), -r.Int31()}[r.Intn(7)]
+	case 2:
+		return generateString(r, size, false)
+	case 3:
+		b := make([]byte, r.Intn(size+1))
+		r.Read(b)
+		return b
+	case 4:
+		return types.URI{URL: *generateURI(r)}
+	case 5:
+		return types.URIRef{URL: *mustParseURL(generateURIRef(r))}
+	default:
+		return types.Timestamp{Time: generateTime(r)}
+	}
+}
+
+// stringEdgeCases are strings which are often mishandled.
+var stringEdgeCases = []string{
+	"a", "0", "true", "null", "-1", "1e10", `"quoted"`, `back\slash`, "a,b;c=d",
+	"%20%zz", "héllo wörld", "日本語", "🙂", "tab\tseparated", "new\nline",
+	"<xml/>", `{"json":1}`, "' OR 1=1", strings.Repeat("x", 256),
+}
+
+// generateString returns a random UTF-8 string, or an edge case. Non blank
+// strings are not empty nor only spaces.
+func generateString(r *rand.Rand, size int, nonBlank bool) string {
+	for {
+		var s string
+		switch r.Intn(4) {
+		case 0:
+			s = stringEdgeCases[r.Intn(len(stringEdgeCases))]
+		case 1:
+			// Printable ASCII.
+			b := make([]byte, r.Intn(size+1))
+			for i := range b {
+				b[i] = byte(' ' + r.Intn('~'-' '+1))
+			}
+			s = string(b)
+		default:
+			runes := make([]rune, r.Intn(size+1))
+			for i := range runes {
+				runes[i] = generateRune(r)
+			}
+			s = string(runes)
+		}
+		if !nonBlank || strings.TrimSpace(s) != "" {
+			return s
+		}
+	}
+}
+
+// generateRune returns a random valid printable rune, mostly ASCII.
+func generateRune(r *rand.Rand) rune {
+	ranges := [][2]rune{{'a', 'z'}, {'A', 'Z'}, {'0', '9'}, {0xa1, 0x17f}, {0x3040, 0x30ff}, {0x1f600, 0x1f64f}}
+	rg := ranges[r.Intn(len(ranges))]
+	return rg[0] + rune(r.Intn(int(rg[1]-rg[0]+1)))
+}
+
+func generateURI(r *rand.Rand) *url.URL {
+	uris := []string{
+		"https://example.com/schema",
+		"http://example.com:8080/a/b?c=d&e=f#g",
+		"urn:uuid:6e8bc430-9c3a-11d9-9669-0800200c9a66",
+		"mailto:cncf-wg-serverless@lists.cncf.io",
+		"https://[::1]/ipv6",
+	}
+	return mustParseURL(uris[r.Intn(len(uris))])
+}
+
+func generateURIRef(r *rand.Rand) string {
+	refs := []string{
+		"/source",
+		"source",
+		"../relative/path",
+		"/a%20b/%C3%A9",
+		"https://github.com/cloudevents/spec/pull",
+		"urn:event:from:myapi/resource/123",
+		"//authority/path",
+	}
+	if r.Intn(2) == 0 {
+		return generateURI(r).String()
+	}
+	return refs[r.Intn(len(refs))]
+}
+
+func mustParseURL(s string) *url.URL {
+	u, err := url.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// generateTime returns a random UTC time, with a random precision, from 1970
+// to 2100.
+func generateTime(r *rand.Rand) time.Time {
+	t := time.Unix(r.Int63n(4102444800), 0).UTC()
+	switch r.Intn(3) {
+	case 0:
+		return t.Add(time.Duration(r.Intn(1000)) * time.Millisecond)
+	case 1:
+		return t.Add(time.Duration(r.Intn(int(time.Second))))
+	}
+	return t
+}
+
+// generateData sets random data of e, if any: JSON, text, or binary for the
+// spec versions which support it.
+func generateData(r *rand.Rand, size int, e *event.Event) error {
+	switch r.Intn(4) {
+	case 0:
+		return nil
+	case 1:
+		contentType := []string{event.ApplicationJSON, event.TextJSON, "application/vnd.example+json"}[r.Intn(3)]
+		return e.SetData(contentType, generateJSON(r, size, 2))
+	case 2:
+		return e.SetData(event.TextPlain, generateString(r, size, false))
+	}
+	if e.SpecVersion() != event.CloudEventsVersionV1 {
+		return e.SetData(event.TextPlain, generateString(r, size, false))
+	}
+	b := make([]byte, r.Intn(size*4+1))
+	r.Read(b)
+	return e.SetData("application/octet-stream", b)
+}
+
+// generateJSON returns a random JSON value, nested up to depth.
+func generateJSON(r *rand.Rand, size, depth int) interface{} {
+	kind := r.Intn(6)
+	if depth == 0 {
+		kind = r.Intn(4)
+	}
+	switch kind {
+	case 0:
+		return generateString(r, size, false)
+	case 1:
+		return float64(r.Int63n(1<<53)) * []float64{1, -1, 1e-3}[r.Intn(3)]
+	case 2:
+		return r.Intn(2) == 0
+	case 3:
+		return nil
+	case 4:
+		values := make([]interface{}, r.Intn(size+1))
+		for i := range values {
+			values[i] = generateJSON(r, size, depth-1)
+		}
+		return values
+	default:
+		values := make(map[string]interface{}, size)
+		for i := r.Intn(size + 1); i > 0; i-- {
+			values[generateString(r, size, false)] = generateJSON(r, size, depth-1)
+		}
+		return values
+	}
+}
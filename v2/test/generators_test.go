@@ -0,0 +1,42 @@
+/*
+ Copyright 2021 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package test
+
+import (
+	"math/rand"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+func TestGenerateEvent(t *testing.T) {
+	require.NoError(t, quick.Check(func(e RandomEvent) bool {
+		return e.Validate() == nil
+	}, &quick.Config{MaxCount: 1000}))
+	require.NoError(t, quick.Check(func(e RandomInvalidEvent) bool {
+		return e.Validate() != nil
+	}, &quick.Config{MaxCount: 1000}))
+}
+
+func TestGenerateEvent_jsonRoundTrip(t *testing.T) {
+	for _, e := range GenerateEvents(1, 500) {
+		b, err := format.JSON.Marshal(&e)
+		require.NoError(t, err)
+		var got event.Event
+		require.NoError(t, format.JSON.Unmarshal(b, &got), string(b))
+		AssertEventEquals(t, ConvertEventExtensionsToString(t, e), ConvertEventExtensionsToString(t, got))
+	}
+}
+
+func TestGenerateEvents(t *testing.T) {
+	require.Equal(t, GenerateEvents(42, 10), GenerateEvents(42, 10))
+	e := GenerateEvent(rand.New(rand.NewSource(1)), 0)
+	require.NoError(t, e.Validate())
+}
@@ -68,6 +68,20 @@ func ConvertEventExtensionsToString(t testing.TB, e event.Event) event.Event {
 	return out
 }
 
+// AssertRoundTrip asserts that e, once normalized by a first call to
+// roundTrip, is unchanged by a second one, e.g. for the valid events parsed by
+// fuzz targets. Extensions are compared as strings if stringExtensions is set,
+// for the formats and protocols which do not preserve their type.
+func AssertRoundTrip(t testing.TB, e event.Event, stringExtensions bool, roundTrip func(event.Event) event.Event) {
+	want := roundTrip(e)
+	got := roundTrip(want)
+	if stringExtensions {
+		want = ConvertEventExtensionsToString(t, want)
+		got = ConvertEventExtensionsToString(t, got)
+	}
+	AssertEventEquals(t, want, got)
+}
+
 // TestNameOf generates a string test name from x, esp. for ce.Event and ce.Message.
 func TestNameOf(x interface{}) string {
 	switch x := x.(type) {